@@ -162,6 +162,11 @@ var (
 	// This directory replaces the use of v1CacheModuleDataRelDirPath, v1CacheModuleLockRelDirPath, and
 	// v1CacheModuleSumRelDirPath for modules which support tamper proofing.
 	v2CacheModuleRelDirPath = normalpath.Join("v2", "module")
+	// v1CacheGenerateRelDirPath is the relative path to the cache directory where plugin responses
+	// for buf generate are stored.
+	//
+	// Normalized.
+	v1CacheGenerateRelDirPath = normalpath.Join("v1", "generate")
 
 	// allVisibiltyStrings are the possible options that a user can set the visibility flag with.
 	allVisibiltyStrings = []string{
@@ -533,6 +538,22 @@ func NewModuleReaderAndCreateCacheDirs(
 	)
 }
 
+// NewGenerateCacheBucketAndCreateCacheDirs returns a new ReadWriteBucket for the cache
+// of plugin responses used by buf generate, while creating the required cache directories.
+func NewGenerateCacheBucketAndCreateCacheDirs(
+	container appflag.Container,
+) (storage.ReadWriteBucket, error) {
+	cacheGenerateDirPath := normalpath.Join(container.CacheDirPath(), v1CacheGenerateRelDirPath)
+	if err := checkExistingCacheDirs(container.CacheDirPath(), cacheGenerateDirPath); err != nil {
+		return nil, err
+	}
+	if err := createCacheDirs(cacheGenerateDirPath); err != nil {
+		return nil, err
+	}
+	// do NOT want to enable symlinks for our cache
+	return storageos.NewProvider().NewReadWriteBucket(cacheGenerateDirPath)
+}
+
 // NewModuleReaderAndCreateCacheDirsWithExternalPaths returns a new ModuleReader while creating the
 // required cache directories, and configures the cache to preserve external paths.
 //
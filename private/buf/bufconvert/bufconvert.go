@@ -33,10 +33,14 @@ const (
 	MessageEncodingBin MessageEncoding = iota + 1
 	// MessageEncodingJSON is the JSON image encoding.
 	MessageEncodingJSON
+	// MessageEncodingTxtpb is the text image encoding.
+	MessageEncodingTxtpb
 	// formatBin is the binary format.
 	formatBin = "bin"
 	// formatJSON is the JSON format.
 	formatJSON = "json"
+	// formatTxtpb is the text format.
+	formatTxtpb = "txtpb"
 )
 
 var (
@@ -48,6 +52,7 @@ var (
 	messageEncodingFormats = []string{
 		formatBin,
 		formatJSON,
+		formatTxtpb,
 	}
 )
 
@@ -110,6 +115,8 @@ func parseMessageEncodingExt(ext string, defaultEncoding MessageEncoding) Messag
 		return MessageEncodingBin
 	case formatJSON:
 		return MessageEncodingJSON
+	case formatTxtpb:
+		return MessageEncodingTxtpb
 	default:
 		return defaultEncoding
 	}
@@ -121,6 +128,8 @@ func parseMessageEncodingFormat(format string) (MessageEncoding, error) {
 		return MessageEncodingBin, nil
 	case formatJSON:
 		return MessageEncodingJSON, nil
+	case formatTxtpb:
+		return MessageEncodingTxtpb, nil
 	default:
 		return 0, fmt.Errorf("invalid format for message: %q", format)
 	}
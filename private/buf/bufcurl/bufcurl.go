@@ -30,6 +30,9 @@ import (
 // used by two goroutines concurrently during bidirectional streaming calls.
 type Resolver interface {
 	FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error)
+	// ListServices returns the fully-qualified names of all services that can be
+	// resolved, in no particular order.
+	ListServices() ([]protoreflect.FullName, error)
 	protoregistry.MessageTypeResolver
 	protoregistry.ExtensionTypeResolver
 }
@@ -0,0 +1,125 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoprint"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// ListServices uses the given resolver to list the fully-qualified names of
+// all services, sorted by name.
+func ListServices(res Resolver) ([]protoreflect.FullName, error) {
+	serviceNames, err := res.ListServices()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[protoreflect.FullName]struct{}, len(serviceNames))
+	sortedServiceNames := make([]protoreflect.FullName, 0, len(serviceNames))
+	for _, serviceName := range serviceNames {
+		if _, ok := seen[serviceName]; ok {
+			continue
+		}
+		seen[serviceName] = struct{}{}
+		sortedServiceNames = append(sortedServiceNames, serviceName)
+	}
+	sort.Slice(sortedServiceNames, func(i, j int) bool {
+		return sortedServiceNames[i] < sortedServiceNames[j]
+	})
+	return sortedServiceNames, nil
+}
+
+// ListMethods uses the given resolver to list the methods of all services.
+// The methods are sorted by service name, and then in the order in which
+// they are declared in the service.
+func ListMethods(res Resolver) ([]protoreflect.MethodDescriptor, error) {
+	serviceNames, err := ListServices(res)
+	if err != nil {
+		return nil, err
+	}
+	var methodDescriptors []protoreflect.MethodDescriptor
+	for _, serviceName := range serviceNames {
+		descriptor, err := res.FindDescriptorByName(serviceName)
+		if err != nil {
+			return nil, err
+		}
+		serviceDescriptor, ok := descriptor.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("element %s is a %s, not a service", serviceName, DescriptorKind(descriptor))
+		}
+		methods := serviceDescriptor.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			methodDescriptors = append(methodDescriptors, methods.Get(i))
+		}
+	}
+	return methodDescriptors, nil
+}
+
+// Describe uses the given resolver to find the element with the given
+// fully-qualified name, and returns its definition in Protobuf source form.
+//
+// The name of a method may also use the form of an endpoint URL path, such as
+// "foo.v1.FooService/DoSomething". The definition of a method is followed by the
+// definitions of its request and response messages.
+func Describe(res Resolver, symbol string) (string, error) {
+	symbol = strings.TrimPrefix(symbol, ".")
+	if pos := strings.LastIndexByte(symbol, '/'); pos >= 0 {
+		symbol = symbol[:pos] + "." + symbol[pos+1:]
+	}
+	descriptor, err := res.FindDescriptorByName(protoreflect.FullName(symbol))
+	if err == protoregistry.NotFound {
+		return "", fmt.Errorf("failed to find symbol %q in schema", symbol)
+	} else if err != nil {
+		return "", err
+	}
+	descriptors := []protoreflect.Descriptor{descriptor}
+	if methodDescriptor, ok := descriptor.(protoreflect.MethodDescriptor); ok {
+		descriptors = append(descriptors, methodDescriptor.Input())
+		if methodDescriptor.Output().FullName() != methodDescriptor.Input().FullName() {
+			descriptors = append(descriptors, methodDescriptor.Output())
+		}
+	}
+	printer := &protoprint.Printer{
+		Compact:                  true,
+		OmitComments:             protoprint.CommentsNonDoc,
+		ForceFullyQualifiedNames: true,
+	}
+	var builder strings.Builder
+	for i, descriptor := range descriptors {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		wrappedDescriptor, err := desc.WrapDescriptor(descriptor)
+		if err != nil {
+			return "", err
+		}
+		definition, err := printer.PrintProtoToString(wrappedDescriptor)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&builder, "%s is a %s:\n", descriptor.FullName(), DescriptorKind(descriptor))
+		builder.WriteString(definition)
+		if !strings.HasSuffix(definition, "\n") {
+			builder.WriteString("\n")
+		}
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestListServicesAndMethods(t *testing.T) {
+	t.Parallel()
+	res := testNewImageResolver(t)
+	serviceNames, err := ListServices(res)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]protoreflect.FullName{
+			"bar.v1.BarService",
+			"foo.v1.FooService",
+		},
+		serviceNames,
+	)
+	methodDescriptors, err := ListMethods(res)
+	require.NoError(t, err)
+	methodNames := make([]protoreflect.FullName, len(methodDescriptors))
+	for i, methodDescriptor := range methodDescriptors {
+		methodNames[i] = methodDescriptor.FullName()
+	}
+	assert.Equal(
+		t,
+		[]protoreflect.FullName{
+			"bar.v1.BarService.Bar",
+			"foo.v1.FooService.Foo",
+			"foo.v1.FooService.Baz",
+		},
+		methodNames,
+	)
+}
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+	res := testNewImageResolver(t)
+	definition, err := Describe(res, "foo.v1.FooService/Foo")
+	require.NoError(t, err)
+	assert.Contains(t, definition, "foo.v1.FooService.Foo is a method:\nrpc Foo ( .foo.v1.FooRequest ) returns ( .foo.v1.FooResponse );\n")
+	assert.Contains(t, definition, "\nfoo.v1.FooRequest is a message:\nmessage FooRequest {\n  string name = 1;\n}\n")
+	assert.Contains(t, definition, "\nfoo.v1.FooResponse is a message:\nmessage FooResponse {\n}\n")
+
+	definition, err = Describe(res, ".foo.v1.FooService")
+	require.NoError(t, err)
+	assert.Contains(t, definition, "foo.v1.FooService is a service:\nservice FooService {\n")
+	assert.Contains(t, definition, "rpc Baz ( .foo.v1.FooRequest ) returns ( stream .foo.v1.FooResponse );")
+
+	definition, err = Describe(res, "foo.v1.FooRequest.name")
+	require.NoError(t, err)
+	assert.Equal(t, "foo.v1.FooRequest.name is a field:\nstring name = 1;\n", definition)
+
+	_, err = Describe(res, "foo.v1.Missing")
+	assert.EqualError(t, err, `failed to find symbol "foo.v1.Missing" in schema`)
+}
+
+// testNewImageResolver returns a Resolver for foo.v1.FooService and bar.v1.BarService,
+// where foo.v1.FooResponse has the given fields.
+func testNewImageResolver(t *testing.T, responseFields ...*descriptorpb.FieldDescriptorProto) Resolver {
+	fooFileDescriptor := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("foo/v1/foo.proto"),
+		Package: proto.String("foo.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("FooRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("name"),
+						Number:   proto.Int32(1),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+						JsonName: proto.String("name"),
+					},
+				},
+			},
+			{
+				Name:  proto.String("FooResponse"),
+				Field: responseFields,
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("FooService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("Foo"),
+						InputType:  proto.String(".foo.v1.FooRequest"),
+						OutputType: proto.String(".foo.v1.FooResponse"),
+					},
+					{
+						Name:            proto.String("Baz"),
+						InputType:       proto.String(".foo.v1.FooRequest"),
+						OutputType:      proto.String(".foo.v1.FooResponse"),
+						ServerStreaming: proto.Bool(true),
+					},
+				},
+			},
+		},
+	}
+	barFileDescriptor := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("bar/v1/bar.proto"),
+		Package:    proto.String("bar.v1"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"foo/v1/foo.proto"},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("BarService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("Bar"),
+						InputType:  proto.String(".foo.v1.FooRequest"),
+						OutputType: proto.String(".foo.v1.FooResponse"),
+					},
+				},
+			},
+		},
+	}
+	imageFiles := make([]bufimage.ImageFile, 0, 2)
+	for _, fileDescriptor := range []*descriptorpb.FileDescriptorProto{fooFileDescriptor, barFileDescriptor} {
+		imageFile, err := bufimage.NewImageFile(fileDescriptor, nil, "", "", false, false, nil)
+		require.NoError(t, err)
+		imageFiles = append(imageFiles, imageFile)
+	}
+	image, err := bufimage.NewImage(imageFiles)
+	require.NoError(t, err)
+	res, err := NewImageResolver(image)
+	require.NoError(t, err)
+	return res
+}
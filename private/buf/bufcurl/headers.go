@@ -103,14 +103,23 @@ func LoadHeaders(headerFlags []string, dataFile string, others http.Header) (htt
 	}
 	// make sure there are no disallowed headers used
 	for key := range headers {
-		lowerKey := strings.ToLower(key)
-		if _, ok := headerBlockList[lowerKey]; ok || strings.HasPrefix(lowerKey, "grpc-") || strings.HasPrefix(lowerKey, "connect-") {
+		if isReservedHeader(key) {
 			return nil, nil, fmt.Errorf("invalid header: %q is reserved and may not be used", key)
 		}
 	}
 	return headers, dataReader, nil
 }
 
+// isReservedHeader returns true if the header with the given name is part of
+// the Connect or gRPC protocol and may not be set by users.
+func isReservedHeader(key string) bool {
+	lowerKey := strings.ToLower(key)
+	if _, ok := headerBlockList[lowerKey]; ok {
+		return true
+	}
+	return strings.HasPrefix(lowerKey, "grpc-") || strings.HasPrefix(lowerKey, "connect-")
+}
+
 func readHeadersFile(headerFile string, stopAtBlankLine bool, headers http.Header) (reader io.ReadCloser, err error) {
 	var f *os.File
 	if headerFile == "-" {
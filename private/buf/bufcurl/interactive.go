@@ -0,0 +1,388 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/bufbuild/buf/private/pkg/verbose"
+	"golang.org/x/term"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	interactiveCloseCommand = "/close"
+	interactiveHelpCommand  = "/help"
+
+	interactiveHelp = `Enter one request message per line, in JSON format. Press Tab to complete field names.
+Commands:
+  /close  Close the request stream, and wait for the remaining response messages.
+  /help   Print this help.
+Ctrl-C, or Ctrl-D on an empty line, also closes the request stream.
+`
+)
+
+// ErrNotATerminal is returned by NewInteractiveTerminal if stdin is not a terminal.
+var ErrNotATerminal = errors.New("stdin is not a terminal")
+
+// InteractiveTerminal is a terminal from which the request messages of a
+// bidirectional streaming RPC are read interactively, one per line, while the
+// response messages are printed as they arrive.
+type InteractiveTerminal struct {
+	terminal *term.Terminal
+	// The file descriptor of stdin, or -1 if it is not put into raw mode.
+	fd      int
+	printer verbose.Printer
+
+	lock sync.Mutex
+	// The state of the terminal before it was put into raw mode, or nil if it
+	// is not in raw mode.
+	oldState             *term.State
+	requestStreamClosed  bool
+	responseStreamClosed bool
+}
+
+// NewInteractiveTerminal returns a new InteractiveTerminal for the stdin and
+// stdout of the container.
+//
+// ErrNotATerminal is returned if stdin is not a terminal.
+func NewInteractiveTerminal(container appflag.Container) (*InteractiveTerminal, error) {
+	file, ok := container.Stdin().(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		return nil, ErrNotATerminal
+	}
+	interactiveTerminal := newInteractiveTerminal(file, container.Stdout(), int(file.Fd()))
+	if width, height, err := term.GetSize(int(file.Fd())); err == nil && width > 0 && height > 0 {
+		if err := interactiveTerminal.terminal.SetSize(width, height); err != nil {
+			return nil, err
+		}
+	}
+	// Verbose output is written to the terminal, so that it does not garble the
+	// line being entered.
+	if container.VerbosePrinter() != verbose.NopPrinter {
+		interactiveTerminal.printer = verbose.NewWritePrinter(interactiveTerminal, container.AppName())
+	}
+	return interactiveTerminal, nil
+}
+
+func newInteractiveTerminal(in io.Reader, out io.Writer, fd int) *InteractiveTerminal {
+	return &InteractiveTerminal{
+		terminal: term.NewTerminal(
+			struct {
+				io.Reader
+				io.Writer
+			}{in, out},
+			"> ",
+		),
+		fd:      fd,
+		printer: verbose.NopPrinter,
+	}
+}
+
+// VerbosePrinter returns the verbose.Printer that writes to the terminal, or
+// verbose.NopPrinter if verbose output is not enabled.
+func (t *InteractiveTerminal) VerbosePrinter() verbose.Printer {
+	return t.printer
+}
+
+// Write writes to the terminal, above the line being entered.
+func (t *InteractiveTerminal) Write(p []byte) (int, error) {
+	return t.terminal.Write(p)
+}
+
+// newMessageProvider returns a messageProvider that reads the request messages
+// of the given method from the terminal.
+//
+// The terminal is put into raw mode until the request stream is closed.
+func (t *InteractiveTerminal) newMessageProvider(methodDescriptor protoreflect.MethodDescriptor, res Resolver) messageProvider {
+	md := methodDescriptor.Input()
+	t.terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		newLine, newPos, candidates := completeFieldName(md, line, pos)
+		if len(candidates) > 1 && newLine == line {
+			_, _ = fmt.Fprintln(t.terminal, strings.Join(candidates, "  "))
+		}
+		return newLine, newPos, true
+	}
+	_, _ = fmt.Fprintf(t, "Enter %s messages for %s, or %s for help.\n", md.FullName(), methodDescriptor.FullName(), interactiveHelpCommand)
+	return &interactiveMessageProvider{
+		terminal: t,
+		res:      res,
+	}
+}
+
+// closeResponseStream records that the response stream is closed, and notifies
+// the user if the request stream is still open.
+func (t *InteractiveTerminal) closeResponseStream() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.responseStreamClosed = true
+	if !t.requestStreamClosed {
+		_, _ = fmt.Fprintln(t.terminal, "The response stream is closed. Press Enter to exit.")
+	}
+}
+
+func (t *InteractiveTerminal) makeRaw() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.fd < 0 || t.oldState != nil || t.requestStreamClosed {
+		return nil
+	}
+	oldState, err := term.MakeRaw(t.fd)
+	if err != nil {
+		return err
+	}
+	t.oldState = oldState
+	return nil
+}
+
+// closeRequestStream records that the request stream is closed, and restores
+// the terminal from raw mode.
+func (t *InteractiveTerminal) closeRequestStream() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.requestStreamClosed = true
+	if t.oldState == nil {
+		return nil
+	}
+	oldState := t.oldState
+	t.oldState = nil
+	return term.Restore(t.fd, oldState)
+}
+
+func (t *InteractiveTerminal) isResponseStreamClosed() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.responseStreamClosed
+}
+
+type interactiveMessageProvider struct {
+	terminal *InteractiveTerminal
+	res      Resolver
+}
+
+func (i *interactiveMessageProvider) next(msg proto.Message) (retErr error) {
+	defer func() {
+		if retErr != nil {
+			if err := i.terminal.closeRequestStream(); err != nil && errors.Is(retErr, io.EOF) {
+				retErr = err
+			}
+		}
+	}()
+	if err := i.terminal.makeRaw(); err != nil {
+		return err
+	}
+	for {
+		line, err := i.terminal.terminal.ReadLine()
+		if err != nil {
+			return err
+		}
+		if i.terminal.isResponseStreamClosed() {
+			return io.EOF
+		}
+		line = strings.TrimSpace(line)
+		switch line {
+		case "":
+			continue
+		case interactiveCloseCommand:
+			return io.EOF
+		case interactiveHelpCommand:
+			_, _ = io.WriteString(i.terminal, interactiveHelp)
+			continue
+		}
+		if strings.HasPrefix(line, "/") {
+			_, _ = fmt.Fprintf(i.terminal, "unknown command %q, enter %s for help\n", line, interactiveHelpCommand)
+			continue
+		}
+		proto.Reset(msg)
+		if err := protoencoding.NewJSONUnmarshaler(i.res).Unmarshal([]byte(line), msg); err != nil {
+			// The user can correct the message and enter it again.
+			_, _ = fmt.Fprintf(i.terminal, "invalid request message: %v\n", err)
+			continue
+		}
+		return nil
+	}
+}
+
+// completeFieldName completes the name of the field that is being entered at
+// pos, in the JSON form of a message of type md.
+//
+// The line and position after completion are returned, along with the names
+// of all fields that match what was entered so far. If only one field matches,
+// its name is completed and followed by a colon.
+func completeFieldName(md protoreflect.MessageDescriptor, line string, pos int) (string, int, []string) {
+	prefix := line[:pos]
+	// The start of the partial field name, including its opening quote, if any.
+	start := pos
+	for start > 0 && isFieldNameByte(prefix[start-1]) {
+		start--
+	}
+	partial := prefix[start:]
+	if start > 0 && prefix[start-1] == '"' {
+		start--
+	}
+	before := strings.TrimRight(prefix[:start], " \t")
+	if before == "" || (before[len(before)-1] != '{' && before[len(before)-1] != ',') {
+		return line, pos, nil
+	}
+	fieldsMessage := resolveCompletionMessage(md, before)
+	if fieldsMessage == nil {
+		return line, pos, nil
+	}
+	var candidates []string
+	fields := fieldsMessage.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if jsonName := fields.Get(i).JSONName(); strings.HasPrefix(jsonName, partial) {
+			candidates = append(candidates, jsonName)
+		}
+	}
+	if len(candidates) == 0 {
+		return line, pos, nil
+	}
+	sort.Strings(candidates)
+	completion := `"` + candidates[0] + `": `
+	if len(candidates) > 1 {
+		commonPrefix := candidates[0]
+		for _, candidate := range candidates[1:] {
+			for !strings.HasPrefix(candidate, commonPrefix) {
+				commonPrefix = commonPrefix[:len(commonPrefix)-1]
+			}
+		}
+		if commonPrefix == partial {
+			return line, pos, candidates
+		}
+		completion = `"` + commonPrefix
+	}
+	suffix := line[pos:]
+	// Replace the rest of a field name that was already entered, such as when
+	// completing in the middle of a quoted name.
+	for len(suffix) > 0 && isFieldNameByte(suffix[0]) {
+		suffix = suffix[1:]
+	}
+	if len(candidates) == 1 {
+		suffix = strings.TrimPrefix(strings.TrimPrefix(strings.TrimLeft(strings.TrimPrefix(suffix, `"`), " \t"), ":"), " ")
+	}
+	return line[:start] + completion + suffix, start + len(completion), candidates
+}
+
+// resolveCompletionMessage returns the message whose fields are the keys of the
+// innermost JSON object that is open at the end of the given partial JSON, or
+// nil if it is not known.
+func resolveCompletionMessage(md protoreflect.MessageDescriptor, partialJSON string) protoreflect.MessageDescriptor {
+	type jsonFrame struct {
+		// The message whose fields are the keys of the object, or nil for an
+		// array or map.
+		message protoreflect.MessageDescriptor
+		// The field of the values of the array or map, or nil for an object of
+		// a message.
+		field protoreflect.FieldDescriptor
+		// The last key of the object.
+		key string
+	}
+	var frames []*jsonFrame
+	var inString, escaped bool
+	var lastString strings.Builder
+	for _, r := range partialJSON {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+				lastString.WriteRune(r)
+			case r == '\\':
+				escaped = true
+			case r == '"':
+				inString = false
+			default:
+				lastString.WriteRune(r)
+			}
+			continue
+		}
+		switch r {
+		case '"':
+			inString = true
+			lastString.Reset()
+		case ':':
+			if len(frames) > 0 {
+				frames[len(frames)-1].key = lastString.String()
+			}
+		case '{', '[':
+			// The field whose value is the new object or array, if known.
+			var field protoreflect.FieldDescriptor
+			var message protoreflect.MessageDescriptor
+			switch {
+			case len(frames) == 0:
+				message = md
+			case frames[len(frames)-1].message != nil:
+				parent := frames[len(frames)-1]
+				field = parent.message.Fields().ByJSONName(parent.key)
+				if field == nil {
+					field = parent.message.Fields().ByName(protoreflect.Name(parent.key))
+				}
+			default:
+				field = frames[len(frames)-1].field
+				if field != nil && field.IsMap() {
+					field = field.MapValue()
+				}
+			}
+			frame := &jsonFrame{}
+			switch {
+			case message != nil:
+				frame.message = message
+			case field == nil:
+			case r == '[' && field.IsList(), r == '{' && field.IsMap():
+				frame.field = field
+			case field.Message() != nil && !field.IsMap():
+				frame.message = field.Message()
+			}
+			if r == '[' && frame.message != nil {
+				frame.message = nil
+			}
+			frames = append(frames, frame)
+		case '}', ']':
+			if len(frames) > 0 {
+				frames = frames[:len(frames)-1]
+			}
+		}
+	}
+	if inString || len(frames) == 0 {
+		return nil
+	}
+	message := frames[len(frames)-1].message
+	if message == nil {
+		return nil
+	}
+	// Some well-known types have custom JSON representations, whose keys are
+	// not field names.
+	switch message.FullName() {
+	case "google.protobuf.Any", "google.protobuf.Struct", "google.protobuf.Value":
+		return nil
+	}
+	return message
+}
+
+func isFieldNameByte(b byte) bool {
+	return b == '_' || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9')
+}
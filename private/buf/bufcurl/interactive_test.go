@@ -0,0 +1,97 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	registryv1alpha1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/registry/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestCompleteFieldName(t *testing.T) {
+	t.Parallel()
+	md := (&descriptorpb.DescriptorProto{}).ProtoReflect().Descriptor()
+	testCompleteFieldName(t, md, `{"na`, `{"name": `, []string{"name"})
+	testCompleteFieldName(t, md, `{ na`, `{ "name": `, []string{"name"})
+	testCompleteFieldName(t, md, `{"ext`, `{"extension`, []string{"extension", "extensionRange"})
+	testCompleteFieldName(t, md, `{"name": "foo", "e`, `{"name": "foo", "e`, []string{"enumType", "extension", "extensionRange"})
+	testCompleteFieldName(t, md, `{"field": [{"js`, `{"field": [{"jsonName": `, []string{"jsonName"})
+	testCompleteFieldName(t, md, `{"field": [{"name": "a"}], "options": {"map`, `{"field": [{"name": "a"}], "options": {"mapEntry": `, []string{"mapEntry"})
+	testCompleteFieldName(t, md, `{"nested_type": [{"na`, `{"nested_type": [{"name": `, []string{"name"})
+	// Values and the elements of lists are not completed.
+	testCompleteFieldName(t, md, `{"name": "na`, `{"name": "na`, nil)
+	testCompleteFieldName(t, md, `{"reservedName": ["a", "n`, `{"reservedName": ["a", "n`, nil)
+	testCompleteFieldName(t, md, `{"unknown": {"na`, `{"unknown": {"na`, nil)
+	testCompleteFieldName(t, md, `{"x`, `{"x`, nil)
+	// The keys of maps are not completed.
+	testCompleteFieldName(
+		t,
+		(&registryv1alpha1.CreateStudioRequestRequest{}).ProtoReflect().Descriptor(),
+		`{"headers": {"na`,
+		`{"headers": {"na`,
+		nil,
+	)
+
+	// Completing in the middle of a line replaces the rest of the field name.
+	newLine, newPos, candidates := completeFieldName(md, `{"na": "foo"}`, 4)
+	assert.Equal(t, `{"name": "foo"}`, newLine)
+	assert.Equal(t, 9, newPos)
+	assert.Equal(t, []string{"name"}, candidates)
+}
+
+func TestInteractiveMessageProvider(t *testing.T) {
+	t.Parallel()
+	res := testNewImageResolver(t)
+	descriptor, err := res.FindDescriptorByName("foo.v1.FooService.Foo")
+	require.NoError(t, err)
+	methodDescriptor, ok := descriptor.(protoreflect.MethodDescriptor)
+	require.True(t, ok)
+	output := bytes.NewBuffer(nil)
+	terminal := newInteractiveTerminal(
+		strings.NewReader("/help\r{\"na\t\"bob\"}\r{bad\r/unknown\r/close\r"),
+		output,
+		-1,
+	)
+	provider := terminal.newMessageProvider(methodDescriptor, res)
+	msg := dynamicpb.NewMessage(methodDescriptor.Input())
+	require.NoError(t, provider.next(msg))
+	assert.Equal(t, "bob", msg.Get(methodDescriptor.Input().Fields().ByName("name")).String())
+	assert.Equal(t, io.EOF, provider.next(msg))
+	assert.Contains(t, output.String(), "Enter foo.v1.FooRequest messages for foo.v1.FooService.Foo, or /help for help.")
+	assert.Contains(t, output.String(), "/close  Close the request stream")
+	assert.Contains(t, output.String(), "invalid request message: ")
+	assert.Contains(t, output.String(), `unknown command "/unknown", enter /help for help`)
+}
+
+func testCompleteFieldName(
+	t *testing.T,
+	md protoreflect.MessageDescriptor,
+	line string,
+	expectedLine string,
+	expectedCandidates []string,
+) {
+	newLine, newPos, candidates := completeFieldName(md, line, len(line))
+	assert.Equal(t, expectedLine, newLine, line)
+	assert.Equal(t, len(expectedLine), newPos, line)
+	assert.Equal(t, expectedCandidates, candidates, line)
+}
@@ -24,6 +24,8 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/bufbuild/buf/private/pkg/app"
@@ -40,6 +42,55 @@ type deferredMessage struct {
 	data []byte
 }
 
+const (
+	// CodecProto is the codec that encodes messages in the binary Protobuf format.
+	//
+	// This is the default value.
+	CodecProto Codec = iota + 1
+	// CodecJSON is the codec that encodes messages in the Protobuf JSON format.
+	CodecJSON
+)
+
+var (
+	// AllKnownCodecStrings are all string values for Codec.
+	AllKnownCodecStrings = []string{
+		"proto",
+		"json",
+	}
+
+	codecToString = map[Codec]string{
+		CodecProto: "proto",
+		CodecJSON:  "json",
+	}
+	stringToCodec = map[string]Codec{
+		"proto": CodecProto,
+		"json":  CodecJSON,
+	}
+)
+
+// Codec is the codec used to encode request and response messages.
+type Codec int
+
+// String implements fmt.Stringer.
+func (c Codec) String() string {
+	s, ok := codecToString[c]
+	if !ok {
+		return strconv.Itoa(int(c))
+	}
+	return s
+}
+
+// ParseCodec parses the Codec.
+//
+// The empty string is a parse error.
+func ParseCodec(s string) (Codec, error) {
+	c, ok := stringToCodec[strings.ToLower(strings.TrimSpace(s))]
+	if ok {
+		return c, nil
+	}
+	return 0, fmt.Errorf("unknown Codec: %q", s)
+}
+
 type protoCodec struct{}
 
 func (p protoCodec) Name() string {
@@ -68,6 +119,38 @@ func (p protoCodec) Unmarshal(bytes []byte, a any) error {
 	return protoencoding.NewWireUnmarshaler(nil).Unmarshal(bytes, protoMessage)
 }
 
+// jsonCodec uses the resolver to marshal and unmarshal Any messages and extensions
+// in request messages.
+type jsonCodec struct {
+	res Resolver
+}
+
+func (j jsonCodec) Name() string {
+	return "json"
+}
+
+func (j jsonCodec) Marshal(a any) ([]byte, error) {
+	protoMessage, ok := a.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("cannot marshal: %T does not implement proto.Message", a)
+	}
+	return protoencoding.NewJSONMarshaler(j.res).Marshal(protoMessage)
+}
+
+func (j jsonCodec) Unmarshal(bytes []byte, a any) error {
+	if deferred, ok := a.(*deferredMessage); ok {
+		// must make a copy since Connect framework will re-use the byte slice
+		deferred.data = make([]byte, len(bytes))
+		copy(deferred.data, bytes)
+		return nil
+	}
+	protoMessage, ok := a.(proto.Message)
+	if !ok {
+		return fmt.Errorf("cannot unmarshal: %T does not implement proto.Message", a)
+	}
+	return protoencoding.NewJSONUnmarshaler(j.res).Unmarshal(bytes, protoMessage)
+}
+
 type invokeClient = connect.Client[dynamicpb.Message, deferredMessage]
 
 type invoker struct {
@@ -77,6 +160,17 @@ type invoker struct {
 	output    io.Writer
 	errOutput io.Writer
 	printer   verbose.Printer
+	// Unmarshals the deferred response messages, which are encoded with the codec
+	// of the client.
+	responseUnmarshaler protoencoding.Unmarshaler
+	// If true, RPC errors are returned as *connect.Error instead of being
+	// printed to errOutput.
+	returnErrors bool
+	// If non-nil, the request messages of bidirectional streams are read from
+	// the terminal instead of the data.
+	terminal *InteractiveTerminal
+	// If non-nil, the request and response of the RPC are recorded.
+	recording *Recording
 }
 
 // NewInvoker creates a new invoker for invoking the method described by the
@@ -84,18 +178,108 @@ type invoker struct {
 // in JSON format. The given resolver is used to resolve Any messages and
 // extensions that appear in the input or output. Other parameters are used
 // to create a Connect client, for issuing the RPC.
-func NewInvoker(container appflag.Container, md protoreflect.MethodDescriptor, res Resolver, httpClient connect.HTTPClient, opts []connect.ClientOption, url string, out io.Writer) Invoker {
-	opts = append(opts, connect.WithCodec(protoCodec{}))
+func NewInvoker(
+	container appflag.Container,
+	md protoreflect.MethodDescriptor,
+	res Resolver,
+	httpClient connect.HTTPClient,
+	opts []connect.ClientOption,
+	url string,
+	out io.Writer,
+	options ...InvokerOption,
+) Invoker {
+	invokerOptions := newInvokerOptions()
+	for _, option := range options {
+		option(invokerOptions)
+	}
+	var responseUnmarshaler protoencoding.Unmarshaler
+	switch invokerOptions.codec {
+	case CodecJSON:
+		opts = append(opts, connect.WithCodec(jsonCodec{res: res}))
+		responseUnmarshaler = protoencoding.NewJSONUnmarshaler(res)
+	default:
+		opts = append(opts, connect.WithCodec(protoCodec{}))
+		responseUnmarshaler = protoencoding.NewWireUnmarshaler(res)
+	}
 	// TODO: could also provide custom compressor implementations that could give us
 	//  optics into when request and response messages are compressed (which could be
 	//  useful to include in verbose output).
-	return &invoker{
-		md:        md,
-		res:       res,
-		output:    out,
-		printer:   container.VerbosePrinter(),
-		errOutput: container.Stderr(),
-		client:    connect.NewClient[dynamicpb.Message, deferredMessage](httpClient, url, opts...),
+	inv := &invoker{
+		md:                  md,
+		res:                 res,
+		output:              out,
+		printer:             container.VerbosePrinter(),
+		errOutput:           container.Stderr(),
+		client:              connect.NewClient[dynamicpb.Message, deferredMessage](httpClient, url, opts...),
+		responseUnmarshaler: responseUnmarshaler,
+		returnErrors:        invokerOptions.returnErrors,
+		terminal:            invokerOptions.terminal,
+		recording:           invokerOptions.recording,
+	}
+	if inv.terminal != nil {
+		inv.output = inv.terminal
+		inv.errOutput = inv.terminal
+		inv.printer = inv.terminal.VerbosePrinter()
+	}
+	return inv
+}
+
+// InvokerOption is an option for a new Invoker.
+type InvokerOption func(*invokerOptions)
+
+// InvokerWithCodec returns a new InvokerOption that encodes the request and
+// response messages with the given codec.
+//
+// The default is CodecProto.
+func InvokerWithCodec(codec Codec) InvokerOption {
+	return func(invokerOptions *invokerOptions) {
+		invokerOptions.codec = codec
+	}
+}
+
+// InvokerWithInteractiveTerminal returns a new InvokerOption that reads the
+// request messages of bidirectional streaming RPCs interactively from the given
+// terminal, instead of from the data given to Invoke.
+//
+// The response messages, errors and verbose output are written to the terminal,
+// instead of to the output writer and the container.
+func InvokerWithInteractiveTerminal(terminal *InteractiveTerminal) InvokerOption {
+	return func(invokerOptions *invokerOptions) {
+		invokerOptions.terminal = terminal
+	}
+}
+
+// InvokerWithRecording returns a new InvokerOption that records the request
+// and response of the RPC to the given Recording, as they are sent and
+// received. The Method of the Recording is set by the Invoker.
+//
+// The exact request and response headers are only recorded if the HTTP client
+// of the Invoker is created with NewVerboseHTTPClient.
+func InvokerWithRecording(recording *Recording) InvokerOption {
+	return func(invokerOptions *invokerOptions) {
+		invokerOptions.recording = recording
+	}
+}
+
+// invokerWithReturnErrors returns a new InvokerOption that makes the Invoker
+// return RPC errors as *connect.Error, instead of printing them and returning
+// an app error with an exit code for the RPC error.
+func invokerWithReturnErrors() InvokerOption {
+	return func(invokerOptions *invokerOptions) {
+		invokerOptions.returnErrors = true
+	}
+}
+
+type invokerOptions struct {
+	codec        Codec
+	returnErrors bool
+	terminal     *InteractiveTerminal
+	recording    *Recording
+}
+
+func newInvokerOptions() *invokerOptions {
+	return &invokerOptions{
+		codec: CodecProto,
 	}
 }
 
@@ -104,6 +288,10 @@ func (inv *invoker) Invoke(ctx context.Context, dataSource string, data io.Reade
 	// request's user-agent header(s) get overwritten by protocol, so we stash them in the
 	// context so that underlying transport can restore them
 	ctx = withUserAgent(ctx, headers)
+	if inv.recording != nil {
+		inv.recording.Method = fmt.Sprintf("%s/%s", inv.md.Parent().FullName(), inv.md.Name())
+		ctx = withRecording(ctx, inv.recording)
+	}
 	switch {
 	case inv.md.IsStreamingServer() && inv.md.IsStreamingClient():
 		return inv.handleBidiStream(ctx, dataSource, data, headers)
@@ -127,6 +315,9 @@ func (inv *invoker) handleUnary(ctx context.Context, dataSource string, data io.
 	if err := provider.next(dummy); err != io.EOF {
 		return fmt.Errorf("method %s is a unary RPC, but input contained more than one request message", inv.md.Name())
 	}
+	if err := inv.recordRequestMessage(msg); err != nil {
+		return err
+	}
 
 	req := connect.NewRequest(msg)
 	for k, v := range headers {
@@ -141,6 +332,10 @@ func (inv *invoker) handleUnary(ctx context.Context, dataSource string, data io.
 		err := inv.handleErrorResponse(connErr)
 		return err
 	}
+	inv.printResponseCompression(resp.Header())
+	if inv.recording != nil {
+		inv.recording.recordResponseTrailers(resp.Trailer())
+	}
 	return inv.handleResponse(resp.Msg.data, nil)
 }
 
@@ -174,6 +369,10 @@ func (inv *invoker) handleClientStream(ctx context.Context, dataSource string, d
 	if err != nil {
 		return err
 	}
+	inv.printResponseCompression(resp.Header())
+	if inv.recording != nil {
+		inv.recording.recordResponseTrailers(resp.Trailer())
+	}
 	return inv.handleResponse(resp.Msg.data, nil)
 }
 
@@ -188,6 +387,9 @@ func (inv *invoker) handleServerStream(ctx context.Context, dataSource string, d
 	if err := provider.next(dummy); err != io.EOF {
 		return fmt.Errorf("method %s is a unary RPC, but input contained more than one request message", inv.md.Name())
 	}
+	if err := inv.recordRequestMessage(msg); err != nil {
+		return err
+	}
 
 	req := connect.NewRequest(msg)
 	for k, v := range headers {
@@ -211,7 +413,18 @@ func (inv *invoker) handleServerStream(ctx context.Context, dataSource string, d
 
 func (inv *invoker) handleBidiStream(ctx context.Context, dataSource string, data io.Reader, headers http.Header) (retErr error) {
 	ctx, cancel := context.WithCancel(ctx)
-	provider := newStreamMessageProvider(dataSource, data, inv.res)
+	var provider messageProvider
+	if inv.terminal != nil {
+		provider = inv.terminal.newMessageProvider(inv.md, inv.res)
+		defer func() {
+			// The request stream may not have been closed by the user if the RPC failed.
+			if err := inv.terminal.closeRequestStream(); err != nil && retErr == nil {
+				retErr = err
+			}
+		}()
+	} else {
+		provider = newStreamMessageProvider(dataSource, data, inv.res)
+	}
 	msg := dynamicpb.NewMessage(inv.md.Input())
 	stream := inv.client.CallBidiStream(ctx)
 	for k, v := range headers {
@@ -236,6 +449,9 @@ func (inv *invoker) handleBidiStream(ctx context.Context, dataSource string, dat
 		if err := inv.handleStreamResponse(stream); err != nil {
 			recvErr = err
 		}
+		if inv.terminal != nil {
+			inv.terminal.closeResponseStream()
+		}
 	}()
 	defer func() {
 		wg.Wait()
@@ -277,7 +493,7 @@ func (inv *invoker) handleResponse(data []byte, msg *dynamicpb.Message) error {
 	if msg == nil {
 		msg = dynamicpb.NewMessage(inv.md.Output())
 	}
-	if err := protoencoding.NewWireUnmarshaler(inv.res).Unmarshal(data, msg); err != nil {
+	if err := inv.responseUnmarshaler.Unmarshal(data, msg); err != nil {
 		return err
 	}
 	// If we want to add a pretty-print option, we should perhaps make this a flag
@@ -286,6 +502,9 @@ func (inv *invoker) handleResponse(data []byte, msg *dynamicpb.Message) error {
 	if err != nil {
 		return err
 	}
+	if inv.recording != nil {
+		inv.recording.recordResponseMessage(outputBytes)
+	}
 	_, err = fmt.Fprintf(inv.output, "%s\n", outputBytes)
 	return err
 }
@@ -296,6 +515,8 @@ type clientStream interface {
 
 type serverStream interface {
 	Receive() (*deferredMessage, error)
+	ResponseHeader() http.Header
+	ResponseTrailer() http.Header
 	CloseResponse() error
 }
 
@@ -314,6 +535,14 @@ func (ssa *serverStreamAdapter) Receive() (*deferredMessage, error) {
 	return ssa.stream.Msg(), nil
 }
 
+func (ssa *serverStreamAdapter) ResponseHeader() http.Header {
+	return ssa.stream.ResponseHeader()
+}
+
+func (ssa *serverStreamAdapter) ResponseTrailer() http.Header {
+	return ssa.stream.ResponseTrailer()
+}
+
 func (ssa *serverStreamAdapter) CloseResponse() error {
 	return ssa.stream.Close()
 }
@@ -325,6 +554,9 @@ func (inv *invoker) handleStreamRequest(provider messageProvider, msg *dynamicpb
 		} else if err != nil {
 			return err, false
 		}
+		if err := inv.recordRequestMessage(msg); err != nil {
+			return err, false
+		}
 		if err := stream.Send(msg); err != nil {
 			return err, true
 		}
@@ -340,20 +572,46 @@ func (inv *invoker) handleStreamResponse(stream serverStream) (retError error) {
 		}
 	}()
 	msg := dynamicpb.NewMessage(inv.md.Output())
-	for {
+	for i := 0; ; i++ {
 		responseMsg, err := stream.Receive()
 		if errors.Is(err, io.EOF) {
+			if inv.recording != nil {
+				inv.recording.recordResponseTrailers(stream.ResponseTrailer())
+			}
 			return nil
 		} else if err != nil {
 			return err
 		}
+		if i == 0 {
+			// The response headers are only available once the first message is received.
+			inv.printResponseCompression(stream.ResponseHeader())
+		}
 		if err := inv.handleResponse(responseMsg.data, msg); err != nil {
 			return err
 		}
 	}
 }
 
+// printResponseCompression prints the compression of the response messages that was
+// negotiated with the server, as indicated by the response headers of the protocol.
+func (inv *invoker) printResponseCompression(header http.Header) {
+	compression := "identity"
+	for _, key := range []string{"Grpc-Encoding", "Connect-Content-Encoding", "Content-Encoding"} {
+		if value := header.Get(key); value != "" {
+			compression = value
+			break
+		}
+	}
+	inv.printer.Printf("* Response compression: %s\n", compression)
+}
+
 func (inv *invoker) handleErrorResponse(connErr *connect.Error) error {
+	if inv.recording != nil {
+		inv.recording.recordError(connErr)
+	}
+	if inv.returnErrors {
+		return connErr
+	}
 	// NB: This is a nasty hack: we create a fake request that looks
 	//     like a unary Connect request, so that the ErrorWriter will
 	//     print the error in the format we want, which is just the
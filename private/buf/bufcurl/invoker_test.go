@@ -0,0 +1,56 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestParseCodec(t *testing.T) {
+	t.Parallel()
+	for _, s := range AllKnownCodecStrings {
+		codec, err := ParseCodec(s)
+		require.NoError(t, err)
+		assert.Equal(t, s, codec.String())
+	}
+	_, err := ParseCodec("")
+	assert.Error(t, err)
+	_, err = ParseCodec("xml")
+	assert.Error(t, err)
+}
+
+func TestJSONCodec(t *testing.T) {
+	t.Parallel()
+	res := testNewImageResolver(t)
+	messageType, err := res.FindMessageByName("foo.v1.FooRequest")
+	require.NoError(t, err)
+	message := dynamicpb.NewMessage(messageType.Descriptor())
+	message.Set(messageType.Descriptor().Fields().ByName("name"), protoreflect.ValueOfString("bob"))
+	codec := jsonCodec{res: res}
+	data, err := codec.Marshal(message)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"bob"}`, string(data))
+	deferred := &deferredMessage{}
+	require.NoError(t, codec.Unmarshal(data, deferred))
+	assert.Equal(t, data, deferred.data)
+	unmarshaled := dynamicpb.NewMessage(messageType.Descriptor())
+	require.NoError(t, codec.Unmarshal(data, unmarshaled))
+	assert.Equal(t, "bob", unmarshaled.Get(messageType.Descriptor().Fields().ByName("name")).String())
+}
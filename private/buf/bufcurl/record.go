@@ -0,0 +1,352 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/diff"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/bufbuild/connect-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Recording is the request and the response of an RPC, as recorded by an
+// Invoker with InvokerWithRecording. It can be written to a file with
+// WriteRecording, and read back with ReadRecording to replay the RPC.
+//
+// Messages are in the Protobuf JSON format. Headers are the exact headers
+// that were sent and received, including the headers of the protocol, except
+// for the headers with credentials such as Authorization and Cookie, which are
+// never recorded.
+type Recording struct {
+	// Method is the method of the RPC, in the form "package.Service/Method".
+	Method string
+	// Protocol is the protocol that was used for the RPC.
+	Protocol         string
+	RequestHeaders   http.Header
+	RequestMessages  []json.RawMessage
+	ResponseHeaders  http.Header
+	ResponseMessages []json.RawMessage
+	ResponseTrailers http.Header
+	// Code is the code of the RPC, which is 0 if the RPC succeeded.
+	Code connect.Code
+	// ErrorMessage is the message of the error of the RPC, if any.
+	ErrorMessage string
+
+	// The request and response streams of an RPC are recorded concurrently.
+	lock sync.Mutex
+}
+
+// ReadRecording reads a Recording that was written with WriteRecording.
+func ReadRecording(data []byte) (*Recording, error) {
+	var externalRecording externalRecording
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&externalRecording); err != nil {
+		return nil, fmt.Errorf("invalid recording: %w", err)
+	}
+	if _, _, err := splitScriptMethod(externalRecording.Method); err != nil {
+		return nil, err
+	}
+	var code connect.Code
+	if externalRecording.Response.Code != "" {
+		var err error
+		code, err = parseScriptCode(externalRecording.Response.Code)
+		if err != nil {
+			return nil, fmt.Errorf("recorded code %q is not valid", externalRecording.Response.Code)
+		}
+	}
+	return &Recording{
+		Method:           externalRecording.Method,
+		Protocol:         externalRecording.Protocol,
+		RequestHeaders:   externalRecording.Request.Headers,
+		RequestMessages:  externalRecording.Request.Messages,
+		ResponseHeaders:  externalRecording.Response.Headers,
+		ResponseMessages: externalRecording.Response.Messages,
+		ResponseTrailers: externalRecording.Response.Trailers,
+		Code:             code,
+		ErrorMessage:     externalRecording.Response.ErrorMessage,
+	}, nil
+}
+
+// WriteRecording writes the Recording to the writer in JSON format.
+func WriteRecording(writer io.Writer, recording *Recording) error {
+	recording.lock.Lock()
+	defer recording.lock.Unlock()
+	data, err := json.MarshalIndent(
+		externalRecording{
+			Method:   recording.Method,
+			Protocol: recording.Protocol,
+			Request: externalRecordingRequest{
+				Headers:  recording.RequestHeaders,
+				Messages: nonNilMessages(recording.RequestMessages),
+			},
+			Response: externalRecordingResponse{
+				Headers:      recording.ResponseHeaders,
+				Messages:     nonNilMessages(recording.ResponseMessages),
+				Trailers:     recording.ResponseTrailers,
+				Code:         scriptCodeString(recording.Code),
+				ErrorMessage: recording.ErrorMessage,
+			},
+		},
+		"",
+		"  ",
+	)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
+
+// ReplayHeaders returns the recorded request headers that are sent again when
+// the RPC is replayed. The headers of the protocol and the user agent are not
+// included, since they are set by the client that replays the RPC. Headers with
+// credentials are not included either, even if the recording contains them, so
+// credentials must always be given again when replaying the RPC.
+func (r *Recording) ReplayHeaders() http.Header {
+	headers := http.Header{}
+	for key, values := range r.RequestHeaders {
+		if isReservedHeader(key) || isCredentialHeader(key) || strings.EqualFold(key, "user-agent") {
+			continue
+		}
+		headers[key] = append([]string{}, values...)
+	}
+	return headers
+}
+
+// ReplayData returns the recorded request messages as request data, one JSON
+// document per message.
+func (r *Recording) ReplayData() string {
+	var builder strings.Builder
+	for _, message := range r.RequestMessages {
+		builder.Write(message)
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+// DiffRecordings returns a unified diff of the responses of the two recordings
+// of the same method, or nil if the responses are the same.
+//
+// The codes, error messages and response messages are compared. Messages are
+// compared by their content, so differences in the JSON formatting of messages
+// are ignored. Headers and trailers are not compared, since they usually
+// contain values that differ between calls and environments.
+func DiffRecordings(
+	ctx context.Context,
+	runner command.Runner,
+	res Resolver,
+	expected *Recording,
+	actual *Recording,
+	expectedName string,
+	actualName string,
+) ([]byte, error) {
+	expectedData, err := recordedResponseForDiff(res, expected)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", expectedName, err)
+	}
+	actualData, err := recordedResponseForDiff(res, actual)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", actualName, err)
+	}
+	return diff.Diff(
+		ctx,
+		runner,
+		expectedData,
+		actualData,
+		expectedName,
+		actualName,
+		diff.DiffWithSuppressTimestamps(),
+	)
+}
+
+type recordingKey struct{}
+
+func withRecording(ctx context.Context, recording *Recording) context.Context {
+	return context.WithValue(ctx, recordingKey{}, recording)
+}
+
+// recordingFromContext returns the Recording of the RPC of the given context,
+// if the RPC is recorded.
+func recordingFromContext(ctx context.Context) *Recording {
+	recording, _ := ctx.Value(recordingKey{}).(*Recording)
+	return recording
+}
+
+func (r *Recording) recordRequestHeaders(headers http.Header) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.RequestHeaders = withoutCredentialHeaders(headers)
+}
+
+func (r *Recording) recordRequestMessage(message []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.RequestMessages = append(r.RequestMessages, append(json.RawMessage{}, message...))
+}
+
+func (r *Recording) recordResponseHeaders(headers http.Header) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.ResponseHeaders = withoutCredentialHeaders(headers)
+}
+
+func (r *Recording) recordResponseMessage(message []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.ResponseMessages = append(r.ResponseMessages, append(json.RawMessage{}, message...))
+}
+
+func (r *Recording) recordResponseTrailers(trailers http.Header) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if trailers = withoutCredentialHeaders(trailers); len(trailers) > 0 {
+		r.ResponseTrailers = trailers
+	}
+}
+
+// recordError records the code and message of the error of the RPC. The
+// metadata of the error is recorded as the trailers, which for some protocols
+// also includes the response headers.
+func (r *Recording) recordError(connErr *connect.Error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.Code = connErr.Code()
+	r.ErrorMessage = connErr.Message()
+	if meta := withoutCredentialHeaders(connErr.Meta()); len(meta) > 0 {
+		r.ResponseTrailers = meta
+	}
+}
+
+// isCredentialHeader returns true if the header contains credentials, which are
+// never recorded or replayed, as recordings are usually shared or checked in.
+func isCredentialHeader(key string) bool {
+	switch strings.ToLower(key) {
+	case "authorization", "cookie", "proxy-authorization", "set-cookie":
+		return true
+	default:
+		return false
+	}
+}
+
+// withoutCredentialHeaders returns a copy of the headers without the headers
+// with credentials.
+func withoutCredentialHeaders(headers http.Header) http.Header {
+	if headers == nil {
+		return nil
+	}
+	result := make(http.Header, len(headers))
+	for key, values := range headers {
+		if isCredentialHeader(key) {
+			continue
+		}
+		result[key] = append([]string{}, values...)
+	}
+	return result
+}
+
+// recordedResponseForDiff returns the parts of the response of the recording
+// that are compared by DiffRecordings, as indented JSON.
+func recordedResponseForDiff(res Resolver, recording *Recording) ([]byte, error) {
+	recording.lock.Lock()
+	defer recording.lock.Unlock()
+	service, method, err := splitScriptMethod(recording.Method)
+	if err != nil {
+		return nil, err
+	}
+	methodDescriptor, err := ResolveMethodDescriptor(res, service, method)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]json.RawMessage, 0, len(recording.ResponseMessages))
+	for i, message := range recording.ResponseMessages {
+		msg := dynamicpb.NewMessage(methodDescriptor.Output())
+		if err := protoencoding.NewJSONUnmarshaler(res).Unmarshal(message, msg); err != nil {
+			return nil, fmt.Errorf("response message %d: %w", i+1, err)
+		}
+		// Re-marshaling the message normalizes its JSON form, such as the order of fields.
+		data, err := protoencoding.NewJSONMarshaler(res).Marshal(msg)
+		if err != nil {
+			return nil, fmt.Errorf("response message %d: %w", i+1, err)
+		}
+		messages = append(messages, data)
+	}
+	data, err := json.MarshalIndent(
+		externalRecordingResponse{
+			Messages:     messages,
+			Code:         scriptCodeString(recording.Code),
+			ErrorMessage: recording.ErrorMessage,
+		},
+		"",
+		"  ",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// recordRequestMessage records the request message, if the RPC is recorded.
+func (inv *invoker) recordRequestMessage(msg proto.Message) error {
+	if inv.recording == nil {
+		return nil
+	}
+	data, err := protoencoding.NewJSONMarshaler(inv.res).Marshal(msg)
+	if err != nil {
+		return err
+	}
+	inv.recording.recordRequestMessage(data)
+	return nil
+}
+
+// nonNilMessages returns the messages, or an empty slice so that no messages are
+// written as an empty JSON array instead of null.
+func nonNilMessages(messages []json.RawMessage) []json.RawMessage {
+	if messages == nil {
+		return []json.RawMessage{}
+	}
+	return messages
+}
+
+type externalRecording struct {
+	Method   string                    `json:"method"`
+	Protocol string                    `json:"protocol,omitempty"`
+	Request  externalRecordingRequest  `json:"request"`
+	Response externalRecordingResponse `json:"response"`
+}
+
+type externalRecordingRequest struct {
+	Headers  http.Header       `json:"headers,omitempty"`
+	Messages []json.RawMessage `json:"messages"`
+}
+
+type externalRecordingResponse struct {
+	Headers      http.Header       `json:"headers,omitempty"`
+	Messages     []json.RawMessage `json:"messages"`
+	Trailers     http.Header       `json:"trailers,omitempty"`
+	Code         string            `json:"code"`
+	ErrorMessage string            `json:"error_message,omitempty"`
+}
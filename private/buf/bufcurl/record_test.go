@@ -0,0 +1,184 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/verbose"
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestReadRecording(t *testing.T) {
+	t.Parallel()
+	recording := &Recording{
+		Method:   "foo.v1.FooService/Foo",
+		Protocol: connect.ProtocolConnect,
+		RequestHeaders: http.Header{
+			"Content-Type": []string{"application/json"},
+			"X-Greeting":   []string{"hello"},
+		},
+		RequestMessages:  []json.RawMessage{json.RawMessage(`{"name":"bob"}`)},
+		ResponseHeaders:  http.Header{"Content-Type": []string{"application/json"}},
+		ResponseTrailers: http.Header{"X-Trailer": []string{"1"}},
+		Code:             connect.CodeNotFound,
+		ErrorMessage:     "no greeting",
+	}
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, WriteRecording(buffer, recording))
+	assert.Contains(t, buffer.String(), "\"messages\": []")
+	assert.Contains(t, buffer.String(), "\"code\": \"not_found\"")
+	readRecording, err := ReadRecording(buffer.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, recording.Method, readRecording.Method)
+	assert.Equal(t, recording.Protocol, readRecording.Protocol)
+	assert.Equal(t, recording.RequestHeaders, readRecording.RequestHeaders)
+	assert.Equal(t, []json.RawMessage{json.RawMessage(`{
+        "name": "bob"
+      }`)}, readRecording.RequestMessages)
+	assert.Equal(t, recording.ResponseHeaders, readRecording.ResponseHeaders)
+	assert.Equal(t, []json.RawMessage{}, readRecording.ResponseMessages)
+	assert.Equal(t, recording.ResponseTrailers, readRecording.ResponseTrailers)
+	assert.Equal(t, recording.Code, readRecording.Code)
+	assert.Equal(t, recording.ErrorMessage, readRecording.ErrorMessage)
+
+	_, err = ReadRecording([]byte(`{"method": "foo.v1.FooService"}`))
+	assert.EqualError(t, err, `method "foo.v1.FooService" must have the form "package.Service/Method"`)
+	_, err = ReadRecording([]byte(`{"method": "foo.v1.FooService/Foo", "response": {"code": "bad"}}`))
+	assert.EqualError(t, err, `recorded code "bad" is not valid`)
+	_, err = ReadRecording([]byte(`{"method": "foo.v1.FooService/Foo", "unknown": true}`))
+	assert.Error(t, err)
+}
+
+func TestRecordingReplay(t *testing.T) {
+	t.Parallel()
+	recording := &Recording{
+		RequestHeaders: http.Header{
+			"Authorization":            []string{"Bearer secret"},
+			"Connect-Protocol-Version": []string{"1"},
+			"Content-Type":             []string{"application/json"},
+			"Cookie":                   []string{"session=secret"},
+			"Grpc-Timeout":             []string{"1S"},
+			"User-Agent":               []string{"buf/1.0.0"},
+			"X-Greeting":               []string{"hello", "hi"},
+		},
+		RequestMessages: []json.RawMessage{
+			json.RawMessage(`{"name":"bob"}`),
+			json.RawMessage(`{"name":"alice"}`),
+		},
+	}
+	assert.Equal(t, http.Header{"X-Greeting": []string{"hello", "hi"}}, recording.ReplayHeaders())
+	assert.Equal(t, "{\"name\":\"bob\"}\n{\"name\":\"alice\"}\n", recording.ReplayData())
+}
+
+func TestInvokerWithRecording(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		responseWriter.Header().Set("Set-Cookie", "session=secret")
+		testServeFoo(responseWriter, request)
+	}))
+	t.Cleanup(server.Close)
+	res := testNewScriptResolver(t)
+
+	recording := testInvokeWithRecording(t, res, server, `{"name": "bob"}`, 0)
+	assert.Equal(t, "foo.v1.FooService/Foo", recording.Method)
+	assert.Equal(t, []string{"hello"}, recording.RequestHeaders.Values("X-Greeting"))
+	assert.Equal(t, []string{"application/json"}, recording.RequestHeaders.Values("Content-Type"))
+	// Credentials are never recorded.
+	assert.Empty(t, recording.RequestHeaders.Values("Authorization"))
+	assert.Empty(t, recording.ResponseHeaders.Values("Set-Cookie"))
+	assert.Equal(t, []json.RawMessage{json.RawMessage(`{"name":"bob"}`)}, testCompactMessages(t, recording.RequestMessages))
+	assert.Equal(t, []string{"application/json"}, recording.ResponseHeaders.Values("Content-Type"))
+	assert.Equal(t, []json.RawMessage{json.RawMessage(`{"greeting":"hello bob"}`)}, testCompactMessages(t, recording.ResponseMessages))
+	assert.Equal(t, connect.Code(0), recording.Code)
+
+	errorRecording := testInvokeWithRecording(t, res, server, `{"name": "missing"}`, int(connect.CodeNotFound*8))
+	assert.Empty(t, errorRecording.ResponseMessages)
+	assert.Equal(t, connect.CodeNotFound, errorRecording.Code)
+	assert.Equal(t, "no greeting for missing", errorRecording.ErrorMessage)
+
+	diffData, err := DiffRecordings(context.Background(), command.NewRunner(), res, recording, recording, "a", "b")
+	require.NoError(t, err)
+	assert.Empty(t, diffData)
+	diffData, err = DiffRecordings(context.Background(), command.NewRunner(), res, recording, errorRecording, "a", "b")
+	require.NoError(t, err)
+	assert.Contains(t, string(diffData), "--- a\n+++ b\n")
+	assert.Contains(t, string(diffData), "-      \"greeting\": \"hello bob\"\n")
+	assert.Contains(t, string(diffData), "-  \"code\": \"ok\"\n")
+	assert.Contains(t, string(diffData), "+  \"code\": \"not_found\",\n")
+}
+
+func testInvokeWithRecording(
+	t *testing.T,
+	res Resolver,
+	server *httptest.Server,
+	data string,
+	expectedExitCode int,
+) *Recording {
+	descriptor, err := res.FindDescriptorByName("foo.v1.FooService.Foo")
+	require.NoError(t, err)
+	methodDescriptor, ok := descriptor.(protoreflect.MethodDescriptor)
+	require.True(t, ok)
+	recording := &Recording{}
+	invoker := NewInvoker(
+		testNewScriptContainer(t),
+		methodDescriptor,
+		res,
+		NewVerboseHTTPClient(server.Client().Transport, verbose.NopPrinter),
+		nil,
+		server.URL+"/foo.v1.FooService/Foo",
+		bytes.NewBuffer(nil),
+		InvokerWithCodec(CodecJSON),
+		InvokerWithRecording(recording),
+	)
+	err = invoker.Invoke(
+		context.Background(),
+		"(argument)",
+		strings.NewReader(data),
+		http.Header{
+			"Authorization": []string{"Bearer secret"},
+			"X-Greeting":    []string{"hello"},
+		},
+	)
+	if expectedExitCode != 0 {
+		assert.Equal(t, expectedExitCode, app.GetExitCode(err))
+	} else {
+		require.NoError(t, err)
+	}
+	return recording
+}
+
+// testCompactMessages compacts the messages, since the JSON marshaler of
+// messages does not have a stable output.
+func testCompactMessages(t *testing.T, messages []json.RawMessage) []json.RawMessage {
+	compactMessages := make([]json.RawMessage, 0, len(messages))
+	for _, message := range messages {
+		buffer := bytes.NewBuffer(nil)
+		require.NoError(t, json.Compact(buffer, message))
+		compactMessages = append(compactMessages, buffer.Bytes())
+	}
+	return compactMessages
+}
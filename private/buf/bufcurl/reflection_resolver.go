@@ -174,6 +174,37 @@ func (r *reflectionResolver) FindDescriptorByName(name protoreflect.FullName) (p
 	return r.cachedFiles.FindDescriptorByName(name)
 }
 
+func (r *reflectionResolver) ListServices() ([]protoreflect.FullName, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.printer.Printf("* Using server reflection to list services\n")
+	resp, err := r.sendLocked(&reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_ListServices{
+			// The content is not used by the server, but the spec says
+			// to set it to "*".
+			ListServices: "*",
+		},
+	})
+	if err != nil {
+		// intentionally not using "%w" because, depending on the code, the bufcli
+		// app framework might incorrectly interpret it and report a bad error message.
+		return nil, fmt.Errorf("failed to list services: %v", err)
+	}
+	switch response := resp.MessageResponse.(type) {
+	case *reflectionv1.ServerReflectionResponse_ErrorResponse:
+		return nil, connect.NewWireError(connect.Code(response.ErrorResponse.ErrorCode), errors.New(response.ErrorResponse.ErrorMessage))
+	case *reflectionv1.ServerReflectionResponse_ListServicesResponse:
+		serviceNames := make([]protoreflect.FullName, len(response.ListServicesResponse.Service))
+		for i, service := range response.ListServicesResponse.Service {
+			serviceNames[i] = protoreflect.FullName(service.Name)
+		}
+		return serviceNames, nil
+	default:
+		return nil, fmt.Errorf("server replied with unsupported response type: %T", resp.MessageResponse)
+	}
+}
+
 func (r *reflectionResolver) FindMessageByName(message protoreflect.FullName) (protoreflect.MessageType, error) {
 	d, err := r.FindDescriptorByName(message)
 	if err != nil {
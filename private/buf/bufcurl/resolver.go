@@ -72,6 +72,18 @@ func (i *imageResolver) FindDescriptorByName(name protoreflect.FullName) (protor
 	return i.files.FindDescriptorByName(name)
 }
 
+func (i *imageResolver) ListServices() ([]protoreflect.FullName, error) {
+	var serviceNames []protoreflect.FullName
+	i.files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		services := fd.Services()
+		for j := 0; j < services.Len(); j++ {
+			serviceNames = append(serviceNames, services.Get(j).FullName())
+		}
+		return true
+	})
+	return serviceNames, nil
+}
+
 func (i *imageResolver) FindMessageByName(message protoreflect.FullName) (protoreflect.MessageType, error) {
 	d, err := i.files.FindDescriptorByName(message)
 	if err != nil {
@@ -0,0 +1,797 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bufbuild/buf/private/pkg/app/appflag"
+	"github.com/bufbuild/buf/private/pkg/encoding"
+	"github.com/bufbuild/connect-go"
+)
+
+const (
+	// ScriptReportFormatJSON is the JSON report format.
+	//
+	// This is the default value.
+	ScriptReportFormatJSON ScriptReportFormat = iota + 1
+	// ScriptReportFormatJUnit is the JUnit XML report format.
+	ScriptReportFormatJUnit
+)
+
+var (
+	// AllKnownScriptReportFormatStrings are all string values for ScriptReportFormat.
+	AllKnownScriptReportFormatStrings = []string{
+		"json",
+		"junit",
+	}
+
+	scriptReportFormatToString = map[ScriptReportFormat]string{
+		ScriptReportFormatJSON:  "json",
+		ScriptReportFormatJUnit: "junit",
+	}
+	stringToScriptReportFormat = map[string]ScriptReportFormat{
+		"json":  ScriptReportFormatJSON,
+		"junit": ScriptReportFormatJUnit,
+	}
+
+	// scriptVariableRegexp matches a reference to a variable, such as ${user_id}.
+	scriptVariableRegexp = regexp.MustCompile(`\$\{([^}]*)\}`)
+	// scriptVariableNameRegexp matches a valid variable name.
+	scriptVariableNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// ScriptReportFormat is the format of a ScriptReport.
+type ScriptReportFormat int
+
+// String implements fmt.Stringer.
+func (s ScriptReportFormat) String() string {
+	str, ok := scriptReportFormatToString[s]
+	if !ok {
+		return strconv.Itoa(int(s))
+	}
+	return str
+}
+
+// ParseScriptReportFormat parses the ScriptReportFormat.
+//
+// The empty string is a parse error.
+func ParseScriptReportFormat(s string) (ScriptReportFormat, error) {
+	f, ok := stringToScriptReportFormat[strings.ToLower(strings.TrimSpace(s))]
+	if ok {
+		return f, nil
+	}
+	return 0, fmt.Errorf("unknown ScriptReportFormat: %q", s)
+}
+
+// Script is a sequence of RPCs that are invoked against the same server.
+//
+// Values captured from the responses of earlier calls can be referenced by
+// later calls as ${name}, in the request data, headers and expected field values
+// of the calls.
+type Script struct {
+	// Name is the name of the script, used as the name of the test suite in reports.
+	Name string
+	// Headers are included with every call, in the form "name: value".
+	Headers []string
+	Calls   []*ScriptCall
+}
+
+// ScriptCall is a single RPC of a Script.
+type ScriptCall struct {
+	// Name is the name of the call, used as the name of the test case in reports.
+	//
+	// Defaults to the method.
+	Name string
+	// Method is the fully-qualified service name and method name, in the form
+	// of the last two path components of an endpoint URL.
+	Method string
+	// Headers are included with this call only, in the form "name: value".
+	Headers []string
+	// Data are the JSON documents of the request messages. If empty, a single
+	// empty request message is sent.
+	Data []string
+	// Capture maps variable names to the paths of the response fields whose
+	// values are captured.
+	Capture map[string]string
+	// ExpectCode is the expected code of the RPC, 0 for success.
+	ExpectCode connect.Code
+	// ExpectFields maps the paths of response fields to their expected values.
+	ExpectFields map[string]string
+}
+
+// ReadScript reads a Script from YAML or JSON data.
+//
+// A script has the form:
+//
+//	name: smoke-test
+//	headers:
+//	  - "Authorization: Bearer token"
+//	calls:
+//	  - name: create user
+//	    method: acme.user.v1.UserService/CreateUser
+//	    data:
+//	      name: bob
+//	    capture:
+//	      user_id: user.id
+//	    expect:
+//	      fields:
+//	        user.name: bob
+//	  - method: acme.user.v1.UserService/GetUser
+//	    data: {"id": "${user_id}"}
+//	    expect:
+//	      code: ok
+//
+// The data of a call can be a mapping, a JSON string, or a list of either,
+// one per request message. Field paths are the JSON names of fields separated
+// by dots, where the elements of lists are referenced by index, such as
+// "users.0.id". Field paths refer to the last response message.
+func ReadScript(data []byte) (*Script, error) {
+	var externalScript externalScript
+	if err := encoding.UnmarshalYAMLStrict(data, &externalScript); err != nil {
+		return nil, err
+	}
+	if len(externalScript.Calls) == 0 {
+		return nil, errors.New("script must have at least one call")
+	}
+	if err := validateScriptHeaders(externalScript.Headers); err != nil {
+		return nil, err
+	}
+	script := &Script{
+		Name:    externalScript.Name,
+		Headers: externalScript.Headers,
+		Calls:   make([]*ScriptCall, 0, len(externalScript.Calls)),
+	}
+	for i, externalScriptCall := range externalScript.Calls {
+		scriptCall, err := newScriptCall(externalScriptCall)
+		if err != nil {
+			return nil, fmt.Errorf("call %d: %w", i+1, err)
+		}
+		script.Calls = append(script.Calls, scriptCall)
+	}
+	return script, nil
+}
+
+// ScriptReport is the result of running a Script.
+type ScriptReport struct {
+	Name  string
+	Calls []*ScriptCallResult
+}
+
+// NumFailed returns the number of calls that failed.
+func (s *ScriptReport) NumFailed() int {
+	var numFailed int
+	for _, call := range s.Calls {
+		if call.Failed() {
+			numFailed++
+		}
+	}
+	return numFailed
+}
+
+// ScriptCallResult is the result of a ScriptCall.
+type ScriptCallResult struct {
+	Name   string
+	Method string
+	// Code is the code of the RPC, or empty if the RPC was not invoked or
+	// failed with an error that has no code.
+	Code     string
+	Duration time.Duration
+	// Failures are the assertions of the call that failed.
+	Failures []string
+	// Error is the error that prevented the call from completing, if any.
+	Error string
+}
+
+// Failed returns true if the call had an error or a failed assertion.
+func (s *ScriptCallResult) Failed() bool {
+	return s.Error != "" || len(s.Failures) > 0
+}
+
+// RunScript invokes the calls of the Script in order and returns the report.
+//
+// The method of each call is appended to the given baseURL to form the
+// endpoint URL. The given headers are included with every call, in addition
+// to the headers of the script and of the call. The headers of the call override
+// the headers of the script with the same name, which override the given
+// headers. All calls are invoked, even if earlier calls fail.
+func RunScript(
+	ctx context.Context,
+	container appflag.Container,
+	script *Script,
+	res Resolver,
+	httpClient connect.HTTPClient,
+	clientOptions []connect.ClientOption,
+	baseURL string,
+	headers http.Header,
+	options ...InvokerOption,
+) *ScriptReport {
+	report := &ScriptReport{
+		Name:  script.Name,
+		Calls: make([]*ScriptCallResult, 0, len(script.Calls)),
+	}
+	scriptHeaders, scriptHeadersErr := loadScriptHeaders(script.Headers, headers)
+	variables := make(map[string]string)
+	for _, call := range script.Calls {
+		result := &ScriptCallResult{
+			Name:   call.Name,
+			Method: call.Method,
+		}
+		if scriptHeadersErr != nil {
+			result.Error = scriptHeadersErr.Error()
+		} else {
+			start := time.Now()
+			runScriptCall(ctx, container, call, res, httpClient, clientOptions, baseURL, scriptHeaders, variables, result, options)
+			result.Duration = time.Since(start)
+		}
+		report.Calls = append(report.Calls, result)
+	}
+	return report
+}
+
+// WriteScriptReport writes the report to the writer in the given format.
+func WriteScriptReport(writer io.Writer, report *ScriptReport, format ScriptReportFormat) error {
+	switch format {
+	case ScriptReportFormatJSON:
+		return writeScriptReportAsJSON(writer, report)
+	case ScriptReportFormatJUnit:
+		return writeScriptReportAsJUnit(writer, report)
+	default:
+		return fmt.Errorf("unknown script report format: %v", format)
+	}
+}
+
+type externalScript struct {
+	Name    string               `json:"name,omitempty" yaml:"name,omitempty"`
+	Headers []string             `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Calls   []externalScriptCall `json:"calls,omitempty" yaml:"calls,omitempty"`
+}
+
+type externalScriptCall struct {
+	Name    string               `json:"name,omitempty" yaml:"name,omitempty"`
+	Method  string               `json:"method,omitempty" yaml:"method,omitempty"`
+	Headers []string             `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Data    interface{}          `json:"data,omitempty" yaml:"data,omitempty"`
+	Capture map[string]string    `json:"capture,omitempty" yaml:"capture,omitempty"`
+	Expect  externalScriptExpect `json:"expect,omitempty" yaml:"expect,omitempty"`
+}
+
+type externalScriptExpect struct {
+	Code   string                 `json:"code,omitempty" yaml:"code,omitempty"`
+	Fields map[string]interface{} `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+type externalScriptReport struct {
+	Name     string                     `json:"name,omitempty"`
+	Tests    int                        `json:"tests"`
+	Failures int                        `json:"failures"`
+	Calls    []externalScriptCallResult `json:"calls"`
+}
+
+type externalScriptCallResult struct {
+	Name     string   `json:"name"`
+	Method   string   `json:"method"`
+	Code     string   `json:"code,omitempty"`
+	Duration string   `json:"duration"`
+	Failures []string `json:"failures,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+func newScriptCall(externalScriptCall externalScriptCall) (*ScriptCall, error) {
+	method := externalScriptCall.Method
+	if method == "" {
+		return nil, errors.New("method is required")
+	}
+	if _, _, err := splitScriptMethod(method); err != nil {
+		return nil, err
+	}
+	if err := validateScriptHeaders(externalScriptCall.Headers); err != nil {
+		return nil, err
+	}
+	data, err := scriptDataToJSON(externalScriptCall.Data)
+	if err != nil {
+		return nil, err
+	}
+	for name, path := range externalScriptCall.Capture {
+		if !scriptVariableNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("capture variable name %q is not valid", name)
+		}
+		if path == "" {
+			return nil, fmt.Errorf("capture variable %q must have a field path", name)
+		}
+	}
+	var expectCode connect.Code
+	if externalScriptCall.Expect.Code != "" {
+		expectCode, err = parseScriptCode(externalScriptCall.Expect.Code)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var expectFields map[string]string
+	if len(externalScriptCall.Expect.Fields) > 0 {
+		expectFields = make(map[string]string, len(externalScriptCall.Expect.Fields))
+		for path, value := range externalScriptCall.Expect.Fields {
+			if path == "" {
+				return nil, errors.New("expected field path cannot be empty")
+			}
+			stringValue, ok := scriptScalarToString(value)
+			if !ok {
+				return nil, fmt.Errorf("expected value of field %q must be a scalar", path)
+			}
+			expectFields[path] = stringValue
+		}
+	}
+	name := externalScriptCall.Name
+	if name == "" {
+		name = method
+	}
+	return &ScriptCall{
+		Name:         name,
+		Method:       method,
+		Headers:      externalScriptCall.Headers,
+		Data:         data,
+		Capture:      externalScriptCall.Capture,
+		ExpectCode:   expectCode,
+		ExpectFields: expectFields,
+	}, nil
+}
+
+// loadScriptHeaders loads the headers and adds the headers of base that are
+// not overridden.
+func loadScriptHeaders(headerFlags []string, base http.Header) (http.Header, error) {
+	headers, _, err := LoadHeaders(headerFlags, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range base {
+		if _, ok := headers[key]; !ok {
+			headers[key] = values
+		}
+	}
+	return headers, nil
+}
+
+func validateScriptHeaders(headers []string) error {
+	for _, header := range headers {
+		if name, _, ok := strings.Cut(header, ":"); !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("header %q must have the form \"name: value\"", header)
+		}
+	}
+	return nil
+}
+
+// splitScriptMethod splits a method of the form "pkg.Service/Method".
+func splitScriptMethod(method string) (string, string, error) {
+	service, methodName, ok := strings.Cut(method, "/")
+	if !ok || service == "" || methodName == "" || strings.Contains(methodName, "/") {
+		return "", "", fmt.Errorf("method %q must have the form \"package.Service/Method\"", method)
+	}
+	return service, methodName, nil
+}
+
+// scriptDataToJSON converts the data of a call to JSON documents.
+func scriptDataToJSON(data interface{}) ([]string, error) {
+	if data == nil {
+		return nil, nil
+	}
+	values, ok := data.([]interface{})
+	if !ok {
+		values = []interface{}{data}
+	}
+	documents := make([]string, 0, len(values))
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			documents = append(documents, s)
+			continue
+		}
+		document, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("data cannot be converted to JSON: %w", err)
+		}
+		documents = append(documents, string(document))
+	}
+	return documents, nil
+}
+
+func parseScriptCode(s string) (connect.Code, error) {
+	if s == "ok" {
+		return 0, nil
+	}
+	var code connect.Code
+	if err := code.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("expected code %q is not valid", s)
+	}
+	return code, nil
+}
+
+func scriptCodeString(code connect.Code) string {
+	if code == 0 {
+		return "ok"
+	}
+	return code.String()
+}
+
+// scriptScalarToString returns the string form of a scalar YAML or JSON value,
+// as it is compared to the values of response fields.
+func scriptScalarToString(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case nil:
+		return "null", true
+	case string:
+		return value, true
+	case bool:
+		return strconv.FormatBool(value), true
+	case int:
+		return strconv.Itoa(value), true
+	case int64:
+		return strconv.FormatInt(value, 10), true
+	case uint64:
+		return strconv.FormatUint(value, 10), true
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64), true
+	case json.Number:
+		return value.String(), true
+	default:
+		return "", false
+	}
+}
+
+// expandScriptVariables replaces the references to variables in s with their
+// values, escaped by the given function.
+func expandScriptVariables(s string, variables map[string]string, escape func(string) string) (string, error) {
+	var expandErr error
+	expanded := scriptVariableRegexp.ReplaceAllStringFunc(s, func(reference string) string {
+		name := scriptVariableRegexp.FindStringSubmatch(reference)[1]
+		value, ok := variables[name]
+		if !ok {
+			if expandErr == nil {
+				expandErr = fmt.Errorf("variable %q is not defined", name)
+			}
+			return reference
+		}
+		return escape(value)
+	})
+	return expanded, expandErr
+}
+
+// escapeJSONString escapes s to be used inside a JSON string.
+func escapeJSONString(s string) string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return s
+	}
+	return string(data[1 : len(data)-1])
+}
+
+func noEscape(s string) string {
+	return s
+}
+
+func runScriptCall(
+	ctx context.Context,
+	container appflag.Container,
+	call *ScriptCall,
+	res Resolver,
+	httpClient connect.HTTPClient,
+	clientOptions []connect.ClientOption,
+	baseURL string,
+	scriptHeaders http.Header,
+	variables map[string]string,
+	result *ScriptCallResult,
+	options []InvokerOption,
+) {
+	var callHeaderFlags []string
+	for _, header := range call.Headers {
+		expandedHeader, err := expandScriptVariables(header, variables, noEscape)
+		if err != nil {
+			result.Error = err.Error()
+			return
+		}
+		callHeaderFlags = append(callHeaderFlags, expandedHeader)
+	}
+	callHeaders, err := loadScriptHeaders(callHeaderFlags, scriptHeaders)
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+	var data io.Reader
+	if len(call.Data) > 0 {
+		documents := make([]string, 0, len(call.Data))
+		for _, document := range call.Data {
+			expandedDocument, err := expandScriptVariables(document, variables, escapeJSONString)
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+			documents = append(documents, expandedDocument)
+		}
+		data = strings.NewReader(strings.Join(documents, "\n"))
+	}
+	service, method, err := splitScriptMethod(call.Method)
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+	methodDescriptor, err := ResolveMethodDescriptor(res, service, method)
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+	var output bytes.Buffer
+	invoker := NewInvoker(
+		container,
+		methodDescriptor,
+		res,
+		httpClient,
+		clientOptions,
+		strings.TrimSuffix(baseURL, "/")+"/"+call.Method,
+		&output,
+		append(append([]InvokerOption{}, options...), invokerWithReturnErrors())...,
+	)
+	var code connect.Code
+	var errorMessage string
+	if err := invoker.Invoke(ctx, call.Name, data, callHeaders); err != nil {
+		var connErr *connect.Error
+		if !errors.As(err, &connErr) {
+			result.Error = err.Error()
+			return
+		}
+		code = connErr.Code()
+		errorMessage = connErr.Message()
+	}
+	result.Code = scriptCodeString(code)
+	if code != call.ExpectCode {
+		failure := fmt.Sprintf("expected code %s, got %s", scriptCodeString(call.ExpectCode), result.Code)
+		if errorMessage != "" {
+			failure += ": " + errorMessage
+		}
+		result.Failures = append(result.Failures, failure)
+	}
+	if len(call.ExpectFields) == 0 && len(call.Capture) == 0 {
+		return
+	}
+	// The invoker writes each response message as a JSON document.
+	var lastMessage interface{}
+	var hasMessage bool
+	decoder := json.NewDecoder(&output)
+	decoder.UseNumber()
+	for {
+		var message interface{}
+		if err := decoder.Decode(&message); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			result.Error = err.Error()
+			return
+		}
+		lastMessage = message
+		hasMessage = true
+	}
+	for _, path := range sortedKeys(call.ExpectFields) {
+		if !hasMessage {
+			result.Failures = append(result.Failures, fmt.Sprintf("field %q: no response message", path))
+			continue
+		}
+		expected, err := expandScriptVariables(call.ExpectFields[path], variables, noEscape)
+		if err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("field %q: %v", path, err))
+			continue
+		}
+		value, ok := lookupScriptField(lastMessage, path)
+		if !ok {
+			result.Failures = append(result.Failures, fmt.Sprintf("field %q is not present in the response", path))
+			continue
+		}
+		if actual := scriptValueToString(value); actual != expected {
+			result.Failures = append(result.Failures, fmt.Sprintf("field %q: expected %q, got %q", path, expected, actual))
+		}
+	}
+	for _, name := range sortedKeys(call.Capture) {
+		path := call.Capture[name]
+		if !hasMessage {
+			result.Failures = append(result.Failures, fmt.Sprintf("capture %q: no response message", name))
+			continue
+		}
+		value, ok := lookupScriptField(lastMessage, path)
+		if !ok {
+			result.Failures = append(result.Failures, fmt.Sprintf("capture %q: field %q is not present in the response", name, path))
+			continue
+		}
+		variables[name] = scriptValueToString(value)
+	}
+}
+
+// lookupScriptField returns the value at the path of a decoded JSON value.
+func lookupScriptField(value interface{}, path string) (interface{}, bool) {
+	for _, element := range strings.Split(path, ".") {
+		switch typedValue := value.(type) {
+		case map[string]interface{}:
+			fieldValue, ok := typedValue[element]
+			if !ok {
+				return nil, false
+			}
+			value = fieldValue
+		case []interface{}:
+			index, err := strconv.Atoi(element)
+			if err != nil || index < 0 || index >= len(typedValue) {
+				return nil, false
+			}
+			value = typedValue[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// scriptValueToString returns the string form of a decoded JSON value. Values
+// that are not scalars are returned as JSON.
+func scriptValueToString(value interface{}) string {
+	if s, ok := scriptScalarToString(value); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeScriptReportAsJSON(writer io.Writer, report *ScriptReport) error {
+	externalReport := externalScriptReport{
+		Name:     report.Name,
+		Tests:    len(report.Calls),
+		Failures: report.NumFailed(),
+		Calls:    make([]externalScriptCallResult, 0, len(report.Calls)),
+	}
+	for _, call := range report.Calls {
+		externalReport.Calls = append(
+			externalReport.Calls,
+			externalScriptCallResult{
+				Name:     call.Name,
+				Method:   call.Method,
+				Code:     call.Code,
+				Duration: call.Duration.String(),
+				Failures: call.Failures,
+				Error:    call.Error,
+			},
+		)
+	}
+	data, err := json.MarshalIndent(externalReport, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
+
+func writeScriptReportAsJUnit(writer io.Writer, report *ScriptReport) error {
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	testsuites := xml.StartElement{Name: xml.Name{Local: "testsuites"}}
+	if err := encoder.EncodeToken(testsuites); err != nil {
+		return err
+	}
+	name := report.Name
+	if name == "" {
+		name = "script"
+	}
+	var numFailures, numErrors int
+	var totalDuration time.Duration
+	for _, call := range report.Calls {
+		if call.Error != "" {
+			numErrors++
+		} else if len(call.Failures) > 0 {
+			numFailures++
+		}
+		totalDuration += call.Duration
+	}
+	testsuite := xml.StartElement{
+		Name: xml.Name{Local: "testsuite"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "name"}, Value: name},
+			{Name: xml.Name{Local: "tests"}, Value: strconv.Itoa(len(report.Calls))},
+			{Name: xml.Name{Local: "failures"}, Value: strconv.Itoa(numFailures)},
+			{Name: xml.Name{Local: "errors"}, Value: strconv.Itoa(numErrors)},
+			{Name: xml.Name{Local: "time"}, Value: junitSeconds(totalDuration)},
+		},
+	}
+	if err := encoder.EncodeToken(testsuite); err != nil {
+		return err
+	}
+	for _, call := range report.Calls {
+		if err := writeScriptCallResultAsJUnit(encoder, call); err != nil {
+			return err
+		}
+	}
+	if err := encoder.EncodeToken(xml.EndElement{Name: testsuite.Name}); err != nil {
+		return err
+	}
+	if err := encoder.EncodeToken(xml.EndElement{Name: testsuites.Name}); err != nil {
+		return err
+	}
+	if err := encoder.Flush(); err != nil {
+		return err
+	}
+	if _, err := writer.Write([]byte("\n")); err != nil {
+		return err
+	}
+	return nil
+}
+
+func writeScriptCallResultAsJUnit(encoder *xml.Encoder, call *ScriptCallResult) error {
+	testcase := xml.StartElement{
+		Name: xml.Name{Local: "testcase"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "name"}, Value: call.Name},
+			{Name: xml.Name{Local: "classname"}, Value: call.Method},
+			{Name: xml.Name{Local: "time"}, Value: junitSeconds(call.Duration)},
+		},
+	}
+	if err := encoder.EncodeToken(testcase); err != nil {
+		return err
+	}
+	// A call with an error is reported as an error, otherwise each failed
+	// assertion is reported as a failure.
+	if call.Error != "" {
+		if err := writeJUnitElement(encoder, "error", call.Error); err != nil {
+			return err
+		}
+	} else {
+		for _, failure := range call.Failures {
+			if err := writeJUnitElement(encoder, "failure", failure); err != nil {
+				return err
+			}
+		}
+	}
+	return encoder.EncodeToken(xml.EndElement{Name: testcase.Name})
+}
+
+func writeJUnitElement(encoder *xml.Encoder, name string, message string) error {
+	element := xml.StartElement{
+		Name: xml.Name{Local: name},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "message"}, Value: message},
+		},
+	}
+	if err := encoder.EncodeToken(element); err != nil {
+		return err
+	}
+	return encoder.EncodeToken(xml.EndElement{Name: element.Name})
+}
+
+func junitSeconds(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', 3, 64)
+}
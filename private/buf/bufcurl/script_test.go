@@ -0,0 +1,365 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/app/appname"
+	"github.com/bufbuild/buf/private/pkg/verbose"
+	"github.com/bufbuild/connect-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestReadScript(t *testing.T) {
+	t.Parallel()
+	script, err := ReadScript([]byte(`
+name: smoke
+headers:
+  - "X-Test: 1"
+calls:
+  - method: foo.v1.FooService/Foo
+    data:
+      name: bob
+    capture:
+      greeting: greeting
+  - name: stream
+    method: foo.v1.FooService/Baz
+    data:
+      - '{"name": "${greeting}"}'
+      - name: alice
+    expect:
+      code: not_found
+      fields:
+        count: 2
+        ok: true
+`))
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		&Script{
+			Name:    "smoke",
+			Headers: []string{"X-Test: 1"},
+			Calls: []*ScriptCall{
+				{
+					Name:    "foo.v1.FooService/Foo",
+					Method:  "foo.v1.FooService/Foo",
+					Data:    []string{`{"name":"bob"}`},
+					Capture: map[string]string{"greeting": "greeting"},
+				},
+				{
+					Name:       "stream",
+					Method:     "foo.v1.FooService/Baz",
+					Data:       []string{`{"name": "${greeting}"}`, `{"name":"alice"}`},
+					ExpectCode: connect.CodeNotFound,
+					ExpectFields: map[string]string{
+						"count": "2",
+						"ok":    "true",
+					},
+				},
+			},
+		},
+		script,
+	)
+
+	_, err = ReadScript([]byte(`name: empty`))
+	assert.EqualError(t, err, "script must have at least one call")
+	_, err = ReadScript([]byte(`calls: [{method: foo.v1.FooService}]`))
+	assert.EqualError(t, err, `call 1: method "foo.v1.FooService" must have the form "package.Service/Method"`)
+	_, err = ReadScript([]byte(`calls: [{method: foo.v1.FooService/Foo, expect: {code: bad}}]`))
+	assert.EqualError(t, err, `call 1: expected code "bad" is not valid`)
+	_, err = ReadScript([]byte(`calls: [{method: foo.v1.FooService/Foo, capture: {"1x": name}}]`))
+	assert.EqualError(t, err, `call 1: capture variable name "1x" is not valid`)
+	_, err = ReadScript([]byte(`calls: [{method: foo.v1.FooService/Foo, expect: {fields: {name: [a]}}}]`))
+	assert.EqualError(t, err, `call 1: expected value of field "name" must be a scalar`)
+	_, err = ReadScript([]byte(`calls: [{method: foo.v1.FooService/Foo, headers: [bad]}]`))
+	assert.EqualError(t, err, `call 1: header "bad" must have the form "name: value"`)
+	_, err = ReadScript([]byte(`calls: [{method: foo.v1.FooService/Foo, unknown: true}]`))
+	assert.Error(t, err)
+}
+
+func TestLookupScriptField(t *testing.T) {
+	t.Parallel()
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(`{"users": [{"id": "1", "age": 30}], "ok": true}`)))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&value))
+	fieldValue, ok := lookupScriptField(value, "users.0.id")
+	require.True(t, ok)
+	assert.Equal(t, "1", scriptValueToString(fieldValue))
+	fieldValue, ok = lookupScriptField(value, "users.0.age")
+	require.True(t, ok)
+	assert.Equal(t, "30", scriptValueToString(fieldValue))
+	fieldValue, ok = lookupScriptField(value, "users")
+	require.True(t, ok)
+	assert.Equal(t, `[{"age":30,"id":"1"}]`, scriptValueToString(fieldValue))
+	fieldValue, ok = lookupScriptField(value, "ok")
+	require.True(t, ok)
+	assert.Equal(t, "true", scriptValueToString(fieldValue))
+	_, ok = lookupScriptField(value, "users.1.id")
+	assert.False(t, ok)
+	_, ok = lookupScriptField(value, "ok.value")
+	assert.False(t, ok)
+}
+
+func TestExpandScriptVariables(t *testing.T) {
+	t.Parallel()
+	variables := map[string]string{"name": `bob "the builder"`}
+	expanded, err := expandScriptVariables(`{"name": "${name}"}`, variables, escapeJSONString)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name": "bob \"the builder\""}`, expanded)
+	expanded, err = expandScriptVariables(`Name: ${name}`, variables, noEscape)
+	require.NoError(t, err)
+	assert.Equal(t, `Name: bob "the builder"`, expanded)
+	_, err = expandScriptVariables(`${missing}`, variables, noEscape)
+	assert.EqualError(t, err, `variable "missing" is not defined`)
+}
+
+func TestRunScript(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(testServeFoo))
+	t.Cleanup(server.Close)
+	script, err := ReadScript([]byte(`
+name: smoke
+headers:
+  - "X-Greeting: hello"
+calls:
+  - name: greet
+    method: foo.v1.FooService/Foo
+    data:
+      name: bob
+    capture:
+      greeting: greeting
+    expect:
+      fields:
+        greeting: hello bob
+  - name: greet again
+    method: foo.v1.FooService/Foo
+    headers:
+      - "X-Greeting: hi"
+    data: '{"name": "${greeting}"}'
+    expect:
+      fields:
+        greeting: hi hello bob
+  - name: not found
+    method: foo.v1.FooService/Foo
+    data:
+      name: missing
+    expect:
+      code: not_found
+  - name: unexpected code
+    method: foo.v1.FooService/Foo
+    data:
+      name: missing
+    expect:
+      fields:
+        greeting: hello missing
+  - name: unknown method
+    method: foo.v1.FooService/Missing
+`))
+	require.NoError(t, err)
+	report := RunScript(
+		context.Background(),
+		testNewScriptContainer(t),
+		script,
+		testNewScriptResolver(t),
+		server.Client(),
+		nil,
+		server.URL,
+		http.Header{},
+		InvokerWithCodec(CodecJSON),
+	)
+	for _, call := range report.Calls {
+		call.Duration = 0
+	}
+	assert.Equal(
+		t,
+		&ScriptReport{
+			Name: "smoke",
+			Calls: []*ScriptCallResult{
+				{
+					Name:   "greet",
+					Method: "foo.v1.FooService/Foo",
+					Code:   "ok",
+				},
+				{
+					Name:   "greet again",
+					Method: "foo.v1.FooService/Foo",
+					Code:   "ok",
+				},
+				{
+					Name:   "not found",
+					Method: "foo.v1.FooService/Foo",
+					Code:   "not_found",
+				},
+				{
+					Name:   "unexpected code",
+					Method: "foo.v1.FooService/Foo",
+					Code:   "not_found",
+					Failures: []string{
+						"expected code ok, got not_found: no greeting for missing",
+						`field "greeting": no response message`,
+					},
+				},
+				{
+					Name:   "unknown method",
+					Method: "foo.v1.FooService/Missing",
+					Error:  `URL indicates method name "Missing", but service "foo.v1.FooService" contains no such method`,
+				},
+			},
+		},
+		report,
+	)
+	assert.Equal(t, 2, report.NumFailed())
+
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, WriteScriptReport(buffer, report, ScriptReportFormatJUnit))
+	assert.Equal(
+		t,
+		`<testsuites>
+  <testsuite name="smoke" tests="5" failures="1" errors="1" time="0.000">
+    <testcase name="greet" classname="foo.v1.FooService/Foo" time="0.000"></testcase>
+    <testcase name="greet again" classname="foo.v1.FooService/Foo" time="0.000"></testcase>
+    <testcase name="not found" classname="foo.v1.FooService/Foo" time="0.000"></testcase>
+    <testcase name="unexpected code" classname="foo.v1.FooService/Foo" time="0.000">
+      <failure message="expected code ok, got not_found: no greeting for missing"></failure>
+      <failure message="field &#34;greeting&#34;: no response message"></failure>
+    </testcase>
+    <testcase name="unknown method" classname="foo.v1.FooService/Missing" time="0.000">
+      <error message="URL indicates method name &#34;Missing&#34;, but service &#34;foo.v1.FooService&#34; contains no such method"></error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+		buffer.String(),
+	)
+
+	buffer.Reset()
+	require.NoError(t, WriteScriptReport(buffer, report, ScriptReportFormatJSON))
+	externalReport := externalScriptReport{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &externalReport))
+	assert.Equal(t, 5, externalReport.Tests)
+	assert.Equal(t, 2, externalReport.Failures)
+	assert.Equal(t, "greet", externalReport.Calls[0].Name)
+	assert.Equal(t, "0s", externalReport.Calls[0].Duration)
+}
+
+func TestParseScriptReportFormat(t *testing.T) {
+	t.Parallel()
+	for _, s := range AllKnownScriptReportFormatStrings {
+		format, err := ParseScriptReportFormat(s)
+		require.NoError(t, err)
+		assert.Equal(t, s, format.String())
+	}
+	_, err := ParseScriptReportFormat("")
+	assert.Error(t, err)
+	_, err = ParseScriptReportFormat("xml")
+	assert.Error(t, err)
+}
+
+// testNewScriptResolver returns a Resolver for foo.v1.FooService/Foo as served
+// by testServeFoo.
+func testNewScriptResolver(t *testing.T) Resolver {
+	return testNewImageResolver(
+		t,
+		&descriptorpb.FieldDescriptorProto{
+			Name:     proto.String("greeting"),
+			Number:   proto.Int32(1),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			JsonName: proto.String("greeting"),
+		},
+	)
+}
+
+// testServeFoo serves foo.v1.FooService/Foo with the Connect protocol and the
+// JSON codec, greeting the name of the request with the X-Greeting header.
+func testServeFoo(responseWriter http.ResponseWriter, request *http.Request) {
+	responseWriter.Header().Set("Content-Type", "application/json")
+	if request.URL.Path != "/foo.v1.FooService/Foo" {
+		responseWriter.WriteHeader(http.StatusNotFound)
+		_, _ = responseWriter.Write([]byte(`{"code": "unimplemented"}`))
+		return
+	}
+	var fooRequest struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(request.Body).Decode(&fooRequest); err != nil {
+		responseWriter.WriteHeader(http.StatusBadRequest)
+		_, _ = responseWriter.Write([]byte(`{"code": "invalid_argument"}`))
+		return
+	}
+	if fooRequest.Name == "missing" {
+		responseWriter.WriteHeader(http.StatusNotFound)
+		_, _ = responseWriter.Write([]byte(`{"code": "not_found", "message": "no greeting for missing"}`))
+		return
+	}
+	_ = json.NewEncoder(responseWriter).Encode(map[string]string{
+		"greeting": request.Header.Get("X-Greeting") + " " + fooRequest.Name,
+	})
+}
+
+type testScriptContainer struct {
+	app.Container
+	nameContainer appname.Container
+}
+
+func testNewScriptContainer(t *testing.T) *testScriptContainer {
+	baseContainer := app.NewContainer(nil, nil, nil, nil)
+	nameContainer, err := appname.NewContainer(baseContainer, "buf")
+	require.NoError(t, err)
+	return &testScriptContainer{
+		Container:     baseContainer,
+		nameContainer: nameContainer,
+	}
+}
+
+func (c *testScriptContainer) AppName() string {
+	return c.nameContainer.AppName()
+}
+
+func (c *testScriptContainer) ConfigDirPath() string {
+	return c.nameContainer.ConfigDirPath()
+}
+
+func (c *testScriptContainer) CacheDirPath() string {
+	return c.nameContainer.CacheDirPath()
+}
+
+func (c *testScriptContainer) DataDirPath() string {
+	return c.nameContainer.DataDirPath()
+}
+
+func (c *testScriptContainer) Port() (uint16, error) {
+	return c.nameContainer.Port()
+}
+
+func (c *testScriptContainer) Logger() *zap.Logger {
+	return zap.NewNop()
+}
+
+func (c *testScriptContainer) VerbosePrinter() verbose.Printer {
+	return verbose.NopPrinter
+}
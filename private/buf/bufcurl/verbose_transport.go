@@ -123,8 +123,17 @@ func (v *verboseClient) Do(req *http.Request) (*http.Response, error) {
 			v.traceRequest(req, reqNum)
 		},
 	}
+	// The exact headers that are sent and received are recorded, including the
+	// headers of the protocol.
+	recording := recordingFromContext(req.Context())
+	if recording != nil {
+		recording.recordRequestHeaders(req.Header)
+	}
 	resp, err := v.transport.RoundTrip(req)
 	if resp != nil {
+		if recording != nil {
+			recording.recordResponseHeaders(resp.Header)
+		}
 		v.traceResponse(resp, reqNum)
 		if resp.Body != nil {
 			resp.Body = &verboseReader{
@@ -128,6 +128,17 @@ type ProtoFileRef interface {
 	internalProtoFileRef() internal.ProtoFileRef
 }
 
+// IsLocalRef returns true if the Ref is a directory or a proto file on the local
+// filesystem, as opposed to for example an archive, a git repository, or a module.
+func IsLocalRef(ref Ref) bool {
+	switch ref.internalRef().(type) {
+	case internal.DirRef, internal.ProtoFileRef:
+		return true
+	default:
+		return false
+	}
+}
+
 // ImageRefParser is an image ref parser for Buf.
 type ImageRefParser interface {
 	// GetImageRef gets the reference for the image file.
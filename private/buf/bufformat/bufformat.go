@@ -17,11 +17,13 @@ package bufformat
 
 import (
 	"context"
+	"io"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
 	"github.com/bufbuild/buf/private/pkg/thread"
+	"github.com/bufbuild/protocompile/ast"
 	"github.com/bufbuild/protocompile/parser"
 	"github.com/bufbuild/protocompile/reporter"
 	"go.uber.org/multierr"
@@ -56,7 +58,7 @@ func Format(ctx context.Context, module bufmodule.Module) (_ storage.ReadBucket,
 			defer func() {
 				retErr = multierr.Append(retErr, writeObjectCloser.Close())
 			}()
-			if err := newFormatter(writeObjectCloser, fileNode).Run(); err != nil {
+			if err := FormatFileNode(writeObjectCloser, fileNode); err != nil {
 				return err
 			}
 			return writeObjectCloser.SetExternalPath(moduleFile.ExternalPath())
@@ -66,4 +68,11 @@ func Format(ctx context.Context, module bufmodule.Module) (_ storage.ReadBucket,
 		return nil, err
 	}
 	return readWriteBucket, nil
+}
+
+// FormatFileNode formats the given file node and writes the result to the writer.
+//
+// This is used to print file nodes that were modified after parsing.
+func FormatFileNode(writer io.Writer, fileNode *ast.FileNode) error {
+	return newFormatter(writer, fileNode).Run()
 }
\ No newline at end of file
@@ -21,10 +21,14 @@ package bufgen
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"strconv"
+	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagemodify"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin/bufpluginref"
 	"github.com/bufbuild/buf/private/bufpkg/bufremoteplugin"
@@ -33,6 +37,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/connectclient"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/bufbuild/buf/private/pkg/verbose"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
@@ -40,6 +45,8 @@ import (
 const (
 	// ExternalConfigFilePath is the default external configuration file path.
 	ExternalConfigFilePath = "buf.gen.yaml"
+	// V2Version is the string used to identify the v2 version of the generate template.
+	V2Version = "v2"
 	// V1Version is the string used to identify the v1 version of the generate template.
 	V1Version = "v1"
 	// V1Beta1Version is the string used to identify the v1beta1 version of the generate template.
@@ -55,6 +62,17 @@ const (
 	StrategyAll Strategy = 2
 )
 
+const (
+	// ManifestFormatJSON is the format that says to write generation manifests as JSON.
+	ManifestFormatJSON ManifestFormat = 1
+	// ManifestFormatYAML is the format that says to write generation manifests as YAML.
+	ManifestFormatYAML ManifestFormat = 2
+)
+
+// ErrCheckFailed is returned by Generate with GenerateWithCheck if the generated
+// files on disk are out of date.
+var ErrCheckFailed = errors.New("generated files are out of date")
+
 // Strategy is a generation stategy.
 type Strategy int
 
@@ -84,6 +102,38 @@ func (s Strategy) String() string {
 	}
 }
 
+// ManifestFormat is the format of a generation manifest.
+type ManifestFormat int
+
+// ParseManifestFormat parses the ManifestFormat.
+func ParseManifestFormat(s string) (ManifestFormat, error) {
+	switch s {
+	case "json":
+		return ManifestFormatJSON, nil
+	case "yaml":
+		return ManifestFormatYAML, nil
+	default:
+		return 0, fmt.Errorf("unknown manifest format: %s", s)
+	}
+}
+
+// String implements fmt.Stringer.
+func (m ManifestFormat) String() string {
+	switch m {
+	case ManifestFormatJSON:
+		return "json"
+	case ManifestFormatYAML:
+		return "yaml"
+	default:
+		return strconv.Itoa(int(m))
+	}
+}
+
+// fileName returns the name of the generation manifest in each out directory.
+func (m ManifestFormat) fileName() string {
+	return "buf.gen.outputs." + m.String()
+}
+
 // Provider is a provider.
 type Provider interface {
 	// GetConfig gets the Config for the YAML data at ExternalConfigFilePath.
@@ -118,15 +168,58 @@ func NewGenerator(
 	storageosProvider storageos.Provider,
 	runner command.Runner,
 	clientConfig *connectclient.Config,
+	options ...GeneratorOption,
 ) Generator {
 	return newGenerator(
 		logger,
 		storageosProvider,
 		runner,
 		clientConfig,
+		options...,
 	)
 }
 
+// GeneratorOption is an option for a new Generator.
+type GeneratorOption func(*generator)
+
+// GeneratorWithCacheBucket returns a new GeneratorOption that caches the
+// CodeGeneratorResponses of local plugins in the given bucket.
+//
+// Responses are keyed by a digest of the CodeGeneratorRequests, the plugin
+// binary, and the plugin options, so that plugins are not invoked again
+// if none of these changed.
+//
+// The default is to not cache responses.
+func GeneratorWithCacheBucket(readWriteBucket storage.ReadWriteBucket) GeneratorOption {
+	return func(generator *generator) {
+		generator.cacheReadWriteBucket = readWriteBucket
+	}
+}
+
+// GeneratorWithVerbosePrinter returns a new GeneratorOption that prints
+// verbose messages, such as the cache statistics, to the given Printer.
+//
+// The default is to not print verbose messages.
+func GeneratorWithVerbosePrinter(verbosePrinter verbose.Printer) GeneratorOption {
+	return func(generator *generator) {
+		generator.verbosePrinter = verbosePrinter
+	}
+}
+
+// GeneratorWithMaxParallelPlugins returns a new GeneratorOption that limits the
+// number of local plugin invocations that run at once across all plugins.
+//
+// Plugins with the directory strategy are invoked once per directory, and every
+// invocation counts against the limit.
+//
+// The default is to only limit the number of invocations per plugin to thread.Parallelism().
+// A value of <1 has no meaning.
+func GeneratorWithMaxParallelPlugins(maxParallelPlugins int) GeneratorOption {
+	return func(generator *generator) {
+		generator.maxParallelPlugins = maxParallelPlugins
+	}
+}
+
 // GenerateOption is an option for Generate.
 type GenerateOption func(*generateOptions)
 
@@ -159,6 +252,47 @@ func GenerateWithIncludeWellKnownTypes() GenerateOption {
 	}
 }
 
+// GenerateWithClean says to delete the previously generated files that are no longer
+// generated from the out directories of all plugins, as if Clean was set for every
+// PluginConfig.
+//
+// Only the files recorded in the manifest written by a previous clean generation
+// are deleted.
+func GenerateWithClean() GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.clean = true
+	}
+}
+
+// GenerateWithCheck says to compare the generated files against the files on disk
+// instead of writing them, and to write a unified diff of the differences to the writer.
+//
+// If the files on disk are out of date, Generate returns an error for which
+// errors.Is(err, ErrCheckFailed) is true.
+func GenerateWithCheck(writer io.Writer) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.checkWriter = writer
+	}
+}
+
+// GenerateWithManifest says to write a generation manifest in the given format to
+// every out directory, after the generated files are written.
+//
+// The manifest is named buf.gen.outputs.json or buf.gen.outputs.yaml, and lists the
+// inputs the image was built from, the plugins that generated files in the out directory
+// with the source files of every CodeGeneratorRequest sent to them, and the generated
+// files with their digests and the plugins that generated them. The versions of
+// plugins are only known for remote plugins that pin a version.
+//
+// Plugins that write to a .jar or .zip archive are not included in any manifest.
+// No manifests are written with GenerateWithCheck.
+func GenerateWithManifest(manifestFormat ManifestFormat, inputRefs ...string) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.manifestFormat = manifestFormat
+		generateOptions.manifestInputRefs = inputRefs
+	}
+}
+
 // Config is a configuration.
 type Config struct {
 	// Required
@@ -167,6 +301,20 @@ type Config struct {
 	ManagedConfig *ManagedConfig
 	// Optional
 	TypesConfig *TypesConfig
+	// Optional, only set for v2
+	InputConfigs []*InputConfig
+}
+
+// InputConfig is an input configuration.
+type InputConfig struct {
+	// Required, the input in the format accepted by buffetch
+	InputRef string
+	// Optional
+	Paths []string
+	// Optional
+	ExcludePaths []string
+	// Optional
+	Types []string
 }
 
 // PluginConfig is a plugin configuration.
@@ -187,6 +335,21 @@ type PluginConfig struct {
 	Strategy Strategy
 	// Optional
 	ProtocPath string
+	// Optional, deletes the previously generated files in Out that are no longer generated
+	Clean bool
+	// Optional, only generates for the files in these normalized paths
+	IncludePaths []string
+	// Optional, does not generate for the files in these normalized paths
+	ExcludePaths []string
+	// Optional, only generates for these types and their dependencies
+	Types []string
+	// Optional, fails every invocation of a local plugin that takes longer than this
+	Timeout time.Duration
+}
+
+// hasImageFilter returns true if the PluginConfig only generates for a subset of the image.
+func (p *PluginConfig) hasImageFilter() bool {
+	return len(p.IncludePaths) > 0 || len(p.ExcludePaths) > 0 || len(p.Types) > 0
 }
 
 // PluginName returns this PluginConfig's plugin name.
@@ -244,6 +407,9 @@ type ManagedConfig struct {
 	ObjcClassPrefixConfig   *ObjcClassPrefixConfig
 	RubyPackageConfig       *RubyPackageConfig
 	Override                map[string]map[string]string
+	// Applied after all of the other managed mode options.
+	FileOptionRules  []*bufimagemodify.FileOptionRule
+	FieldOptionRules []*bufimagemodify.FieldOptionRule
 }
 
 // JavaPackagePrefixConfig is the java_package prefix configuration.
@@ -355,23 +521,33 @@ type ExternalPluginConfigV1 struct {
 	Path       interface{} `json:"path,omitempty" yaml:"path,omitempty"`
 	ProtocPath string      `json:"protoc_path,omitempty" yaml:"protoc_path,omitempty"`
 	Strategy   string      `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	Clean      bool        `json:"clean,omitempty" yaml:"clean,omitempty"`
+	// IncludePaths, ExcludePaths and Types restrict the plugin to a subset of the input, in
+	// addition to any restrictions given on the command line.
+	IncludePaths []string `json:"include_paths,omitempty" yaml:"include_paths,omitempty"`
+	ExcludePaths []string `json:"exclude_paths,omitempty" yaml:"exclude_paths,omitempty"`
+	Types        []string `json:"types,omitempty" yaml:"types,omitempty"`
+	// Timeout is a duration such as "30s" after which every invocation of a local plugin fails.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 }
 
 // ExternalManagedConfigV1 is an external managed mode configuration.
 //
 // Only use outside of this package for testing.
 type ExternalManagedConfigV1 struct {
-	Enabled             bool                              `json:"enabled,omitempty" yaml:"enabled,omitempty"`
-	CcEnableArenas      *bool                             `json:"cc_enable_arenas,omitempty" yaml:"cc_enable_arenas,omitempty"`
-	JavaMultipleFiles   *bool                             `json:"java_multiple_files,omitempty" yaml:"java_multiple_files,omitempty"`
-	JavaStringCheckUtf8 *bool                             `json:"java_string_check_utf8,omitempty" yaml:"java_string_check_utf8,omitempty"`
-	JavaPackagePrefix   ExternalJavaPackagePrefixConfigV1 `json:"java_package_prefix,omitempty" yaml:"java_package_prefix,omitempty"`
-	CsharpNamespace     ExternalCsharpNamespaceConfigV1   `json:"csharp_namespace,omitempty" yaml:"csharp_namespace,omitempty"`
-	OptimizeFor         ExternalOptimizeForConfigV1       `json:"optimize_for,omitempty" yaml:"optimize_for,omitempty"`
-	GoPackagePrefix     ExternalGoPackagePrefixConfigV1   `json:"go_package_prefix,omitempty" yaml:"go_package_prefix,omitempty"`
-	ObjcClassPrefix     ExternalObjcClassPrefixConfigV1   `json:"objc_class_prefix,omitempty" yaml:"objc_class_prefix,omitempty"`
-	RubyPackage         ExternalRubyPackageConfigV1       `json:"ruby_package,omitempty" yaml:"ruby_package,omitempty"`
-	Override            map[string]map[string]string      `json:"override,omitempty" yaml:"override,omitempty"`
+	Enabled             bool                               `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	CcEnableArenas      *bool                              `json:"cc_enable_arenas,omitempty" yaml:"cc_enable_arenas,omitempty"`
+	JavaMultipleFiles   *bool                              `json:"java_multiple_files,omitempty" yaml:"java_multiple_files,omitempty"`
+	JavaStringCheckUtf8 *bool                              `json:"java_string_check_utf8,omitempty" yaml:"java_string_check_utf8,omitempty"`
+	JavaPackagePrefix   ExternalJavaPackagePrefixConfigV1  `json:"java_package_prefix,omitempty" yaml:"java_package_prefix,omitempty"`
+	CsharpNamespace     ExternalCsharpNamespaceConfigV1    `json:"csharp_namespace,omitempty" yaml:"csharp_namespace,omitempty"`
+	OptimizeFor         ExternalOptimizeForConfigV1        `json:"optimize_for,omitempty" yaml:"optimize_for,omitempty"`
+	GoPackagePrefix     ExternalGoPackagePrefixConfigV1    `json:"go_package_prefix,omitempty" yaml:"go_package_prefix,omitempty"`
+	ObjcClassPrefix     ExternalObjcClassPrefixConfigV1    `json:"objc_class_prefix,omitempty" yaml:"objc_class_prefix,omitempty"`
+	RubyPackage         ExternalRubyPackageConfigV1        `json:"ruby_package,omitempty" yaml:"ruby_package,omitempty"`
+	Override            map[string]map[string]string       `json:"override,omitempty" yaml:"override,omitempty"`
+	Rules               []ExternalManagedRuleConfigV1      `json:"rules,omitempty" yaml:"rules,omitempty"`
+	FieldRules          []ExternalManagedFieldRuleConfigV1 `json:"field_rules,omitempty" yaml:"field_rules,omitempty"`
 }
 
 // IsEmpty returns true if the config is empty, excluding the 'Enabled' setting.
@@ -386,7 +562,39 @@ func (e ExternalManagedConfigV1) IsEmpty() bool {
 		e.GoPackagePrefix.IsEmpty() &&
 		e.ObjcClassPrefix.IsEmpty() &&
 		e.RubyPackage.IsEmpty() &&
-		len(e.Override) == 0
+		len(e.Override) == 0 &&
+		len(e.Rules) == 0 &&
+		len(e.FieldRules) == 0
+}
+
+// ExternalManagedRuleConfigV1 is an external managed mode rule configuration.
+//
+// A rule sets or disables a file option for the files that match all of
+// Module, Path and Package. A rule without any of these matches all files.
+type ExternalManagedRuleConfigV1 struct {
+	Option string `json:"option,omitempty" yaml:"option,omitempty"`
+	// Value is either a bool or a string.
+	Value   interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	Disable bool        `json:"disable,omitempty" yaml:"disable,omitempty"`
+	Module  string      `json:"module,omitempty" yaml:"module,omitempty"`
+	Path    string      `json:"path,omitempty" yaml:"path,omitempty"`
+	Package string      `json:"package,omitempty" yaml:"package,omitempty"`
+}
+
+// ExternalManagedFieldRuleConfigV1 is an external managed mode field rule configuration.
+//
+// A field rule sets or disables a field option for the fields that match all of
+// Module, Package, Message and Field. A field rule without any of these matches all
+// fields for which the option is valid.
+type ExternalManagedFieldRuleConfigV1 struct {
+	Option string `json:"option,omitempty" yaml:"option,omitempty"`
+	// Value is either a bool or a string.
+	Value   interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	Disable bool        `json:"disable,omitempty" yaml:"disable,omitempty"`
+	Module  string      `json:"module,omitempty" yaml:"module,omitempty"`
+	Package string      `json:"package,omitempty" yaml:"package,omitempty"`
+	Message string      `json:"message,omitempty" yaml:"message,omitempty"`
+	Field   string      `json:"field,omitempty" yaml:"field,omitempty"`
 }
 
 // ExternalJavaPackagePrefixConfigV1 is the external java_package prefix configuration.
@@ -531,6 +739,25 @@ func (e ExternalObjcClassPrefixConfigV1) IsEmpty() bool {
 		len(e.Override) == 0
 }
 
+// ExternalConfigV2 is an external configuration.
+//
+// This is a superset of ExternalConfigV1 that adds the inputs to generate for.
+type ExternalConfigV2 struct {
+	Version string                   `json:"version,omitempty" yaml:"version,omitempty"`
+	Plugins []ExternalPluginConfigV1 `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+	Managed ExternalManagedConfigV1  `json:"managed,omitempty" yaml:"managed,omitempty"`
+	Types   *ExternalTypesConfigV1   `json:"types,omitempty" yaml:"types,omitempty"`
+	Inputs  []ExternalInputConfigV2  `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+}
+
+// ExternalInputConfigV2 is an external input configuration.
+type ExternalInputConfigV2 struct {
+	Input        string   `json:"input,omitempty" yaml:"input,omitempty"`
+	Paths        []string `json:"paths,omitempty" yaml:"paths,omitempty"`
+	ExcludePaths []string `json:"exclude_paths,omitempty" yaml:"exclude_paths,omitempty"`
+	Types        []string `json:"types,omitempty" yaml:"types,omitempty"`
+}
+
 // ExternalConfigV1Beta1 is an external configuration.
 type ExternalConfigV1Beta1 struct {
 	Version string                        `json:"version,omitempty" yaml:"version,omitempty"`
@@ -21,13 +21,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagemodify"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin/bufpluginref"
 	"github.com/bufbuild/buf/private/bufpkg/bufremoteplugin"
 	"github.com/bufbuild/buf/private/pkg/encoding"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
@@ -124,6 +129,15 @@ func getConfig(
 			return nil, err
 		}
 		return newConfigV1(logger, externalConfigV1, id)
+	case V2Version:
+		var externalConfigV2 ExternalConfigV2
+		if err := unmarshalStrict(data, &externalConfigV2); err != nil {
+			return nil, err
+		}
+		if err := validateExternalConfigV2(externalConfigV2, id); err != nil {
+			return nil, err
+		}
+		return newConfigV2(logger, externalConfigV2, id)
 	default:
 		return nil, fmt.Errorf(`%s has no version set. Please add "version: %s"`, id, V1Version)
 	}
@@ -148,16 +162,39 @@ func newConfigV1(logger *zap.Logger, externalConfig ExternalConfigV1, id string)
 		if err != nil {
 			return nil, err
 		}
+		includePaths, err := newPluginPathsV1(plugin.IncludePaths)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid include_paths: %w", id, err)
+		}
+		excludePaths, err := newPluginPathsV1(plugin.ExcludePaths)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid exclude_paths: %w", id, err)
+		}
+		excludePathMap := stringutil.SliceToMap(excludePaths)
+		for _, includePath := range includePaths {
+			if _, ok := excludePathMap[includePath]; ok {
+				return nil, fmt.Errorf("%s: cannot set the same path for both include_paths and exclude_paths: %s", id, includePath)
+			}
+		}
+		timeout, err := newPluginTimeoutV1(plugin.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid timeout: %w", id, err)
+		}
 		pluginConfig := &PluginConfig{
-			Plugin:     plugin.Plugin,
-			Revision:   plugin.Revision,
-			Name:       plugin.Name,
-			Remote:     plugin.Remote,
-			Out:        plugin.Out,
-			Opt:        opt,
-			Path:       path,
-			ProtocPath: plugin.ProtocPath,
-			Strategy:   strategy,
+			Plugin:       plugin.Plugin,
+			Revision:     plugin.Revision,
+			Name:         plugin.Name,
+			Remote:       plugin.Remote,
+			Out:          plugin.Out,
+			Opt:          opt,
+			Path:         path,
+			ProtocPath:   plugin.ProtocPath,
+			Strategy:     strategy,
+			Clean:        plugin.Clean,
+			IncludePaths: includePaths,
+			ExcludePaths: excludePaths,
+			Types:        plugin.Types,
+			Timeout:      timeout,
 		}
 		if pluginConfig.IsRemote() {
 			// Always use StrategyAll for remote plugins
@@ -173,6 +210,81 @@ func newConfigV1(logger *zap.Logger, externalConfig ExternalConfigV1, id string)
 	}, nil
 }
 
+func newConfigV2(logger *zap.Logger, externalConfig ExternalConfigV2, id string) (*Config, error) {
+	config, err := newConfigV1(logger, externalConfigV2ToV1(externalConfig), id)
+	if err != nil {
+		return nil, err
+	}
+	for _, input := range externalConfig.Inputs {
+		config.InputConfigs = append(
+			config.InputConfigs,
+			&InputConfig{
+				InputRef:     input.Input,
+				Paths:        input.Paths,
+				ExcludePaths: input.ExcludePaths,
+				Types:        input.Types,
+			},
+		)
+	}
+	return config, nil
+}
+
+func validateExternalConfigV2(externalConfig ExternalConfigV2, id string) error {
+	if err := validateExternalConfigV1(externalConfigV2ToV1(externalConfig), id); err != nil {
+		return err
+	}
+	for _, input := range externalConfig.Inputs {
+		if input.Input == "" {
+			return fmt.Errorf("%s: input is required for every entry in inputs", id)
+		}
+	}
+	return nil
+}
+
+// externalConfigV2ToV1 returns the part of the ExternalConfigV2 that is shared with ExternalConfigV1.
+func externalConfigV2ToV1(externalConfig ExternalConfigV2) ExternalConfigV1 {
+	return ExternalConfigV1{
+		Version: externalConfig.Version,
+		Plugins: externalConfig.Plugins,
+		Managed: externalConfig.Managed,
+		Types:   externalConfig.Types,
+	}
+}
+
+// newPluginPathsV1 normalizes and validates the include or exclude paths of a plugin.
+func newPluginPathsV1(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	normalizedPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
+		normalizedPath, err := normalpath.NormalizeAndValidate(path)
+		if err != nil {
+			return nil, err
+		}
+		if normalizedPath == "." {
+			return nil, errors.New(`"." is not a valid path value`)
+		}
+		normalizedPaths = append(normalizedPaths, normalizedPath)
+	}
+	return stringutil.SliceToUniqueSortedSlice(normalizedPaths), nil
+}
+
+// newPluginTimeoutV1 parses and validates the timeout of a plugin.
+func newPluginTimeoutV1(timeout string) (time.Duration, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, err
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("%q is not a positive duration", timeout)
+	}
+	return duration, nil
+}
+
 func validateExternalConfigV1(externalConfig ExternalConfigV1, id string) error {
 	if len(externalConfig.Plugins) == 0 {
 		return fmt.Errorf("%s: no plugins set", id)
@@ -243,6 +355,9 @@ func checkPathAndStrategyUnset(id string, plugin ExternalPluginConfigV1, pluginI
 	if plugin.ProtocPath != "" {
 		return fmt.Errorf("%s: remote plugin %s cannot specify a protoc path", id, pluginIdentifier)
 	}
+	if plugin.Timeout != "" {
+		return fmt.Errorf("%s: remote plugin %s cannot specify a timeout", id, pluginIdentifier)
+	}
 	return nil
 }
 
@@ -277,6 +392,14 @@ func newManagedConfigV1(logger *zap.Logger, externalManagedConfig ExternalManage
 	if err != nil {
 		return nil, err
 	}
+	fileOptionRules, err := newFileOptionRulesV1(externalManagedConfig.Rules)
+	if err != nil {
+		return nil, err
+	}
+	fieldOptionRules, err := newFieldOptionRulesV1(externalManagedConfig.FieldRules)
+	if err != nil {
+		return nil, err
+	}
 	override := externalManagedConfig.Override
 	for overrideID, overrideValue := range override {
 		for importPath := range overrideValue {
@@ -308,9 +431,106 @@ func newManagedConfigV1(logger *zap.Logger, externalManagedConfig ExternalManage
 		ObjcClassPrefixConfig:   objcClassPrefixConfig,
 		RubyPackageConfig:       rubyPackageConfig,
 		Override:                override,
+		FileOptionRules:         fileOptionRules,
+		FieldOptionRules:        fieldOptionRules,
 	}, nil
 }
 
+func newFileOptionRulesV1(externalRules []ExternalManagedRuleConfigV1) ([]*bufimagemodify.FileOptionRule, error) {
+	if len(externalRules) == 0 {
+		return nil, nil
+	}
+	fileOptionRules := make([]*bufimagemodify.FileOptionRule, 0, len(externalRules))
+	for i, externalRule := range externalRules {
+		if externalRule.Option == "" {
+			return nil, fmt.Errorf("invalid managed mode rule %d: option is required", i+1)
+		}
+		value, err := newManagedRuleValueV1(externalRule.Value, externalRule.Disable)
+		if err != nil {
+			return nil, fmt.Errorf("invalid managed mode rule %d: %s: %w", i+1, externalRule.Option, err)
+		}
+		fileOptionRule := &bufimagemodify.FileOptionRule{
+			Option:  externalRule.Option,
+			Value:   value,
+			Disable: externalRule.Disable,
+			Package: externalRule.Package,
+		}
+		if externalRule.Module != "" {
+			moduleIdentity, err := bufmoduleref.ModuleIdentityForString(externalRule.Module)
+			if err != nil {
+				return nil, fmt.Errorf("invalid managed mode rule %d: invalid module: %w", i+1, err)
+			}
+			fileOptionRule.ModuleIdentity = moduleIdentity
+		}
+		if externalRule.Path != "" {
+			path, err := normalpath.NormalizeAndValidate(externalRule.Path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid managed mode rule %d: invalid path: %w", i+1, err)
+			}
+			fileOptionRule.Path = path
+		}
+		if err := bufimagemodify.ValidateFileOptionRule(fileOptionRule); err != nil {
+			return nil, fmt.Errorf("invalid managed mode rule %d: %w", i+1, err)
+		}
+		fileOptionRules = append(fileOptionRules, fileOptionRule)
+	}
+	return fileOptionRules, nil
+}
+
+func newFieldOptionRulesV1(externalFieldRules []ExternalManagedFieldRuleConfigV1) ([]*bufimagemodify.FieldOptionRule, error) {
+	if len(externalFieldRules) == 0 {
+		return nil, nil
+	}
+	fieldOptionRules := make([]*bufimagemodify.FieldOptionRule, 0, len(externalFieldRules))
+	for i, externalFieldRule := range externalFieldRules {
+		if externalFieldRule.Option == "" {
+			return nil, fmt.Errorf("invalid managed mode field rule %d: option is required", i+1)
+		}
+		value, err := newManagedRuleValueV1(externalFieldRule.Value, externalFieldRule.Disable)
+		if err != nil {
+			return nil, fmt.Errorf("invalid managed mode field rule %d: %s: %w", i+1, externalFieldRule.Option, err)
+		}
+		fieldOptionRule := &bufimagemodify.FieldOptionRule{
+			Option:  externalFieldRule.Option,
+			Value:   value,
+			Disable: externalFieldRule.Disable,
+			Package: externalFieldRule.Package,
+			Message: strings.TrimPrefix(externalFieldRule.Message, "."),
+			Field:   externalFieldRule.Field,
+		}
+		if externalFieldRule.Module != "" {
+			moduleIdentity, err := bufmoduleref.ModuleIdentityForString(externalFieldRule.Module)
+			if err != nil {
+				return nil, fmt.Errorf("invalid managed mode field rule %d: invalid module: %w", i+1, err)
+			}
+			fieldOptionRule.ModuleIdentity = moduleIdentity
+		}
+		if err := bufimagemodify.ValidateFieldOptionRule(fieldOptionRule); err != nil {
+			return nil, fmt.Errorf("invalid managed mode field rule %d: %w", i+1, err)
+		}
+		fieldOptionRules = append(fieldOptionRules, fieldOptionRule)
+	}
+	return fieldOptionRules, nil
+}
+
+// newManagedRuleValueV1 returns the value of a managed mode rule as a string.
+func newManagedRuleValueV1(externalValue interface{}, disable bool) (string, error) {
+	var value string
+	switch t := externalValue.(type) {
+	case nil:
+	case bool:
+		value = strconv.FormatBool(t)
+	case string:
+		value = t
+	default:
+		return "", errors.New("value must be a bool or a string")
+	}
+	if value == "" && !disable {
+		return "", errors.New("one of value or disable is required")
+	}
+	return value, nil
+}
+
 func newJavaPackagePrefixConfigV1(externalJavaPackagePrefixConfig ExternalJavaPackagePrefixConfigV1) (*JavaPackagePrefixConfig, error) {
 	if externalJavaPackagePrefixConfig.IsEmpty() {
 		return nil, nil
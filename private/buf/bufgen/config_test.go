@@ -20,6 +20,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagemodify"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
@@ -369,6 +370,100 @@ func TestReadConfigV1(t *testing.T) {
 		},
 	}
 
+	successConfig10 := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Plugin:   "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+			},
+			{
+				Plugin:       "go-grpc",
+				Out:          "gen/go",
+				Strategy:     StrategyDirectory,
+				IncludePaths: []string{"api", "other/v1/foo.proto"},
+				ExcludePaths: []string{"api/internal"},
+				Types:        []string{"api.v1.FooService"},
+			},
+		},
+	}
+
+	successConfig11 := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Plugin:   "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+				Timeout:  90 * time.Second,
+			},
+			{
+				Plugin:   "go-grpc",
+				Out:      "gen/go",
+				Strategy: StrategyAll,
+				Timeout:  10 * time.Second,
+			},
+		},
+	}
+
+	successConfig12 := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Plugin:   "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+			},
+		},
+		ManagedConfig: &ManagedConfig{
+			FileOptionRules: []*bufimagemodify.FileOptionRule{
+				{
+					Option: "java_generic_services",
+					Value:  "true",
+				},
+				{
+					Option:         "swift_prefix",
+					Value:          "Acme",
+					ModuleIdentity: mustCreateModuleIdentity(t, "someremote.com", "owner", "repo"),
+				},
+				{
+					Option:  "php_class_prefix",
+					Value:   "Acme",
+					Path:    "acme/weather",
+					Package: "acme.weather",
+				},
+				{
+					Option:  "java_package",
+					Disable: true,
+				},
+			},
+		},
+	}
+
+	successConfig13 := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Plugin:   "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+			},
+		},
+		ManagedConfig: &ManagedConfig{
+			FieldOptionRules: []*bufimagemodify.FieldOptionRule{
+				{
+					Option: "jstype",
+					Value:  "JS_STRING",
+				},
+				{
+					Option:         "jstype",
+					Disable:        true,
+					ModuleIdentity: mustCreateModuleIdentity(t, "someremote.com", "owner", "repo"),
+					Package:        "acme.weather",
+					Message:        "acme.weather.v1.Forecast",
+					Field:          "id",
+				},
+			},
+		},
+	}
+
 	ctx := context.Background()
 	nopLogger := zap.NewNop()
 	provider := NewProvider(zap.NewNop())
@@ -587,6 +682,30 @@ func TestReadConfigV1(t *testing.T) {
 	config, err = ReadConfig(ctx, nopLogger, provider, readBucket, ReadConfigWithOverride(string(data)))
 	require.NoError(t, err)
 	assertConfigsWithEqualOptimizeFor(t, successConfig9, config)
+	config, err = ReadConfig(ctx, nopLogger, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success10.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig10, config)
+	config, err = ReadConfig(ctx, nopLogger, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success10.json")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig10, config)
+	config, err = ReadConfig(ctx, nopLogger, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success11.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig11, config)
+	config, err = ReadConfig(ctx, nopLogger, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success11.json")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig11, config)
+	config, err = ReadConfig(ctx, nopLogger, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success12.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig12, config)
+	config, err = ReadConfig(ctx, nopLogger, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success12.json")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig12, config)
+	config, err = ReadConfig(ctx, nopLogger, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success13.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig13, config)
+	config, err = ReadConfig(ctx, nopLogger, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v1", "gen_success13.json")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig13, config)
 
 	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error1.yaml"))
 	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error2.yaml"))
@@ -602,6 +721,15 @@ func TestReadConfigV1(t *testing.T) {
 	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error12.yaml"))
 	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error13.yaml"))
 	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error14.yaml"))
+	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error15.yaml"))
+	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error16.yaml"))
+	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error17.yaml"))
+	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error18.yaml"))
+	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error19.yaml"))
+	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error20.yaml"))
+	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error21.yaml"))
+	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error22.yaml"))
+	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "gen_error23.yaml"))
 
 	successConfig = &Config{
 		PluginConfigs: []*PluginConfig{
@@ -647,6 +775,43 @@ func TestReadConfigV1(t *testing.T) {
 	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v1", "go_gen_error6.yaml"))
 }
 
+func TestReadConfigV2(t *testing.T) {
+	successConfig := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Plugin:   "go",
+				Out:      "gen/go",
+				Strategy: StrategyDirectory,
+			},
+		},
+		InputConfigs: []*InputConfig{
+			{
+				InputRef:     "https://github.com/foo/bar.git#branch=main",
+				Paths:        []string{"proto/foo"},
+				ExcludePaths: []string{"proto/foo/internal"},
+				Types:        []string{"foo.v1.Foo"},
+			},
+			{
+				InputRef: "../protos",
+			},
+		},
+	}
+	ctx := context.Background()
+	nopLogger := zap.NewNop()
+	provider := NewProvider(zap.NewNop())
+	readBucket, err := storagemem.NewReadBucket(nil)
+	require.NoError(t, err)
+	config, err := ReadConfig(ctx, nopLogger, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v2", "gen_success1.yaml")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig, config)
+	config, err = ReadConfig(ctx, nopLogger, provider, readBucket, ReadConfigWithOverride(filepath.Join("testdata", "v2", "gen_success1.json")))
+	require.NoError(t, err)
+	require.Equal(t, successConfig, config)
+
+	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v2", "gen_error1.yaml"))
+	testReadConfigError(t, nopLogger, provider, readBucket, filepath.Join("testdata", "v2", "gen_error2.yaml"))
+}
+
 func testReadConfigError(t *testing.T, logger *zap.Logger, provider Provider, readBucket storage.ReadBucket, testFilePath string) {
 	ctx := context.Background()
 	_, err := ReadConfig(ctx, logger, provider, readBucket, ReadConfigWithOverride(testFilePath))
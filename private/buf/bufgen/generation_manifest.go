@@ -0,0 +1,303 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufplugin/bufpluginref"
+	"github.com/bufbuild/buf/private/bufpkg/bufremoteplugin"
+	"github.com/bufbuild/buf/private/pkg/encoding"
+	"github.com/bufbuild/buf/private/pkg/manifest"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"go.uber.org/multierr"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// externalGenerationManifest is the generation manifest written to an out directory.
+type externalGenerationManifest struct {
+	// The inputs that the image was built from, in the order they were specified.
+	Inputs  []string                                `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+	Plugins []*externalGenerationManifestPlugin     `json:"plugins" yaml:"plugins"`
+	Files   []*externalGenerationManifestOutputFile `json:"files" yaml:"files"`
+}
+
+// externalGenerationManifestPlugin is a plugin that generated files in the out directory.
+type externalGenerationManifestPlugin struct {
+	Name string `json:"name" yaml:"name"`
+	// Only known for remote plugins that pin a version.
+	Version  string `json:"version,omitempty" yaml:"version,omitempty"`
+	Revision int    `json:"revision,omitempty" yaml:"revision,omitempty"`
+	// The digest of the plugin binary, only known for local plugins with a path
+	// without arguments, as otherwise the binary is for example an interpreter.
+	Digest   string                               `json:"digest,omitempty" yaml:"digest,omitempty"`
+	Requests []*externalGenerationManifestRequest `json:"requests" yaml:"requests"`
+}
+
+// externalGenerationManifestRequest is a CodeGeneratorRequest sent to a plugin.
+type externalGenerationManifestRequest struct {
+	SourceFiles []string `json:"source_files" yaml:"source_files"`
+}
+
+// externalGenerationManifestOutputFile is a file generated in the out directory.
+type externalGenerationManifestOutputFile struct {
+	// Relative to the out directory.
+	Path   string `json:"path" yaml:"path"`
+	Digest string `json:"digest" yaml:"digest"`
+	// The name of the plugin that generated the file, which is one of the plugins
+	// of the manifest.
+	Plugin string `json:"plugin" yaml:"plugin"`
+	// The source files of the request that the file was generated for.
+	SourceFiles []string `json:"source_files" yaml:"source_files"`
+}
+
+// pluginSourceFiles are the source files that a plugin was sent.
+type pluginSourceFiles struct {
+	// requestSourceFiles are the files to generate of each CodeGeneratorRequest sent
+	// to the plugin that has files to generate.
+	requestSourceFiles [][]string
+	// pathToSourceFiles are the files to generate of the request that each file of the
+	// response was generated for, by the path of the file. Only set for local plugins.
+	pathToSourceFiles map[string][]string
+}
+
+func newPluginSourceFiles(
+	requests []*pluginpb.CodeGeneratorRequest,
+	pathToSourceFiles map[string][]string,
+) *pluginSourceFiles {
+	requestSourceFiles := make([][]string, 0, len(requests))
+	for _, request := range requests {
+		if len(request.FileToGenerate) == 0 {
+			continue
+		}
+		requestSourceFiles = append(requestSourceFiles, request.FileToGenerate)
+	}
+	return &pluginSourceFiles{
+		requestSourceFiles: requestSourceFiles,
+		pathToSourceFiles:  pathToSourceFiles,
+	}
+}
+
+// newRemotePluginSourceFiles returns the pluginSourceFiles of a remote plugin, which
+// is sent all of the files of the image in a single request, regardless of the strategy.
+func newRemotePluginSourceFiles(
+	image bufimage.Image,
+	includeImports bool,
+	includeWellKnownTypes bool,
+) *pluginSourceFiles {
+	return newPluginSourceFiles(
+		[]*pluginpb.CodeGeneratorRequest{
+			bufimage.ImageToCodeGeneratorRequest(image, "", nil, includeImports, includeWellKnownTypes),
+		},
+		nil,
+	)
+}
+
+// writeGenerationManifests writes a generation manifest to each out directory.
+//
+// The responses must have already been written to the out directories, so that the
+// digests include the content inserted at insertion points by later plugins.
+//
+// The allPluginSourceFiles are the source files sent to the plugin at the same index.
+//
+// Plugins that write to a .jar or .zip archive are not included in the manifests.
+func (g *generator) writeGenerationManifests(
+	ctx context.Context,
+	config *Config,
+	responses []*pluginpb.CodeGeneratorResponse,
+	allPluginSourceFiles []*pluginSourceFiles,
+	baseOutDirPath string,
+	manifestFormat ManifestFormat,
+	inputRefs []string,
+) error {
+	// The out directories in the order in which they are first used.
+	var outs []string
+	outToGenerationManifest := make(map[string]*externalGenerationManifest)
+	outToPathToOutputFile := make(map[string]map[string]*externalGenerationManifestOutputFile)
+	digester, err := manifest.NewDigester(manifest.DigestTypeShake256)
+	if err != nil {
+		return err
+	}
+	for i, pluginConfig := range config.PluginConfigs {
+		out := pluginConfig.Out
+		if baseOutDirPath != "" && baseOutDirPath != "." {
+			out = filepath.Join(baseOutDirPath, out)
+		}
+		switch filepath.Ext(out) {
+		case ".jar", ".zip":
+			continue
+		}
+		out = filepath.Clean(out)
+		generationManifest, ok := outToGenerationManifest[out]
+		if !ok {
+			generationManifest = &externalGenerationManifest{
+				Inputs: inputRefs,
+			}
+			outToGenerationManifest[out] = generationManifest
+			outToPathToOutputFile[out] = make(map[string]*externalGenerationManifestOutputFile)
+			outs = append(outs, out)
+		}
+		sourceFiles := allPluginSourceFiles[i]
+		generationManifestPlugin, err := newExternalGenerationManifestPlugin(digester, pluginConfig)
+		if err != nil {
+			return fmt.Errorf("plugin %s: %v", pluginConfig.PluginName(), err)
+		}
+		for _, requestSourceFiles := range sourceFiles.requestSourceFiles {
+			generationManifestPlugin.Requests = append(
+				generationManifestPlugin.Requests,
+				&externalGenerationManifestRequest{
+					SourceFiles: requestSourceFiles,
+				},
+			)
+		}
+		generationManifest.Plugins = append(generationManifest.Plugins, generationManifestPlugin)
+		for _, file := range responses[i].GetFile() {
+			// Files with an insertion point modify a file generated by an earlier plugin.
+			if file.GetInsertionPoint() != "" {
+				continue
+			}
+			path := normalpath.Normalize(file.GetName())
+			fileSourceFiles := sourceFiles.pathToSourceFiles[path]
+			if fileSourceFiles == nil && len(sourceFiles.requestSourceFiles) == 1 {
+				fileSourceFiles = sourceFiles.requestSourceFiles[0]
+			}
+			outToPathToOutputFile[out][path] = &externalGenerationManifestOutputFile{
+				Path:        path,
+				Plugin:      generationManifestPlugin.Name,
+				SourceFiles: fileSourceFiles,
+			}
+		}
+	}
+	for _, out := range outs {
+		generationManifest := outToGenerationManifest[out]
+		pathToOutputFile := outToPathToOutputFile[out]
+		paths := make([]string, 0, len(pathToOutputFile))
+		for path := range pathToOutputFile {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		readWriteBucket, err := g.storageosProvider.NewReadWriteBucket(
+			out,
+			storageos.ReadWriteBucketWithSymlinksIfSupported(),
+		)
+		if err != nil {
+			return err
+		}
+		generationManifest.Files = make([]*externalGenerationManifestOutputFile, 0, len(paths))
+		for _, path := range paths {
+			digest, err := digestPath(ctx, readWriteBucket, digester, path)
+			if err != nil {
+				return err
+			}
+			outputFile := pathToOutputFile[path]
+			outputFile.Digest = digest.String()
+			generationManifest.Files = append(generationManifest.Files, outputFile)
+		}
+		data, err := marshalGenerationManifest(generationManifest, manifestFormat)
+		if err != nil {
+			return err
+		}
+		if err := storage.PutPath(ctx, readWriteBucket, manifestFormat.fileName(), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newExternalGenerationManifestPlugin returns the plugin for the PluginConfig, without
+// any requests.
+func newExternalGenerationManifestPlugin(
+	digester manifest.Digester,
+	pluginConfig *PluginConfig,
+) (*externalGenerationManifestPlugin, error) {
+	if pluginConfig.Plugin != "" && pluginConfig.IsRemote() {
+		if reference, err := bufpluginref.PluginReferenceForString(pluginConfig.Plugin, pluginConfig.Revision); err == nil {
+			return &externalGenerationManifestPlugin{
+				Name:     reference.IdentityString(),
+				Version:  reference.Version(),
+				Revision: reference.Revision(),
+			}, nil
+		}
+		return &externalGenerationManifestPlugin{
+			Name: pluginConfig.Plugin,
+		}, nil
+	}
+	if pluginConfig.Remote != "" {
+		if _, _, _, version, err := bufremoteplugin.ParsePluginVersionPath(pluginConfig.Remote); err == nil && version != "" {
+			return &externalGenerationManifestPlugin{
+				Name:    strings.TrimSuffix(pluginConfig.Remote, ":"+version),
+				Version: version,
+			}, nil
+		}
+		return &externalGenerationManifestPlugin{
+			Name: pluginConfig.PluginName(),
+		}, nil
+	}
+	generationManifestPlugin := &externalGenerationManifestPlugin{
+		Name: pluginConfig.PluginName(),
+	}
+	// As for the response cache, the binary of a plugin with a path with arguments
+	// is for example an interpreter, and not the plugin.
+	if isResponseCacheable(pluginConfig) {
+		pluginDigest, err := localPluginDigest(digester, pluginConfig)
+		if err != nil {
+			return nil, err
+		}
+		generationManifestPlugin.Digest = pluginDigest.String()
+	}
+	return generationManifestPlugin, nil
+}
+
+func digestPath(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	digester manifest.Digester,
+	path string,
+) (_ *manifest.Digest, retErr error) {
+	readObjectCloser, err := readBucket.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, readObjectCloser.Close())
+	}()
+	return digester.Digest(readObjectCloser)
+}
+
+func marshalGenerationManifest(
+	generationManifest *externalGenerationManifest,
+	manifestFormat ManifestFormat,
+) ([]byte, error) {
+	switch manifestFormat {
+	case ManifestFormatJSON:
+		data, err := json.MarshalIndent(generationManifest, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case ManifestFormatYAML:
+		return encoding.MarshalYAML(generationManifest)
+	default:
+		return nil, fmt.Errorf("unknown manifest format: %v", manifestFormat)
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/encoding"
+	"github.com/bufbuild/buf/private/pkg/manifest"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestWriteGenerationManifests(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	baseOutDirPath := t.TempDir()
+	image := testNewImage(t, "a/a.proto", "a/b.proto", "b/c.proto")
+	pluginPath := filepath.Join(t.TempDir(), "protoc-gen-test")
+	require.NoError(t, os.WriteFile(pluginPath, []byte("v1"), 0700))
+	config := &Config{
+		PluginConfigs: []*PluginConfig{
+			{
+				Name:     "test",
+				Out:      "gen",
+				Path:     []string{pluginPath},
+				Strategy: StrategyDirectory,
+			},
+			{
+				Plugin:   "buf.build/protocolbuffers/go:v1.28.1",
+				Revision: 2,
+				Out:      "gen/",
+				Strategy: StrategyDirectory,
+			},
+			{
+				Name:     "archive",
+				Out:      "gen.zip",
+				Strategy: StrategyAll,
+			},
+		},
+	}
+	responses := []*pluginpb.CodeGeneratorResponse{
+		{
+			File: []*pluginpb.CodeGeneratorResponse_File{
+				{
+					Name:    proto.String("a/a.txt"),
+					Content: proto.String("a"),
+				},
+				{
+					Name:    proto.String("b/c.txt"),
+					Content: proto.String("c"),
+				},
+			},
+		},
+		{
+			File: []*pluginpb.CodeGeneratorResponse_File{
+				{
+					Name:    proto.String("a/a.pb.go"),
+					Content: proto.String("package a"),
+				},
+				{
+					Name:           proto.String("a/a.txt"),
+					InsertionPoint: proto.String("foo"),
+					Content:        proto.String("inserted"),
+				},
+			},
+		},
+		{
+			File: []*pluginpb.CodeGeneratorResponse_File{
+				{
+					Name:    proto.String("archived.txt"),
+					Content: proto.String("archived"),
+				},
+			},
+		},
+	}
+	// The local plugin is sent a request per directory, and the remote plugin a single
+	// request with all of the files.
+	allPluginSourceFiles := []*pluginSourceFiles{
+		{
+			requestSourceFiles: [][]string{
+				{"a/a.proto", "a/b.proto"},
+				{"b/c.proto"},
+			},
+			pathToSourceFiles: map[string][]string{
+				"a/a.txt": {"a/a.proto", "a/b.proto"},
+				"b/c.txt": {"b/c.proto"},
+			},
+		},
+		newRemotePluginSourceFiles(image, false, false),
+		{
+			requestSourceFiles: [][]string{
+				{"a/a.proto", "a/b.proto", "b/c.proto"},
+			},
+		},
+	}
+	// The files as written by the response writer, including the inserted content.
+	writtenFiles := map[string]string{
+		"a/a.txt":   "ainserted",
+		"b/c.txt":   "c",
+		"a/a.pb.go": "package a",
+	}
+	for path, content := range writtenFiles {
+		filePath := filepath.Join(baseOutDirPath, "gen", filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0600))
+	}
+	generator := newGenerator(zap.NewNop(), storageos.NewProvider(), command.NewRunner(), nil)
+	require.NoError(
+		t,
+		generator.writeGenerationManifests(
+			ctx,
+			config,
+			responses,
+			allPluginSourceFiles,
+			baseOutDirPath,
+			ManifestFormatJSON,
+			[]string{"proto", "buf.build/acme/weather"},
+		),
+	)
+	data, err := os.ReadFile(filepath.Join(baseOutDirPath, "gen", "buf.gen.outputs.json"))
+	require.NoError(t, err)
+	generationManifest := &externalGenerationManifest{}
+	require.NoError(t, json.Unmarshal(data, generationManifest))
+	expectedGenerationManifest := &externalGenerationManifest{
+		Inputs: []string{"proto", "buf.build/acme/weather"},
+		Plugins: []*externalGenerationManifestPlugin{
+			{
+				Name:   "test",
+				Digest: testDigestString(t, "v1"),
+				Requests: []*externalGenerationManifestRequest{
+					{
+						SourceFiles: []string{"a/a.proto", "a/b.proto"},
+					},
+					{
+						SourceFiles: []string{"b/c.proto"},
+					},
+				},
+			},
+			{
+				Name:     "buf.build/protocolbuffers/go",
+				Version:  "v1.28.1",
+				Revision: 2,
+				Requests: []*externalGenerationManifestRequest{
+					{
+						SourceFiles: []string{"a/a.proto", "a/b.proto", "b/c.proto"},
+					},
+				},
+			},
+		},
+		Files: []*externalGenerationManifestOutputFile{
+			{
+				Path:        "a/a.pb.go",
+				Digest:      testDigestString(t, "package a"),
+				Plugin:      "buf.build/protocolbuffers/go",
+				SourceFiles: []string{"a/a.proto", "a/b.proto", "b/c.proto"},
+			},
+			{
+				Path:        "a/a.txt",
+				Digest:      testDigestString(t, "ainserted"),
+				Plugin:      "test",
+				SourceFiles: []string{"a/a.proto", "a/b.proto"},
+			},
+			{
+				Path:        "b/c.txt",
+				Digest:      testDigestString(t, "c"),
+				Plugin:      "test",
+				SourceFiles: []string{"b/c.proto"},
+			},
+		},
+	}
+	assert.Equal(t, expectedGenerationManifest, generationManifest)
+	_, err = os.Stat(filepath.Join(baseOutDirPath, "gen.zip"))
+	assert.True(t, os.IsNotExist(err))
+
+	require.NoError(
+		t,
+		generator.writeGenerationManifests(
+			ctx,
+			config,
+			responses,
+			allPluginSourceFiles,
+			baseOutDirPath,
+			ManifestFormatYAML,
+			[]string{"proto", "buf.build/acme/weather"},
+		),
+	)
+	data, err = os.ReadFile(filepath.Join(baseOutDirPath, "gen", "buf.gen.outputs.yaml"))
+	require.NoError(t, err)
+	generationManifest = &externalGenerationManifest{}
+	require.NoError(t, encoding.UnmarshalYAMLStrict(data, generationManifest))
+	assert.Equal(t, expectedGenerationManifest, generationManifest)
+}
+
+func TestParseManifestFormat(t *testing.T) {
+	t.Parallel()
+	manifestFormat, err := ParseManifestFormat("json")
+	require.NoError(t, err)
+	assert.Equal(t, ManifestFormatJSON, manifestFormat)
+	manifestFormat, err = ParseManifestFormat("yaml")
+	require.NoError(t, err)
+	assert.Equal(t, ManifestFormatYAML, manifestFormat)
+	_, err = ParseManifestFormat("")
+	assert.Error(t, err)
+	_, err = ParseManifestFormat("xml")
+	assert.Error(t, err)
+}
+
+func testNewImage(t *testing.T, paths ...string) bufimage.Image {
+	imageFiles := make([]bufimage.ImageFile, 0, len(paths))
+	for _, path := range paths {
+		imageFile, err := bufimage.NewImageFile(
+			&descriptorpb.FileDescriptorProto{
+				Name:   proto.String(path),
+				Syntax: proto.String("proto3"),
+			},
+			nil,
+			"",
+			"",
+			false,
+			false,
+			nil,
+		)
+		require.NoError(t, err)
+		imageFiles = append(imageFiles, imageFile)
+	}
+	image, err := bufimage.NewImage(imageFiles)
+	require.NoError(t, err)
+	return image
+}
+
+func testDigestString(t *testing.T, content string) string {
+	digester, err := manifest.NewDigester(manifest.DigestTypeShake256)
+	require.NoError(t, err)
+	digest, err := digester.Digest(bytes.NewReader([]byte(content)))
+	require.NoError(t, err)
+	return digest.String()
+}
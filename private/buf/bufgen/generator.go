@@ -19,10 +19,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
+	"sync"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagemodify"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin/bufpluginref"
@@ -35,8 +38,10 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app/appproto/appprotoos"
 	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/connectclient"
+	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/bufbuild/buf/private/pkg/thread"
+	"github.com/bufbuild/buf/private/pkg/verbose"
 	connect "github.com/bufbuild/connect-go"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
@@ -46,8 +51,12 @@ import (
 type generator struct {
 	logger                *zap.Logger
 	storageosProvider     storageos.Provider
+	runner                command.Runner
 	appprotoexecGenerator bufpluginexec.Generator
 	clientConfig          *connectclient.Config
+	cacheReadWriteBucket  storage.ReadWriteBucket
+	verbosePrinter        verbose.Printer
+	maxParallelPlugins    int
 }
 
 func newGenerator(
@@ -55,13 +64,25 @@ func newGenerator(
 	storageosProvider storageos.Provider,
 	runner command.Runner,
 	clientConfig *connectclient.Config,
+	options ...GeneratorOption,
 ) *generator {
-	return &generator{
-		logger:                logger,
-		storageosProvider:     storageosProvider,
-		appprotoexecGenerator: bufpluginexec.NewGenerator(logger, storageosProvider, runner),
-		clientConfig:          clientConfig,
+	generator := &generator{
+		logger:            logger,
+		storageosProvider: storageosProvider,
+		runner:            runner,
+		clientConfig:      clientConfig,
+		verbosePrinter:    verbose.NopPrinter,
 	}
+	for _, option := range options {
+		option(generator)
+	}
+	generator.appprotoexecGenerator = bufpluginexec.NewGenerator(
+		logger,
+		storageosProvider,
+		runner,
+		bufpluginexec.GeneratorWithMaxParallelInvocations(generator.maxParallelPlugins),
+	)
+	return generator
 }
 
 // Generate executes all of the plugins specified by the given Config, and
@@ -100,6 +121,10 @@ func (g *generator) Generate(
 		generateOptions.baseOutDirPath,
 		generateOptions.includeImports,
 		generateOptions.includeWellKnownTypes,
+		generateOptions.clean,
+		generateOptions.checkWriter,
+		generateOptions.manifestFormat,
+		generateOptions.manifestInputRefs,
 	)
 }
 
@@ -111,21 +136,34 @@ func (g *generator) generate(
 	baseOutDirPath string,
 	includeImports bool,
 	includeWellKnownTypes bool,
+	clean bool,
+	checkWriter io.Writer,
+	manifestFormat ManifestFormat,
+	manifestInputRefs []string,
 ) error {
 	if err := modifyImage(ctx, g.logger, config, image); err != nil {
 		return err
 	}
-	responses, err := g.execPlugins(
+	var responseCache *responseCache
+	if g.cacheReadWriteBucket != nil {
+		responseCache = newResponseCache(g.logger, g.cacheReadWriteBucket)
+	}
+	responses, allPluginSourceFiles, err := g.execPlugins(
 		ctx,
 		container,
 		config,
 		image,
+		responseCache,
 		includeImports,
 		includeWellKnownTypes,
 	)
 	if err != nil {
 		return err
 	}
+	if responseCache != nil {
+		hits, misses := responseCache.Stats()
+		g.verbosePrinter.Printf("generate cache: %d hits, %d misses", hits, misses)
+	}
 	// Apply the CodeGeneratorResponses in the order they were specified.
 	responseWriter := appprotoos.NewResponseWriter(
 		g.logger,
@@ -141,17 +179,43 @@ func (g *generator) generate(
 		if response == nil {
 			return fmt.Errorf("failed to get plugin response for %s", pluginConfig.PluginName())
 		}
+		var addResponseOptions []appprotoos.AddResponseOption
+		if clean || pluginConfig.Clean {
+			addResponseOptions = append(addResponseOptions, appprotoos.AddResponseWithClean())
+		}
 		if err := responseWriter.AddResponse(
 			ctx,
 			response,
 			out,
+			addResponseOptions...,
 		); err != nil {
 			return fmt.Errorf("plugin %s: %v", pluginConfig.PluginName(), err)
 		}
 	}
+	if checkWriter != nil {
+		diffPresent, err := responseWriter.Diff(ctx, g.runner, checkWriter)
+		if err != nil {
+			return err
+		}
+		if diffPresent {
+			return ErrCheckFailed
+		}
+		return nil
+	}
 	if err := responseWriter.Close(); err != nil {
 		return err
 	}
+	if manifestFormat != 0 {
+		return g.writeGenerationManifests(
+			ctx,
+			config,
+			responses,
+			allPluginSourceFiles,
+			baseOutDirPath,
+			manifestFormat,
+			manifestInputRefs,
+		)
+	}
 	return nil
 }
 
@@ -160,20 +224,23 @@ func (g *generator) execPlugins(
 	container app.EnvStdioContainer,
 	config *Config,
 	image bufimage.Image,
+	responseCache *responseCache,
 	includeImports bool,
 	includeWellKnownTypes bool,
-) ([]*pluginpb.CodeGeneratorResponse, error) {
+) ([]*pluginpb.CodeGeneratorResponse, []*pluginSourceFiles, error) {
 	imageProvider := newImageProvider(image)
 	// Collect all of the plugin jobs so that they can be executed in parallel.
 	jobs := make([]func(context.Context) error, 0, len(config.PluginConfigs))
 	responses := make([]*pluginpb.CodeGeneratorResponse, len(config.PluginConfigs))
+	allPluginSourceFiles := make([]*pluginSourceFiles, len(config.PluginConfigs))
 	requiredFeatures := computeRequiredFeatures(image)
 	remotePluginConfigTable := make(map[string][]*remotePluginExecArgs, len(config.PluginConfigs))
 	for i, pluginConfig := range config.PluginConfigs {
 		index := i
 		currentPluginConfig := pluginConfig
 		remote := currentPluginConfig.GetRemoteHostname()
-		if remote != "" {
+		if !currentPluginConfig.hasImageFilter() && remote != "" {
+			allPluginSourceFiles[index] = newRemotePluginSourceFiles(image, includeImports, includeWellKnownTypes)
 			remotePluginConfigTable[remote] = append(
 				remotePluginConfigTable[remote],
 				&remotePluginExecArgs{
@@ -181,13 +248,68 @@ func (g *generator) execPlugins(
 					PluginConfig: currentPluginConfig,
 				},
 			)
+			continue
+		}
+		currentImageProvider := imageProvider
+		currentImage := image
+		if currentPluginConfig.hasImageFilter() {
+			// Plugins that only generate for a subset of the image cannot share the image
+			// with other plugins, so remote plugins are not batched with other plugins either.
+			filteredImage, err := filterImage(image, currentPluginConfig)
+			if err != nil {
+				return nil, nil, fmt.Errorf("plugin %s: %v", currentPluginConfig.PluginName(), err)
+			}
+			currentImageProvider = newImageProvider(filteredImage)
+			currentImage = filteredImage
+		}
+		if remote != "" {
+			allPluginSourceFiles[index] = newRemotePluginSourceFiles(currentImage, includeImports, includeWellKnownTypes)
+			remotePluginExecArgs := []*remotePluginExecArgs{
+				{
+					Index:        index,
+					PluginConfig: currentPluginConfig,
+				},
+			}
+			jobs = append(jobs, func(ctx context.Context) error {
+				var results []*remotePluginExecutionResult
+				var err error
+				if currentPluginConfig.Plugin == "" {
+					results, err = g.executeRemotePlugins(
+						ctx,
+						container,
+						currentImage,
+						remote,
+						remotePluginExecArgs,
+						includeImports,
+						includeWellKnownTypes,
+					)
+				} else {
+					results, err = g.execRemotePluginsV2(
+						ctx,
+						container,
+						currentImage,
+						remote,
+						remotePluginExecArgs,
+						includeImports,
+						includeWellKnownTypes,
+					)
+				}
+				if err != nil {
+					return err
+				}
+				for _, result := range results {
+					responses[result.Index] = result.CodeGeneratorResponse
+				}
+				return nil
+			})
 		} else {
 			jobs = append(jobs, func(ctx context.Context) error {
-				response, err := g.execLocalPlugin(
+				response, pluginSourceFiles, err := g.execLocalPlugin(
 					ctx,
 					container,
-					imageProvider,
+					currentImageProvider,
 					currentPluginConfig,
+					responseCache,
 					includeImports,
 					includeWellKnownTypes,
 				)
@@ -195,6 +317,7 @@ func (g *generator) execPlugins(
 					return err
 				}
 				responses[index] = response
+				allPluginSourceFiles[index] = pluginSourceFiles
 				return nil
 			})
 		}
@@ -273,47 +396,137 @@ func (g *generator) execPlugins(
 		thread.ParallelizeWithCancel(cancel),
 	); err != nil {
 		if errs := multierr.Errors(err); len(errs) > 0 {
-			return nil, errs[0]
+			return nil, nil, errs[0]
 		}
-		return nil, err
+		return nil, nil, err
 	}
 	if err := validateResponses(responses, config.PluginConfigs); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	checkRequiredFeatures(container, requiredFeatures, responses, config.PluginConfigs)
-	return responses, nil
+	return responses, allPluginSourceFiles, nil
 }
 
+// execLocalPlugin returns the response of the local plugin, and the source files of
+// the requests sent to the plugin.
 func (g *generator) execLocalPlugin(
 	ctx context.Context,
 	container app.EnvStdioContainer,
 	imageProvider *imageProvider,
 	pluginConfig *PluginConfig,
+	responseCache *responseCache,
 	includeImports bool,
 	includeWellKnownTypes bool,
-) (*pluginpb.CodeGeneratorResponse, error) {
+) (*pluginpb.CodeGeneratorResponse, *pluginSourceFiles, error) {
 	pluginImages, err := imageProvider.GetImages(pluginConfig.Strategy)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	response, err := g.appprotoexecGenerator.Generate(
-		ctx,
-		container,
-		pluginConfig.PluginName(),
-		bufimage.ImagesToCodeGeneratorRequests(
-			pluginImages,
-			pluginConfig.Opt,
-			nil,
-			includeImports,
-			includeWellKnownTypes,
-		),
-		bufpluginexec.GenerateWithPluginPath(pluginConfig.Path...),
-		bufpluginexec.GenerateWithProtocPath(pluginConfig.ProtocPath),
+	requests := bufimage.ImagesToCodeGeneratorRequests(
+		pluginImages,
+		pluginConfig.Opt,
+		nil,
+		includeImports,
+		includeWellKnownTypes,
 	)
-	if err != nil {
-		return nil, fmt.Errorf("plugin %s: %v", pluginConfig.PluginName(), err)
+	if !isResponseCacheable(pluginConfig) {
+		responseCache = nil
+	}
+	// The responses to the requests, in the same order as the requests.
+	requestResponses := make([]*pluginpb.CodeGeneratorResponse, len(requests))
+	var cacheKeys []string
+	if responseCache != nil {
+		cacheKeys, err = responseCache.Keys(pluginConfig, requests)
+		if err != nil {
+			return nil, nil, fmt.Errorf("plugin %s: %v", pluginConfig.PluginName(), err)
+		}
+		for i, cacheKey := range cacheKeys {
+			response, ok, err := responseCache.Get(ctx, cacheKey)
+			if err != nil {
+				return nil, nil, err
+			}
+			if ok {
+				requestResponses[i] = response
+			}
+		}
+	}
+	var uncachedRequests []*pluginpb.CodeGeneratorRequest
+	requestToIndex := make(map[*pluginpb.CodeGeneratorRequest]int)
+	for i, request := range requests {
+		if requestResponses[i] == nil {
+			uncachedRequests = append(uncachedRequests, request)
+			requestToIndex[request] = i
+			requestResponses[i] = &pluginpb.CodeGeneratorResponse{}
+		}
+	}
+	if len(uncachedRequests) > 0 {
+		var lock sync.Mutex
+		response, err := g.appprotoexecGenerator.Generate(
+			ctx,
+			container,
+			pluginConfig.PluginName(),
+			uncachedRequests,
+			bufpluginexec.GenerateWithPluginPath(pluginConfig.Path...),
+			bufpluginexec.GenerateWithProtocPath(pluginConfig.ProtocPath),
+			bufpluginexec.GenerateWithTimeout(pluginConfig.Timeout),
+			bufpluginexec.GenerateWithAddFileFunc(
+				func(request *pluginpb.CodeGeneratorRequest, file *pluginpb.CodeGeneratorResponse_File) {
+					lock.Lock()
+					defer lock.Unlock()
+					requestResponse := requestResponses[requestToIndex[request]]
+					requestResponse.File = append(requestResponse.File, file)
+				},
+			),
+		)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				// The error names the files of the invocation that timed out, which are
+				// determined by the strategy.
+				return nil, nil, fmt.Errorf("plugin %s with strategy %s: %v", pluginConfig.PluginName(), pluginConfig.Strategy.String(), err)
+			}
+			return nil, nil, fmt.Errorf("plugin %s: %v", pluginConfig.PluginName(), err)
+		}
+		for _, request := range uncachedRequests {
+			index := requestToIndex[request]
+			requestResponses[index].SupportedFeatures = response.SupportedFeatures
+			if responseCache != nil {
+				if err := responseCache.Put(ctx, cacheKeys[index], requestResponses[index]); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+	response, pathToSourceFiles := mergeRequestResponses(requests, requestResponses)
+	return response, newPluginSourceFiles(requests, pathToSourceFiles), nil
+}
+
+// mergeRequestResponses merges the responses to the requests of a plugin into a single
+// response, and returns the files to generate of the request that each file of the
+// response was generated for, by the path of the file.
+//
+// As with the responses of appproto, a file with the same name as an earlier file is
+// dropped, unless it is for an insertion point.
+func mergeRequestResponses(
+	requests []*pluginpb.CodeGeneratorRequest,
+	requestResponses []*pluginpb.CodeGeneratorResponse,
+) (*pluginpb.CodeGeneratorResponse, map[string][]string) {
+	response := &pluginpb.CodeGeneratorResponse{}
+	pathToSourceFiles := make(map[string][]string)
+	for i, requestResponse := range requestResponses {
+		for _, file := range requestResponse.GetFile() {
+			if file.GetInsertionPoint() == "" {
+				if _, ok := pathToSourceFiles[file.GetName()]; ok {
+					continue
+				}
+				pathToSourceFiles[file.GetName()] = requests[i].GetFileToGenerate()
+			}
+			response.File = append(response.File, file)
+		}
+		if requestResponse.SupportedFeatures != nil {
+			response.SupportedFeatures = requestResponse.SupportedFeatures
+		}
 	}
-	return response, nil
+	return response, pathToSourceFiles
 }
 
 type remotePluginExecArgs struct {
@@ -487,6 +700,33 @@ func getPluginReference(pluginConfig *PluginConfig) (*registryv1alpha1.PluginRef
 	}, nil
 }
 
+// filterImage returns a copy of the image that only includes the files and types selected
+// by the include paths, exclude paths and types of the PluginConfig.
+//
+// Files that are not selected are kept as imports if they are required by the selected files.
+func filterImage(image bufimage.Image, pluginConfig *PluginConfig) (bufimage.Image, error) {
+	var err error
+	if len(pluginConfig.IncludePaths) > 0 || len(pluginConfig.ExcludePaths) > 0 {
+		// The image may already be restricted with --path or --exclude-path, in which case
+		// the paths of the plugin may not exist in the image.
+		image, err = bufimage.ImageWithOnlyPathsAllowNotExist(
+			image,
+			pluginConfig.IncludePaths,
+			pluginConfig.ExcludePaths,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(pluginConfig.Types) > 0 {
+		image, err = bufimageutil.ImageFilteredByTypes(image, pluginConfig.Types...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return image, nil
+}
+
 // modifyImage modifies the image according to the given configuration (i.e. managed mode).
 func modifyImage(
 	ctx context.Context,
@@ -666,6 +906,29 @@ func newModifier(
 		modifier,
 		rubyPackageModifier,
 	)
+	if len(managedConfig.FileOptionRules) > 0 {
+		// The rules are applied last, so that they take precedence over all other options.
+		modifier, err = bufimagemodify.FileOptionRules(
+			logger,
+			sweeper,
+			modifier,
+			managedConfig.FileOptionRules,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct managed mode rules: %w", err)
+		}
+	}
+	if len(managedConfig.FieldOptionRules) > 0 {
+		fieldOptionRulesModifier, err := bufimagemodify.FieldOptionRules(
+			logger,
+			sweeper,
+			managedConfig.FieldOptionRules,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct managed mode field rules: %w", err)
+		}
+		modifier = bufimagemodify.Merge(modifier, fieldOptionRulesModifier)
+	}
 	return modifier, nil
 }
 
@@ -704,6 +967,11 @@ type generateOptions struct {
 	baseOutDirPath        string
 	includeImports        bool
 	includeWellKnownTypes bool
+	clean                 bool
+	checkWriter           io.Writer
+	// Not written if 0.
+	manifestFormat    ManifestFormat
+	manifestInputRefs []string
 }
 
 func newGenerateOptions() *generateOptions {
@@ -0,0 +1,81 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package bufgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestMergeRequestResponses(t *testing.T) {
+	t.Parallel()
+	requests := []*pluginpb.CodeGeneratorRequest{
+		{
+			FileToGenerate: []string{"a/a.proto"},
+		},
+		{
+			FileToGenerate: []string{"b/b.proto"},
+		},
+	}
+	aFile := &pluginpb.CodeGeneratorResponse_File{
+		Name:    proto.String("a.txt"),
+		Content: proto.String("a"),
+	}
+	insertionPointFile := &pluginpb.CodeGeneratorResponse_File{
+		Name:           proto.String("a.txt"),
+		InsertionPoint: proto.String("foo"),
+		Content:        proto.String("b"),
+	}
+	bFile := &pluginpb.CodeGeneratorResponse_File{
+		Name:    proto.String("b.txt"),
+		Content: proto.String("b"),
+	}
+	response, pathToSourceFiles := mergeRequestResponses(
+		requests,
+		[]*pluginpb.CodeGeneratorResponse{
+			{
+				File: []*pluginpb.CodeGeneratorResponse_File{aFile},
+			},
+			{
+				File: []*pluginpb.CodeGeneratorResponse_File{
+					insertionPointFile,
+					bFile,
+					// A duplicate of the file generated for the first request.
+					{
+						Name:    proto.String("a.txt"),
+						Content: proto.String("duplicate"),
+					},
+				},
+				SupportedFeatures: proto.Uint64(uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)),
+			},
+		},
+	)
+	assert.Equal(
+		t,
+		[]*pluginpb.CodeGeneratorResponse_File{aFile, insertionPointFile, bFile},
+		response.File,
+	)
+	assert.Equal(t, uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL), response.GetSupportedFeatures())
+	assert.Equal(
+		t,
+		map[string][]string{
+			"a.txt": {"a/a.proto"},
+			"b.txt": {"b/b.proto"},
+		},
+		pathToSourceFiles,
+	)
+}
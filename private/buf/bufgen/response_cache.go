@@ -0,0 +1,203 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufpluginexec"
+	"github.com/bufbuild/buf/private/pkg/manifest"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// responseCacheKeyVersion is written into every cache key, so that changing how keys
+// are computed or how responses are stored invalidates all existing entries.
+const responseCacheKeyVersion = "2"
+
+// responseCache caches the CodeGeneratorResponses of local plugins.
+//
+// Responses are stored per CodeGeneratorRequest, by a key that is the digest of the
+// CodeGeneratorRequest, the digest of the plugin binary, and the options used to invoke
+// the plugin, so that entries never have to be invalidated. Responses of remote plugins,
+// and of local plugins that are not cacheable per isResponseCacheable, are not cached.
+type responseCache struct {
+	logger          *zap.Logger
+	readWriteBucket storage.ReadWriteBucket
+
+	lock   sync.Mutex
+	hits   int
+	misses int
+}
+
+func newResponseCache(logger *zap.Logger, readWriteBucket storage.ReadWriteBucket) *responseCache {
+	return &responseCache{
+		logger:          logger,
+		readWriteBucket: readWriteBucket,
+	}
+}
+
+// Keys returns the cache key for invoking the local plugin with each of the requests.
+//
+// The plugin must be cacheable per isResponseCacheable.
+func (c *responseCache) Keys(
+	pluginConfig *PluginConfig,
+	requests []*pluginpb.CodeGeneratorRequest,
+) ([]string, error) {
+	digester, err := manifest.NewDigester(manifest.DigestTypeShake256)
+	if err != nil {
+		return nil, err
+	}
+	pluginDigest, err := localPluginDigest(digester, pluginConfig)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(requests))
+	for i, request := range requests {
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(request)
+		if err != nil {
+			return nil, err
+		}
+		requestDigest, err := digester.Digest(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		var keyData bytes.Buffer
+		// The path of the plugin binary is deliberately not part of the key, only its content.
+		fmt.Fprintf(&keyData, "version %s\n", responseCacheKeyVersion)
+		fmt.Fprintf(&keyData, "plugin %q\n", pluginConfig.PluginName())
+		fmt.Fprintf(&keyData, "plugin_digest %s\n", pluginDigest.String())
+		fmt.Fprintf(&keyData, "opt %q\n", pluginConfig.Opt)
+		fmt.Fprintf(&keyData, "request %s\n", requestDigest.String())
+		keyDigest, err := digester.Digest(&keyData)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = keyDigest.Hex()
+	}
+	return keys, nil
+}
+
+// Get gets the cached response for the key.
+//
+// Returns false if there is no valid cached response.
+func (c *responseCache) Get(ctx context.Context, key string) (*pluginpb.CodeGeneratorResponse, bool, error) {
+	data, err := storage.ReadPath(ctx, c.readWriteBucket, keyPath(key))
+	if err != nil {
+		if storage.IsNotExist(err) {
+			c.markMiss(key)
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	response := &pluginpb.CodeGeneratorResponse{}
+	if err := proto.Unmarshal(data, response); err != nil {
+		// The entry is overwritten when the response is put again.
+		c.logger.Debug("generate_cache_invalid_entry", zap.String("key", key), zap.Error(err))
+		c.markMiss(key)
+		return nil, false, nil
+	}
+	c.logger.Debug("generate_cache_hit", zap.String("key", key))
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.hits++
+	return response, true, nil
+}
+
+// Put caches the response for the key.
+//
+// Responses with an error are not cached.
+func (c *responseCache) Put(ctx context.Context, key string, response *pluginpb.CodeGeneratorResponse) (retErr error) {
+	if response.GetError() != "" {
+		return nil
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(response)
+	if err != nil {
+		return err
+	}
+	writeObjectCloser, err := c.readWriteBucket.Put(ctx, keyPath(key), storage.PutWithAtomic())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, writeObjectCloser.Close())
+	}()
+	_, err = writeObjectCloser.Write(data)
+	return err
+}
+
+// Stats returns the number of hits and misses.
+func (c *responseCache) Stats() (int, int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.hits, c.misses
+}
+
+func (c *responseCache) markMiss(key string) {
+	c.logger.Debug("generate_cache_miss", zap.String("key", key))
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.misses++
+}
+
+// isResponseCacheable returns true if the responses of the local plugin can be cached.
+//
+// The key only contains the digest of the plugin binary. If the path of the plugin has
+// arguments, such as [sh, gen.sh] or [go, run, ./cmd/protoc-gen-foo], the binary is an
+// interpreter or a toolchain that runs code that is not part of the key, so the cached
+// responses could be stale.
+func isResponseCacheable(pluginConfig *PluginConfig) bool {
+	return len(pluginConfig.Path) <= 1
+}
+
+// localPluginDigest returns the digest of the binary of the local plugin, as found with
+// bufpluginexec.LookPath.
+func localPluginDigest(digester manifest.Digester, pluginConfig *PluginConfig) (*manifest.Digest, error) {
+	pluginPath, err := bufpluginexec.LookPath(
+		pluginConfig.PluginName(),
+		bufpluginexec.HandlerWithPluginPath(pluginConfig.Path...),
+		bufpluginexec.HandlerWithProtocPath(pluginConfig.ProtocPath),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return digestFile(digester, pluginPath)
+}
+
+// keyPath returns the path of the entry for the key.
+//
+// Entries are sharded by the first two characters of the key to keep directories small.
+func keyPath(key string) string {
+	return normalpath.Join(key[:2], key[2:])
+}
+
+func digestFile(digester manifest.Digester, path string) (_ *manifest.Digest, retErr error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, file.Close())
+	}()
+	return digester.Digest(file)
+}
@@ -0,0 +1,123 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestResponseCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	pluginPath := filepath.Join(t.TempDir(), "protoc-gen-test")
+	require.NoError(t, os.WriteFile(pluginPath, []byte("v1"), 0700))
+	pluginConfig := &PluginConfig{
+		Name:     "test",
+		Out:      "gen",
+		Path:     []string{pluginPath},
+		Strategy: StrategyDirectory,
+	}
+	requests := []*pluginpb.CodeGeneratorRequest{
+		{
+			FileToGenerate: []string{"a.proto"},
+		},
+	}
+	responseCache := newResponseCache(zap.NewNop(), storagemem.NewReadWriteBucket())
+
+	keys, err := responseCache.Keys(pluginConfig, requests)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	key := keys[0]
+	_, ok, err := responseCache.Get(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	response := &pluginpb.CodeGeneratorResponse{
+		File: []*pluginpb.CodeGeneratorResponse_File{
+			{
+				Name:    proto.String("a.txt"),
+				Content: proto.String("a"),
+			},
+		},
+	}
+	require.NoError(t, responseCache.Put(ctx, key, response))
+	cachedResponse, ok, err := responseCache.Get(ctx, key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, proto.Equal(response, cachedResponse))
+	hits, misses := responseCache.Stats()
+	assert.Equal(t, 1, hits)
+	assert.Equal(t, 1, misses)
+
+	sameKeys, err := responseCache.Keys(pluginConfig, requests)
+	require.NoError(t, err)
+	assert.Equal(t, keys, sameKeys)
+	optKeys, err := responseCache.Keys(
+		&PluginConfig{
+			Name:     "test",
+			Out:      "gen",
+			Opt:      "foo=bar",
+			Path:     []string{pluginPath},
+			Strategy: StrategyDirectory,
+		},
+		requests,
+	)
+	require.NoError(t, err)
+	assert.NotEqual(t, keys, optKeys)
+	requestKeys, err := responseCache.Keys(
+		pluginConfig,
+		[]*pluginpb.CodeGeneratorRequest{
+			requests[0],
+			{
+				FileToGenerate: []string{"b.proto"},
+			},
+		},
+	)
+	require.NoError(t, err)
+	// The key of each request only depends on that request.
+	assert.Equal(t, []string{key, requestKeys[1]}, requestKeys)
+	assert.NotEqual(t, key, requestKeys[1])
+	require.NoError(t, os.WriteFile(pluginPath, []byte("v2"), 0700))
+	pluginKeys, err := responseCache.Keys(pluginConfig, requests)
+	require.NoError(t, err)
+	assert.NotEqual(t, keys, pluginKeys)
+}
+
+func TestIsResponseCacheable(t *testing.T) {
+	t.Parallel()
+	assert.True(t, isResponseCacheable(&PluginConfig{Name: "test"}))
+	assert.True(t, isResponseCacheable(&PluginConfig{Name: "test", Path: []string{"protoc-gen-test"}}))
+	assert.False(t, isResponseCacheable(&PluginConfig{Name: "test", Path: []string{"sh", "gen.sh"}}))
+	assert.False(t, isResponseCacheable(&PluginConfig{Name: "test", Path: []string{"go", "run", "./cmd/protoc-gen-test"}}))
+}
+
+func TestResponseCacheSkipsErrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	responseCache := newResponseCache(zap.NewNop(), storagemem.NewReadWriteBucket())
+	require.NoError(t, responseCache.Put(ctx, "abcdef", &pluginpb.CodeGeneratorResponse{Error: proto.String("failed")}))
+	_, ok, err := responseCache.Get(ctx, "abcdef")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
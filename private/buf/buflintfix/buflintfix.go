@@ -0,0 +1,209 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package buflintfix applies machine-applicable fixes for lint violations.
+package buflintfix
+
+import (
+	"context"
+	"sort"
+
+	"github.com/bufbuild/buf/private/buf/bufformat"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/bufbuild/protocompile/ast"
+	"github.com/bufbuild/protocompile/parser"
+	"github.com/bufbuild/protocompile/reporter"
+	"go.uber.org/multierr"
+)
+
+const (
+	// These match the defaults in bufcheck. We cannot take an explicit dependency
+	// on the internal package that defines them.
+	defaultEnumZeroValueSuffix = "_UNSPECIFIED"
+	defaultServiceSuffix       = "Service"
+)
+
+// FixableIDs are the IDs of the lint rules that have machine-applicable fixes.
+var FixableIDs = []string{
+	"ENUM_VALUE_PREFIX",
+	"ENUM_ZERO_VALUE_SUFFIX",
+	"FIELD_LOWER_SNAKE_CASE",
+	"IMPORT_USED",
+	"SERVICE_SUFFIX",
+}
+
+// Fix applies the machine-applicable fixes for the given lint FileAnnotations to the
+// files in the readBucket.
+//
+// The FileAnnotations must have been produced by linting the files in the readBucket
+// with the given config. The returned read bucket only contains the files that were
+// changed, with the same external paths as the files in the readBucket. Changed files
+// are printed with the same formatter as buf format. The FileAnnotations that could
+// not be fixed are also returned.
+//
+// Fields and enum values are not renamed if their names are used in the options of
+// any file in the readBucket, such as in [default = VALUE] or in message literals,
+// as the references would no longer resolve. The readBucket should therefore contain
+// all the files that may reference the files with FileAnnotations.
+func Fix(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	config *buflintconfig.Config,
+	fileAnnotations []bufanalysis.FileAnnotation,
+) (storage.ReadBucket, []bufanalysis.FileAnnotation, error) {
+	fixableIDs := stringutil.SliceToMap(FixableIDs)
+	pathToFileAnnotations := make(map[string][]bufanalysis.FileAnnotation)
+	var unfixedFileAnnotations []bufanalysis.FileAnnotation
+	for _, fileAnnotation := range fileAnnotations {
+		if _, ok := fixableIDs[fileAnnotation.Type()]; !ok || fileAnnotation.FileInfo() == nil {
+			unfixedFileAnnotations = append(unfixedFileAnnotations, fileAnnotation)
+			continue
+		}
+		path := fileAnnotation.FileInfo().Path()
+		pathToFileAnnotations[path] = append(pathToFileAnnotations[path], fileAnnotation)
+	}
+	enumZeroValueSuffix := config.EnumZeroValueSuffix
+	if enumZeroValueSuffix == "" {
+		enumZeroValueSuffix = defaultEnumZeroValueSuffix
+	}
+	serviceSuffix := config.ServiceSuffix
+	if serviceSuffix == "" {
+		serviceSuffix = defaultServiceSuffix
+	}
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	if len(pathToFileAnnotations) == 0 {
+		return readWriteBucket, unfixedFileAnnotations, nil
+	}
+	optionNames, err := getOptionNames(ctx, readBucket)
+	if err != nil {
+		return nil, nil, err
+	}
+	paths := make([]string, 0, len(pathToFileAnnotations))
+	for path := range pathToFileAnnotations {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fileUnfixedFileAnnotations, err := fixFile(
+			ctx,
+			readBucket,
+			readWriteBucket,
+			path,
+			pathToFileAnnotations[path],
+			enumZeroValueSuffix,
+			serviceSuffix,
+			optionNames,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		unfixedFileAnnotations = append(unfixedFileAnnotations, fileUnfixedFileAnnotations...)
+	}
+	return readWriteBucket, unfixedFileAnnotations, nil
+}
+
+// fixFile applies the fixes for the FileAnnotations of the file at the path, and writes the
+// file to the writeBucket if any fix was applied.
+//
+// Returns the FileAnnotations that could not be fixed.
+func fixFile(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	writeBucket storage.WriteBucket,
+	path string,
+	fileAnnotations []bufanalysis.FileAnnotation,
+	enumZeroValueSuffix string,
+	serviceSuffix string,
+	optionNames map[string]struct{},
+) (_ []bufanalysis.FileAnnotation, retErr error) {
+	readObjectCloser, err := readBucket.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, readObjectCloser.Close())
+	}()
+	fileNode, err := parser.Parse(readObjectCloser.ExternalPath(), readObjectCloser, reporter.NewHandler(nil))
+	if err != nil {
+		return nil, err
+	}
+	fixer := newFixer(fileNode, enumZeroValueSuffix, serviceSuffix, optionNames)
+	var unfixedFileAnnotations []bufanalysis.FileAnnotation
+	for _, fileAnnotation := range fileAnnotations {
+		if !fixer.Fix(fileAnnotation) {
+			unfixedFileAnnotations = append(unfixedFileAnnotations, fileAnnotation)
+		}
+	}
+	if len(unfixedFileAnnotations) == len(fileAnnotations) {
+		// Nothing was fixed, so we leave the file as-is.
+		return unfixedFileAnnotations, nil
+	}
+	writeObjectCloser, err := writeBucket.Put(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, writeObjectCloser.Close())
+	}()
+	if err := bufformat.FormatFileNode(writeObjectCloser, fileNode); err != nil {
+		return nil, err
+	}
+	if err := writeObjectCloser.SetExternalPath(readObjectCloser.ExternalPath()); err != nil {
+		return nil, err
+	}
+	return unfixedFileAnnotations, nil
+}
+
+// getOptionNames returns the identifiers used in the names and values of the options
+// of the .proto files in the readBucket.
+//
+// Fields and enum values are referenced by their names in options, so these are the
+// names that cannot be renamed without also changing the options.
+func getOptionNames(ctx context.Context, readBucket storage.ReadBucket) (map[string]struct{}, error) {
+	optionNames := make(map[string]struct{})
+	identVisitor := &ast.SimpleVisitor{
+		DoVisitIdentNode: func(identNode *ast.IdentNode) error {
+			optionNames[identNode.Val] = struct{}{}
+			return nil
+		},
+	}
+	optionVisitor := &ast.SimpleVisitor{
+		DoVisitOptionNode: func(optionNode *ast.OptionNode) error {
+			return ast.Walk(optionNode, identVisitor)
+		},
+	}
+	if err := storage.WalkReadObjects(
+		ctx,
+		readBucket,
+		"",
+		func(readObject storage.ReadObject) error {
+			if normalpath.Ext(readObject.Path()) != ".proto" {
+				return nil
+			}
+			fileNode, err := parser.Parse(readObject.ExternalPath(), readObject, reporter.NewHandler(nil))
+			if err != nil {
+				return err
+			}
+			return ast.Walk(fileNode, optionVisitor)
+		},
+	); err != nil {
+		return nil, err
+	}
+	return optionNames, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflintfix
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis/bufanalysistesting"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/diff"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFix(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storageos.NewProvider().NewReadWriteBucket("testdata")
+	require.NoError(t, err)
+	unfixableFileAnnotations := []bufanalysis.FileAnnotation{
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 3, 1, 3, 11, "PACKAGE_VERSION_SUFFIX"),
+		// Renaming barBaz would collide with bar_baz.
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 13, 10, 13, 16, "FIELD_LOWER_SNAKE_CASE"),
+	}
+	fixedReadBucket, unfixedFileAnnotations, err := Fix(
+		ctx,
+		readBucket,
+		&buflintconfig.Config{},
+		append(
+			[]bufanalysis.FileAnnotation{
+				bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 1, 5, 18, "IMPORT_USED"),
+				bufanalysistesting.NewFileAnnotation(t, "a.proto", 9, 10, 9, 16, "FIELD_LOWER_SNAKE_CASE"),
+				bufanalysistesting.NewFileAnnotation(t, "a.proto", 11, 11, 11, 17, "FIELD_LOWER_SNAKE_CASE"),
+				bufanalysistesting.NewFileAnnotation(t, "a.proto", 15, 20, 15, 29, "FIELD_LOWER_SNAKE_CASE"),
+				bufanalysistesting.NewFileAnnotation(t, "a.proto", 17, 5, 17, 9, "ENUM_VALUE_PREFIX"),
+				bufanalysistesting.NewFileAnnotation(t, "a.proto", 17, 5, 17, 9, "ENUM_ZERO_VALUE_SUFFIX"),
+				bufanalysistesting.NewFileAnnotation(t, "a.proto", 19, 5, 19, 8, "ENUM_VALUE_PREFIX"),
+				bufanalysistesting.NewFileAnnotation(t, "a.proto", 25, 3, 25, 14, "ENUM_ZERO_VALUE_SUFFIX"),
+				bufanalysistesting.NewFileAnnotation(t, "a.proto", 28, 9, 28, 16, "SERVICE_SUFFIX"),
+			},
+			unfixableFileAnnotations...,
+		),
+	)
+	require.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(t, unfixableFileAnnotations, unfixedFileAnnotations)
+	fixedData, err := storage.ReadPath(ctx, fixedReadBucket, "a.proto")
+	require.NoError(t, err)
+	expectedData, err := storage.ReadPath(ctx, readBucket, "a.golden.proto")
+	require.NoError(t, err)
+	fileDiff, err := diff.Diff(ctx, command.NewRunner(), expectedData, fixedData, "a.golden.proto", "a.proto (fixed)")
+	require.NoError(t, err)
+	assert.Empty(t, string(fileDiff))
+}
+
+func TestFixNothingFixed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storageos.NewProvider().NewReadWriteBucket("testdata")
+	require.NoError(t, err)
+	fileAnnotations := []bufanalysis.FileAnnotation{
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 13, 10, 13, 16, "FIELD_LOWER_SNAKE_CASE"),
+	}
+	fixedReadBucket, unfixedFileAnnotations, err := Fix(
+		ctx,
+		readBucket,
+		&buflintconfig.Config{},
+		fileAnnotations,
+	)
+	require.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(t, fileAnnotations, unfixedFileAnnotations)
+	paths, err := storage.AllPaths(ctx, fixedReadBucket, "")
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+}
+
+func TestFixReferencedNames(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storageos.NewProvider().NewReadWriteBucket("testdata")
+	require.NoError(t, err)
+	unfixableFileAnnotations := []bufanalysis.FileAnnotation{
+		// ON is the default value of mode.
+		bufanalysistesting.NewFileAnnotation(t, "references/r.proto", 7, 3, 7, 5, "ENUM_VALUE_PREFIX"),
+		// userName is set in the options of Account in s.proto.
+		bufanalysistesting.NewFileAnnotation(t, "references/r.proto", 13, 19, 13, 27, "FIELD_LOWER_SNAKE_CASE"),
+	}
+	fixedReadBucket, unfixedFileAnnotations, err := Fix(
+		ctx,
+		readBucket,
+		&buflintconfig.Config{},
+		append(
+			[]bufanalysis.FileAnnotation{
+				bufanalysistesting.NewFileAnnotation(t, "references/r.proto", 6, 3, 6, 6, "ENUM_VALUE_PREFIX"),
+				bufanalysistesting.NewFileAnnotation(t, "references/r.proto", 12, 19, 12, 30, "FIELD_LOWER_SNAKE_CASE"),
+			},
+			unfixableFileAnnotations...,
+		),
+	)
+	require.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(t, unfixableFileAnnotations, unfixedFileAnnotations)
+	fixedData, err := storage.ReadPath(ctx, fixedReadBucket, "references/r.proto")
+	require.NoError(t, err)
+	assert.Contains(t, string(fixedData), "MODE_OFF = 0;")
+	assert.Contains(t, string(fixedData), "ON = 1;")
+	assert.NotContains(t, string(fixedData), "MODE_ON")
+	assert.Contains(t, string(fixedData), "display_name = 2")
+	assert.Contains(t, string(fixedData), "userName = 3")
+}
@@ -0,0 +1,243 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflintfix
+
+import (
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/bufbuild/protocompile/ast"
+)
+
+// fixer applies fixes to a single file by modifying its AST.
+//
+// FileAnnotations are matched to AST nodes by their start position,
+// which is the location the lint rules report for each violation.
+type fixer struct {
+	fileNode            *ast.FileNode
+	enumZeroValueSuffix string
+	serviceSuffix       string
+	// optionNames are the identifiers used in options, which fields and enum
+	// values cannot be renamed from.
+	optionNames map[string]struct{}
+
+	enumNodes     []*ast.EnumNode
+	messageBodies []*ast.MessageBody
+}
+
+func newFixer(
+	fileNode *ast.FileNode,
+	enumZeroValueSuffix string,
+	serviceSuffix string,
+	optionNames map[string]struct{},
+) *fixer {
+	fixer := &fixer{
+		fileNode:            fileNode,
+		enumZeroValueSuffix: enumZeroValueSuffix,
+		serviceSuffix:       serviceSuffix,
+		optionNames:         optionNames,
+	}
+	for _, decl := range fileNode.Decls {
+		switch decl := decl.(type) {
+		case *ast.MessageNode:
+			fixer.addMessageBody(&decl.MessageBody)
+		case *ast.EnumNode:
+			fixer.enumNodes = append(fixer.enumNodes, decl)
+		case *ast.ExtendNode:
+			fixer.addExtendNode(decl)
+		}
+	}
+	return fixer
+}
+
+// Fix applies the fix for the FileAnnotation.
+//
+// Returns true if the fix was applied.
+func (f *fixer) Fix(fileAnnotation bufanalysis.FileAnnotation) bool {
+	switch fileAnnotation.Type() {
+	case "ENUM_VALUE_PREFIX":
+		return f.fixEnumValueName(fileAnnotation, f.enumValuePrefixName)
+	case "ENUM_ZERO_VALUE_SUFFIX":
+		return f.fixEnumValueName(fileAnnotation, f.enumZeroValueSuffixName)
+	case "FIELD_LOWER_SNAKE_CASE":
+		return f.fixFieldLowerSnakeCase(fileAnnotation)
+	case "IMPORT_USED":
+		return f.fixImportUsed(fileAnnotation)
+	case "SERVICE_SUFFIX":
+		return f.fixServiceSuffix(fileAnnotation)
+	default:
+		return false
+	}
+}
+
+func (f *fixer) fixEnumValueName(
+	fileAnnotation bufanalysis.FileAnnotation,
+	nameFunc func(enumName string, enumValueName string) string,
+) bool {
+	for _, enumNode := range f.enumNodes {
+		var enumValueNames []*ast.IdentNode
+		for _, decl := range enumNode.Decls {
+			if enumValueNode, ok := decl.(*ast.EnumValueNode); ok {
+				enumValueNames = append(enumValueNames, enumValueNode.Name)
+			}
+		}
+		for _, enumValueName := range enumValueNames {
+			if f.isAt(enumValueName, fileAnnotation) {
+				return f.rename(enumValueName, nameFunc(enumNode.Name.Val, enumValueName.Val), enumValueNames)
+			}
+		}
+	}
+	return false
+}
+
+func (f *fixer) enumValuePrefixName(enumName string, enumValueName string) string {
+	prefix := stringutil.ToUpperSnakeCase(enumName) + "_"
+	if strings.HasPrefix(enumValueName, prefix) {
+		// Already fixed, for example by the fix for ENUM_ZERO_VALUE_SUFFIX.
+		return enumValueName
+	}
+	return prefix + enumValueName
+}
+
+func (f *fixer) enumZeroValueSuffixName(enumName string, enumValueName string) string {
+	if strings.HasSuffix(enumValueName, f.enumZeroValueSuffix) {
+		return enumValueName
+	}
+	// The zero value carries no meaning, so we replace the entire name instead of
+	// appending the suffix, i.e. FOO_NONE becomes FOO_UNSPECIFIED.
+	return stringutil.ToUpperSnakeCase(enumName) + "_" + strings.TrimPrefix(f.enumZeroValueSuffix, "_")
+}
+
+func (f *fixer) fixFieldLowerSnakeCase(fileAnnotation bufanalysis.FileAnnotation) bool {
+	for _, messageBody := range f.messageBodies {
+		fieldNames := messageBodyFieldNames(messageBody)
+		for _, fieldName := range fieldNames {
+			if f.isAt(fieldName, fileAnnotation) {
+				return f.rename(fieldName, stringutil.ToLowerSnakeCase(fieldName.Val), fieldNames)
+			}
+		}
+	}
+	return false
+}
+
+func (f *fixer) fixImportUsed(fileAnnotation bufanalysis.FileAnnotation) bool {
+	for i, decl := range f.fileNode.Decls {
+		if importNode, ok := decl.(*ast.ImportNode); ok && f.isAt(importNode, fileAnnotation) {
+			f.fileNode.Decls = append(f.fileNode.Decls[:i], f.fileNode.Decls[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fixer) fixServiceSuffix(fileAnnotation bufanalysis.FileAnnotation) bool {
+	for _, decl := range f.fileNode.Decls {
+		if serviceNode, ok := decl.(*ast.ServiceNode); ok && f.isAt(serviceNode.Name, fileAnnotation) {
+			if !strings.HasSuffix(serviceNode.Name.Val, f.serviceSuffix) {
+				serviceNode.Name.Val += f.serviceSuffix
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// rename renames the field or enum value name identNode with renameIdent, unless
+// the name is used in an option, in which case the fix cannot be applied as the
+// option would no longer resolve.
+func (f *fixer) rename(identNode *ast.IdentNode, name string, siblings []*ast.IdentNode) bool {
+	if _, ok := f.optionNames[identNode.Val]; ok && identNode.Val != name {
+		return false
+	}
+	return renameIdent(identNode, name, siblings)
+}
+
+// isAt returns true if the node starts at the start position of the FileAnnotation.
+func (f *fixer) isAt(node ast.Node, fileAnnotation bufanalysis.FileAnnotation) bool {
+	start := f.fileNode.NodeInfo(node).Start()
+	return start.Line == fileAnnotation.StartLine() && start.Col == fileAnnotation.StartColumn()
+}
+
+func (f *fixer) addMessageBody(messageBody *ast.MessageBody) {
+	f.messageBodies = append(f.messageBodies, messageBody)
+	for _, decl := range messageBody.Decls {
+		switch decl := decl.(type) {
+		case *ast.MessageNode:
+			f.addMessageBody(&decl.MessageBody)
+		case *ast.GroupNode:
+			f.addMessageBody(&decl.MessageBody)
+		case *ast.OneOfNode:
+			for _, oneofDecl := range decl.Decls {
+				if groupNode, ok := oneofDecl.(*ast.GroupNode); ok {
+					f.addMessageBody(&groupNode.MessageBody)
+				}
+			}
+		case *ast.EnumNode:
+			f.enumNodes = append(f.enumNodes, decl)
+		case *ast.ExtendNode:
+			f.addExtendNode(decl)
+		}
+	}
+}
+
+func (f *fixer) addExtendNode(extendNode *ast.ExtendNode) {
+	for _, decl := range extendNode.Decls {
+		if groupNode, ok := decl.(*ast.GroupNode); ok {
+			f.addMessageBody(&groupNode.MessageBody)
+		}
+	}
+}
+
+// messageBodyFieldNames returns the names of the fields declared in the message body,
+// including the fields within oneofs.
+//
+// The names of groups are not included, as the name of a group field is derived from
+// the name of the group and cannot be changed on its own.
+func messageBodyFieldNames(messageBody *ast.MessageBody) []*ast.IdentNode {
+	var fieldNames []*ast.IdentNode
+	for _, decl := range messageBody.Decls {
+		switch decl := decl.(type) {
+		case *ast.FieldNode:
+			fieldNames = append(fieldNames, decl.Name)
+		case *ast.MapFieldNode:
+			fieldNames = append(fieldNames, decl.Name)
+		case *ast.OneOfNode:
+			for _, oneofDecl := range decl.Decls {
+				if fieldNode, ok := oneofDecl.(*ast.FieldNode); ok {
+					fieldNames = append(fieldNames, fieldNode.Name)
+				}
+			}
+		}
+	}
+	return fieldNames
+}
+
+// renameIdent renames the identNode to the given name, unless the name is already
+// used by one of the siblings, in which case the fix cannot be applied.
+//
+// Returns true if the identNode has the given name after the call.
+func renameIdent(identNode *ast.IdentNode, name string, siblings []*ast.IdentNode) bool {
+	if identNode.Val == name {
+		return true
+	}
+	for _, sibling := range siblings {
+		if sibling != identNode && sibling.Val == name {
+			return false
+		}
+	}
+	identNode.Val = name
+	return true
+}
@@ -158,8 +158,25 @@ func (m *v1beta1Migrator) maybeMigrateConfig(dirPath string) (bool, error) {
 			Build: bufmoduleconfig.ExternalConfigV1{
 				Excludes: excludes,
 			},
-			Breaking: bufbreakingconfig.ExternalConfigV1(v1beta1Config.Breaking),
-			Lint:     buflintconfig.ExternalConfigV1(v1beta1Config.Lint),
+			Breaking: bufbreakingconfig.ExternalConfigV1{
+				Use:                    v1beta1Config.Breaking.Use,
+				Except:                 v1beta1Config.Breaking.Except,
+				Ignore:                 v1beta1Config.Breaking.Ignore,
+				IgnoreOnly:             v1beta1Config.Breaking.IgnoreOnly,
+				IgnoreUnstablePackages: v1beta1Config.Breaking.IgnoreUnstablePackages,
+			},
+			Lint: buflintconfig.ExternalConfigV1{
+				Use:                                  v1beta1Config.Lint.Use,
+				Except:                               v1beta1Config.Lint.Except,
+				Ignore:                               v1beta1Config.Lint.Ignore,
+				IgnoreOnly:                           v1beta1Config.Lint.IgnoreOnly,
+				EnumZeroValueSuffix:                  v1beta1Config.Lint.EnumZeroValueSuffix,
+				RPCAllowSameRequestResponse:          v1beta1Config.Lint.RPCAllowSameRequestResponse,
+				RPCAllowGoogleProtobufEmptyRequests:  v1beta1Config.Lint.RPCAllowGoogleProtobufEmptyRequests,
+				RPCAllowGoogleProtobufEmptyResponses: v1beta1Config.Lint.RPCAllowGoogleProtobufEmptyResponses,
+				ServiceSuffix:                        v1beta1Config.Lint.ServiceSuffix,
+				AllowCommentIgnores:                  v1beta1Config.Lint.AllowCommentIgnores,
+			},
 		}
 		newConfigPath := filepath.Join(dirPath, bufconfig.ExternalConfigV1FilePath)
 		if err := m.writeV1Config(newConfigPath, v1Config, ".", v1beta1Config.Name); err != nil {
@@ -334,8 +351,8 @@ func (m *v1beta1Migrator) maybeMigrateGenTemplate(dirPath string) (bool, error)
 		)
 	}
 	switch versionedConfig.Version {
-	case bufgen.V1Version:
-		// OK, file was already v1
+	case bufgen.V1Version, bufgen.V2Version:
+		// OK, file was already v1 or later
 		return false, nil
 	case bufgen.V1Beta1Version, "":
 		// Continue to migrate
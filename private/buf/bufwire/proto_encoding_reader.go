@@ -62,7 +62,7 @@ func (p *protoEncodingReader) GetMessage(
 			span.SetStatus(codes.Error, retErr.Error())
 		}
 	}()
-	// Currently, this support bin and JSON format.
+	// Currently, this support bin, JSON and txtpb format.
 	resolver, err := protoencoding.NewResolver(
 		bufimage.ImageToFileDescriptors(
 			image,
@@ -77,6 +77,8 @@ func (p *protoEncodingReader) GetMessage(
 		unmarshaler = protoencoding.NewWireUnmarshaler(resolver)
 	case bufconvert.MessageEncodingJSON:
 		unmarshaler = protoencoding.NewJSONUnmarshaler(resolver)
+	case bufconvert.MessageEncodingTxtpb:
+		unmarshaler = protoencoding.NewTxtpbUnmarshaler(resolver)
 	default:
 		return nil, errors.New("unknown message encoding type")
 	}
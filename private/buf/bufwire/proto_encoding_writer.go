@@ -50,7 +50,7 @@ func (p *protoEncodingWriter) PutMessage(
 	message proto.Message,
 	messageRef bufconvert.MessageEncodingRef,
 ) (retErr error) {
-	// Currently, this support bin and JSON format.
+	// Currently, this support bin, JSON and txtpb format.
 	resolver, err := protoencoding.NewResolver(
 		bufimage.ImageToFileDescriptors(
 			image,
@@ -65,6 +65,8 @@ func (p *protoEncodingWriter) PutMessage(
 		marshaler = protoencoding.NewWireMarshaler()
 	case bufconvert.MessageEncodingJSON:
 		marshaler = protoencoding.NewJSONMarshalerIndent(resolver)
+	case bufconvert.MessageEncodingTxtpb:
+		marshaler = protoencoding.NewTxtpbMarshaler(resolver)
 	default:
 		return errors.New("unknown message encoding type")
 	}
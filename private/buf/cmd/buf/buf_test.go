@@ -489,50 +489,59 @@ func TestFailCheckBreaking5(t *testing.T) {
 func TestCheckLsLintRules1(t *testing.T) {
 	t.Parallel()
 	expectedStdout := `
-ID                                CATEGORIES               PURPOSE
-DIRECTORY_SAME_PACKAGE            MINIMAL, BASIC, DEFAULT  Checks that all files in a given directory are in the same package.
-PACKAGE_DEFINED                   MINIMAL, BASIC, DEFAULT  Checks that all files have a package defined.
-PACKAGE_DIRECTORY_MATCH           MINIMAL, BASIC, DEFAULT  Checks that all files are in a directory that matches their package name.
-PACKAGE_SAME_DIRECTORY            MINIMAL, BASIC, DEFAULT  Checks that all files with a given package are in the same directory.
-ENUM_FIRST_VALUE_ZERO             BASIC, DEFAULT           Checks that all first values of enums have a numeric value of 0.
-ENUM_NO_ALLOW_ALIAS               BASIC, DEFAULT           Checks that enums do not have the allow_alias option set.
-ENUM_PASCAL_CASE                  BASIC, DEFAULT           Checks that enums are PascalCase.
-ENUM_VALUE_UPPER_SNAKE_CASE       BASIC, DEFAULT           Checks that enum values are UPPER_SNAKE_CASE.
-FIELD_LOWER_SNAKE_CASE            BASIC, DEFAULT           Checks that field names are lower_snake_case.
-IMPORT_NO_PUBLIC                  BASIC, DEFAULT           Checks that imports are not public.
-IMPORT_NO_WEAK                    BASIC, DEFAULT           Checks that imports are not weak.
-IMPORT_USED                       BASIC, DEFAULT           Checks that imports are used.
-MESSAGE_PASCAL_CASE               BASIC, DEFAULT           Checks that messages are PascalCase.
-ONEOF_LOWER_SNAKE_CASE            BASIC, DEFAULT           Checks that oneof names are lower_snake_case.
-PACKAGE_LOWER_SNAKE_CASE          BASIC, DEFAULT           Checks that packages are lower_snake.case.
-PACKAGE_SAME_CSHARP_NAMESPACE     BASIC, DEFAULT           Checks that all files with a given package have the same value for the csharp_namespace option.
-PACKAGE_SAME_GO_PACKAGE           BASIC, DEFAULT           Checks that all files with a given package have the same value for the go_package option.
-PACKAGE_SAME_JAVA_MULTIPLE_FILES  BASIC, DEFAULT           Checks that all files with a given package have the same value for the java_multiple_files option.
-PACKAGE_SAME_JAVA_PACKAGE         BASIC, DEFAULT           Checks that all files with a given package have the same value for the java_package option.
-PACKAGE_SAME_PHP_NAMESPACE        BASIC, DEFAULT           Checks that all files with a given package have the same value for the php_namespace option.
-PACKAGE_SAME_RUBY_PACKAGE         BASIC, DEFAULT           Checks that all files with a given package have the same value for the ruby_package option.
-PACKAGE_SAME_SWIFT_PREFIX         BASIC, DEFAULT           Checks that all files with a given package have the same value for the swift_prefix option.
-RPC_PASCAL_CASE                   BASIC, DEFAULT           Checks that RPCs are PascalCase.
-SERVICE_PASCAL_CASE               BASIC, DEFAULT           Checks that services are PascalCase.
-SYNTAX_SPECIFIED                  BASIC, DEFAULT           Checks that all files have a syntax specified.
-ENUM_VALUE_PREFIX                 DEFAULT                  Checks that enum values are prefixed with ENUM_NAME_UPPER_SNAKE_CASE.
-ENUM_ZERO_VALUE_SUFFIX            DEFAULT                  Checks that enum zero values are suffixed with _UNSPECIFIED (suffix is configurable).
-FILE_LOWER_SNAKE_CASE             DEFAULT                  Checks that filenames are lower_snake_case.
-PACKAGE_VERSION_SUFFIX            DEFAULT                  Checks that the last component of all packages is a version of the form v\d+, v\d+test.*, v\d+(alpha|beta)\d+, or v\d+p\d+(alpha|beta)\d+, where numbers are >=1.
-RPC_REQUEST_RESPONSE_UNIQUE       DEFAULT                  Checks that RPC request and response types are only used in one RPC (configurable).
-RPC_REQUEST_STANDARD_NAME         DEFAULT                  Checks that RPC request type names are RPCNameRequest or ServiceNameRPCNameRequest (configurable).
-RPC_RESPONSE_STANDARD_NAME        DEFAULT                  Checks that RPC response type names are RPCNameResponse or ServiceNameRPCNameResponse (configurable).
-SERVICE_SUFFIX                    DEFAULT                  Checks that services are suffixed with Service (suffix is configurable).
-COMMENT_ENUM                      COMMENTS                 Checks that enums have non-empty comments.
-COMMENT_ENUM_VALUE                COMMENTS                 Checks that enum values have non-empty comments.
-COMMENT_FIELD                     COMMENTS                 Checks that fields have non-empty comments.
-COMMENT_MESSAGE                   COMMENTS                 Checks that messages have non-empty comments.
-COMMENT_ONEOF                     COMMENTS                 Checks that oneof have non-empty comments.
-COMMENT_RPC                       COMMENTS                 Checks that RPCs have non-empty comments.
-COMMENT_SERVICE                   COMMENTS                 Checks that services have non-empty comments.
-RPC_NO_CLIENT_STREAMING           UNARY_RPC                Checks that RPCs are not client streaming.
-RPC_NO_SERVER_STREAMING           UNARY_RPC                Checks that RPCs are not server streaming.
-PACKAGE_NO_IMPORT_CYCLE                                    Checks that packages do not have import cycles.
+ID                                          CATEGORIES               PURPOSE
+DIRECTORY_SAME_PACKAGE                      MINIMAL, BASIC, DEFAULT  Checks that all files in a given directory are in the same package.
+PACKAGE_DEFINED                             MINIMAL, BASIC, DEFAULT  Checks that all files have a package defined.
+PACKAGE_DIRECTORY_MATCH                     MINIMAL, BASIC, DEFAULT  Checks that all files are in a directory that matches their package name.
+PACKAGE_SAME_DIRECTORY                      MINIMAL, BASIC, DEFAULT  Checks that all files with a given package are in the same directory.
+ENUM_FIRST_VALUE_ZERO                       BASIC, DEFAULT           Checks that all first values of enums have a numeric value of 0.
+ENUM_NO_ALLOW_ALIAS                         BASIC, DEFAULT           Checks that enums do not have the allow_alias option set.
+ENUM_PASCAL_CASE                            BASIC, DEFAULT           Checks that enums are PascalCase.
+ENUM_VALUE_UPPER_SNAKE_CASE                 BASIC, DEFAULT           Checks that enum values are UPPER_SNAKE_CASE.
+FIELD_LOWER_SNAKE_CASE                      BASIC, DEFAULT           Checks that field names are lower_snake_case.
+IMPORT_NO_PUBLIC                            BASIC, DEFAULT           Checks that imports are not public.
+IMPORT_NO_WEAK                              BASIC, DEFAULT           Checks that imports are not weak.
+IMPORT_USED                                 BASIC, DEFAULT           Checks that imports are used.
+MESSAGE_PASCAL_CASE                         BASIC, DEFAULT           Checks that messages are PascalCase.
+ONEOF_LOWER_SNAKE_CASE                      BASIC, DEFAULT           Checks that oneof names are lower_snake_case.
+PACKAGE_LOWER_SNAKE_CASE                    BASIC, DEFAULT           Checks that packages are lower_snake.case.
+PACKAGE_SAME_CSHARP_NAMESPACE               BASIC, DEFAULT           Checks that all files with a given package have the same value for the csharp_namespace option.
+PACKAGE_SAME_GO_PACKAGE                     BASIC, DEFAULT           Checks that all files with a given package have the same value for the go_package option.
+PACKAGE_SAME_JAVA_MULTIPLE_FILES            BASIC, DEFAULT           Checks that all files with a given package have the same value for the java_multiple_files option.
+PACKAGE_SAME_JAVA_PACKAGE                   BASIC, DEFAULT           Checks that all files with a given package have the same value for the java_package option.
+PACKAGE_SAME_PHP_NAMESPACE                  BASIC, DEFAULT           Checks that all files with a given package have the same value for the php_namespace option.
+PACKAGE_SAME_RUBY_PACKAGE                   BASIC, DEFAULT           Checks that all files with a given package have the same value for the ruby_package option.
+PACKAGE_SAME_SWIFT_PREFIX                   BASIC, DEFAULT           Checks that all files with a given package have the same value for the swift_prefix option.
+RPC_PASCAL_CASE                             BASIC, DEFAULT           Checks that RPCs are PascalCase.
+SERVICE_PASCAL_CASE                         BASIC, DEFAULT           Checks that services are PascalCase.
+SYNTAX_SPECIFIED                            BASIC, DEFAULT           Checks that all files have a syntax specified.
+ENUM_VALUE_PREFIX                           DEFAULT                  Checks that enum values are prefixed with ENUM_NAME_UPPER_SNAKE_CASE.
+ENUM_ZERO_VALUE_SUFFIX                      DEFAULT                  Checks that enum zero values are suffixed with _UNSPECIFIED (suffix is configurable).
+FILE_LOWER_SNAKE_CASE                       DEFAULT                  Checks that filenames are lower_snake_case.
+PACKAGE_VERSION_SUFFIX                      DEFAULT                  Checks that the last component of all packages is a version of the form v\d+, v\d+test.*, v\d+(alpha|beta)\d+, or v\d+p\d+(alpha|beta)\d+, where numbers are >=1.
+RPC_REQUEST_RESPONSE_UNIQUE                 DEFAULT                  Checks that RPC request and response types are only used in one RPC (configurable).
+RPC_REQUEST_STANDARD_NAME                   DEFAULT                  Checks that RPC request type names are RPCNameRequest or ServiceNameRPCNameRequest (configurable).
+RPC_RESPONSE_STANDARD_NAME                  DEFAULT                  Checks that RPC response type names are RPCNameResponse or ServiceNameRPCNameResponse (configurable).
+SERVICE_SUFFIX                              DEFAULT                  Checks that services are suffixed with Service (suffix is configurable).
+COMMENT_ENUM                                COMMENTS                 Checks that enums have non-empty comments.
+COMMENT_ENUM_VALUE                          COMMENTS                 Checks that enum values have non-empty comments.
+COMMENT_FIELD                               COMMENTS                 Checks that fields have non-empty comments.
+COMMENT_MESSAGE                             COMMENTS                 Checks that messages have non-empty comments.
+COMMENT_ONEOF                               COMMENTS                 Checks that oneof have non-empty comments.
+COMMENT_RPC                                 COMMENTS                 Checks that RPCs have non-empty comments.
+COMMENT_SERVICE                             COMMENTS                 Checks that services have non-empty comments.
+RPC_NO_CLIENT_STREAMING                     UNARY_RPC                Checks that RPCs are not client streaming.
+RPC_NO_SERVER_STREAMING                     UNARY_RPC                Checks that RPCs are not server streaming.
+COMMENT_FIELD_MIN_LENGTH                    COMMENT_QUALITY          Checks that fields have comments of at least 10 characters (length is configurable).
+COMMENT_FIELD_NAME_PREFIX                   COMMENT_QUALITY          Checks that field comments begin with the name of the field.
+COMMENT_FIELD_NO_TODO                       COMMENT_QUALITY          Checks that fields do not have comments containing TODO, FIXME, XXX (markers are configurable).
+COMMENT_RPC_MIN_LENGTH                      COMMENT_QUALITY          Checks that RPCs have comments of at least 10 characters (length is configurable).
+COMMENT_RPC_NAME_PREFIX                     COMMENT_QUALITY          Checks that RPC comments begin with the name of the RPC.
+COMMENT_RPC_NO_TODO                         COMMENT_QUALITY          Checks that RPCs do not have comments containing TODO, FIXME, XXX (markers are configurable).
+EXTENSION_RANGE_EXPLICIT_BOUNDS             FIELD_NUMBERS            Checks that extension ranges have an explicit upper bound instead of max.
+MESSAGE_FIELD_NUMBERS_CONTIGUOUS            FIELD_NUMBERS            Checks that message field numbers are contiguous, with gaps only for reserved numbers and extension ranges.
+MESSAGE_NO_IMPLEMENTATION_RESERVED_NUMBERS  FIELD_NUMBERS            Checks that message fields and explicitly bounded extension ranges do not use the numbers 19000 to 19999 reserved for the Protobuf implementation.
+PACKAGE_NO_IMPORT_CYCLE                                              Checks that packages do not have import cycles.
 		`
 	testRunStdout(
 		t,
@@ -682,6 +691,13 @@ FIELD_WIRE_JSON_COMPATIBLE_TYPE                 WIRE_JSON
 ENUM_VALUE_NO_DELETE_UNLESS_NUMBER_RESERVED     WIRE_JSON, WIRE                 Checks that enum values are not deleted from a given enum unless the number is reserved.
 FIELD_NO_DELETE_UNLESS_NUMBER_RESERVED          WIRE_JSON, WIRE                 Checks that fields are not deleted from a given message unless the number is reserved.
 FIELD_WIRE_COMPATIBLE_TYPE                      WIRE                            Checks that fields have wire-compatible types in a given message.
+ENUM_VALUE_NO_DELETE_UNLESS_DEPRECATED          DEPRECATION                     Checks that enum values are not deleted from a given enum unless they were deprecated.
+FIELD_NO_DELETE_UNLESS_DEPRECATED               DEPRECATION                     Checks that fields are not deleted from a given message unless they were deprecated.
+MESSAGE_NO_DELETE_UNLESS_DEPRECATED             DEPRECATION                     Checks that messages are not deleted from a given file unless they were deprecated.
+RPC_NO_DELETE_UNLESS_DEPRECATED                 DEPRECATION                     Checks that rpcs are not deleted from a given service unless they were deprecated.
+FIELD_NO_VALIDATION_NARROWING                   VALIDATION                      Checks that the protovalidate and protoc-gen-validate constraints of fields are not narrowed.
+MESSAGE_NO_VALIDATION_NARROWING                 VALIDATION                      Checks that the protovalidate and protoc-gen-validate constraints of messages are not narrowed.
+ONEOF_NO_VALIDATION_NARROWING                   VALIDATION                      Checks that the protovalidate and protoc-gen-validate constraints of oneofs are not narrowed.
 		`
 	testRunStdout(
 		t,
@@ -1481,6 +1497,28 @@ testdata/paths/a/v3/foo/foo.proto:3:1:Package name "a.v3.foo" should be suffixed
 	)
 }
 
+func TestLintFixDiff(t *testing.T) {
+	t.Parallel()
+	stdout := bytes.NewBuffer(nil)
+	// The files are not changed with --diff, so the command fails as they still have the violations.
+	testRun(
+		t,
+		bufcli.ExitCodeFileAnnotation,
+		nil,
+		stdout,
+		"lint",
+		filepath.Join("testdata", "paths"),
+		"--path",
+		filepath.Join("testdata", "paths", "a", "v3"),
+		"--exclude-path",
+		filepath.Join("testdata", "paths", "a", "v3", "foo"),
+		"--fix",
+		"--diff",
+	)
+	assert.Contains(t, stdout.String(), "-  string Value = 2;")
+	assert.Contains(t, stdout.String(), "+  string value = 2;")
+}
+
 func TestBreakingWithPaths(t *testing.T) {
 	tempDir := t.TempDir()
 	testRunStdout(t, nil, 0, ``, "build", filepath.Join("command", "generate", "testdata", "paths"), "-o", filepath.Join(tempDir, "previous.bin"))
@@ -2094,6 +2132,42 @@ func TestFormatDiff(t *testing.T) {
 	)
 }
 
+func TestFormatDiffGitHubActions(t *testing.T) {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandExitCode(
+		t,
+		func(use string) *appcmd.Command { return NewRootCommand(use) },
+		0,
+		internaltesting.NewEnvFunc(t),
+		nil,
+		stdout,
+		stderr,
+		"format",
+		filepath.Join("testdata", "format", "diff"),
+		"-d",
+		"--error-format",
+		"github-actions",
+	)
+	assert.Contains(
+		t,
+		stdout.String(),
+		`
+@@ -1,13 +1,7 @@
+-
+ syntax = "proto3";
+`,
+	)
+	assert.Equal(
+		t,
+		fmt.Sprintf(
+			"::error file=%s,line=1,endLine=1,title=FORMAT::File is not formatted. Run buf format -w to format it.\n",
+			filepath.Join("testdata", "format", "diff", "diff.proto"),
+		),
+		stderr.String(),
+	)
+}
+
 // Tests if the exit code is set for common invocations of buf format
 // with the --exit-code flag.
 func TestFormatExitCode(t *testing.T) {
@@ -24,6 +24,7 @@ import (
 	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/buf/bufwire"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
@@ -44,6 +45,7 @@ const (
 	againstConfigFlagName     = "against-config"
 	excludePathsFlagName      = "exclude-path"
 	disableSymlinksFlagName   = "disable-symlinks"
+	allowPluginsFlagName      = "allow-plugins"
 )
 
 // NewCommand returns a new Command.
@@ -56,7 +58,13 @@ func NewCommand(
 		Use:   name + " <input> --against <against-input>",
 		Short: "Verify no breaking changes have been made",
 		Long: `buf breaking makes sure that the <input> location has no breaking changes compared to the <against-input> location. ` +
-			bufcli.GetInputLong(`the source, module, or image to check for breaking changes`),
+			bufcli.GetInputLong(`the source, module, or image to check for breaking changes`) + `
+
+The check plugins configured in the breaking section of the configuration are arbitrary commands,
+so they are only run with the --allow-plugins flag, and only if the configuration of the <input> is
+read from a local directory or proto file, or is set with --config. The configuration of the
+<against-input> is never used to run plugins. Breaking change detection fails if rules provided
+by a plugin are in use but plugins are not run.`,
 		Args: cobra.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
 			func(ctx context.Context, container appflag.Container) error {
@@ -78,6 +86,7 @@ type flags struct {
 	AgainstConfig     string
 	ExcludePaths      []string
 	DisableSymlinks   bool
+	AllowPlugins      bool
 	// special
 	InputHashtag string
 }
@@ -138,6 +147,15 @@ Overrides --%s`,
 		"",
 		`The file or data to use to configure the against source, module, or image`,
 	)
+	flagSet.BoolVar(
+		&f.AllowPlugins,
+		allowPluginsFlagName,
+		false,
+		fmt.Sprintf(
+			"Run the check plugins in the configuration. Requires a local input or --%s",
+			configFlagName,
+		),
+	)
 }
 
 func run(
@@ -159,6 +177,20 @@ func run(
 	if err != nil {
 		return err
 	}
+	var handlerOptions []bufbreaking.HandlerOption
+	if flags.AllowPlugins {
+		// Plugins run arbitrary commands, so they are only run from configuration that
+		// the user controls. Only the configuration of the input is used for checks, so
+		// the against input does not need to be local.
+		if !buffetch.IsLocalRef(ref) && flags.Config == "" {
+			return appcmd.NewInvalidArgumentErrorf(
+				"--%s can only be used with local inputs or with --%s",
+				allowPluginsFlagName,
+				configFlagName,
+			)
+		}
+		handlerOptions = append(handlerOptions, bufbreaking.HandlerWithPlugins())
+	}
 	storageosProvider := bufcli.NewStorageosProvider(flags.DisableSymlinks)
 	runner := command.NewRunner()
 	clientConfig, err := bufcli.NewConnectClientConfig(container)
@@ -247,10 +279,12 @@ func run(
 		fileAnnotations, err := breakingForImage(
 			ctx,
 			container,
+			runner,
 			imageConfig,
 			againstImageConfigs[i],
 			flags.ExcludeImports,
 			flags.ErrorFormat,
+			handlerOptions,
 		)
 		if err != nil {
 			return err
@@ -258,10 +292,22 @@ func run(
 		allFileAnnotations = append(allFileAnnotations, fileAnnotations...)
 	}
 	if len(allFileAnnotations) > 0 {
+		var allRules []bufcheck.Rule
+		// The rules are only used to describe the rules for the sarif format.
+		if format, err := bufanalysis.ParseFormat(flags.ErrorFormat); err == nil && format == bufanalysis.FormatSARIF {
+			for _, imageConfig := range imageConfigs {
+				rules, err := bufbreaking.RulesForConfig(imageConfig.Config().Breaking)
+				if err != nil {
+					return err
+				}
+				allRules = append(allRules, rules...)
+			}
+		}
 		if err := bufanalysis.PrintFileAnnotations(
 			container.Stdout(),
 			bufanalysis.DeduplicateAndSortFileAnnotations(allFileAnnotations),
 			flags.ErrorFormat,
+			bufanalysis.PrintFileAnnotationsWithRules(bufcheck.AnalysisRulesForRules(allRules)...),
 		); err != nil {
 			return err
 		}
@@ -273,10 +319,12 @@ func run(
 func breakingForImage(
 	ctx context.Context,
 	container appflag.Container,
+	runner command.Runner,
 	imageConfig bufwire.ImageConfig,
 	againstImageConfig bufwire.ImageConfig,
 	excludeImports bool,
 	errorFormat string,
+	handlerOptions []bufbreaking.HandlerOption,
 ) ([]bufanalysis.FileAnnotation, error) {
 	image := imageConfig.Image()
 	if excludeImports {
@@ -286,12 +334,16 @@ func breakingForImage(
 	if excludeImports {
 		againstImage = bufimage.ImageWithoutImports(againstImage)
 	}
-	return bufbreaking.NewHandler(container.Logger()).Check(
+	fileAnnotations, err := bufbreaking.NewHandler(container.Logger(), runner, handlerOptions...).Check(
 		ctx,
 		imageConfig.Config().Breaking,
 		againstImage,
 		image,
 	)
+	if errors.Is(err, bufcheck.ErrPluginsNotEnabled) {
+		return nil, fmt.Errorf("%w, plugins are only run with --%s", err, allowPluginsFlagName)
+	}
+	return fileAnnotations, err
 }
 
 func getExternalPathsForImages(imageConfigs []bufwire.ImageConfig, excludeImports bool) ([]string, error) {
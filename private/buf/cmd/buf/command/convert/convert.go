@@ -47,9 +47,9 @@ func NewCommand(
 	flags := newFlags()
 	return &appcmd.Command{
 		Use:   name + " <input>",
-		Short: "Convert a message from binary to JSON or vice versa",
+		Short: "Convert a message between binary, JSON and text formats",
 		Long: `
-Use an input proto to interpret a proto/json message and convert it to a different format.
+Use an input proto to interpret a proto/json/txtpb message and convert it to a different format.
 
 Examples:
 
@@ -78,6 +78,10 @@ Redirect from stdin to <input>:
 Use a module on the bsr:
 
     $ buf convert <buf.build/owner/repository> --type buf.Foo --from=payload.json
+
+Convert a message in the Protobuf text format, inferred from the .txtpb extension, to JSON:
+
+    $ buf convert example.proto --type buf.Foo --from=payload.txtpb --to=output.json
 `,
 		Args: cobra.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
@@ -227,12 +231,16 @@ func run(
 
 // inverseEncoding returns the opposite encoding of the provided encoding,
 // which will be the default output encoding for a given payload encoding.
+//
+// The txtpb encoding is converted to the binary encoding by default.
 func inverseEncoding(encoding bufconvert.MessageEncoding) (bufconvert.MessageEncoding, error) {
 	switch encoding {
 	case bufconvert.MessageEncodingBin:
 		return bufconvert.MessageEncodingJSON, nil
 	case bufconvert.MessageEncodingJSON:
 		return bufconvert.MessageEncodingBin, nil
+	case bufconvert.MessageEncodingTxtpb:
+		return bufconvert.MessageEncodingBin, nil
 	default:
 		return 0, fmt.Errorf("unknown message encoding %v", encoding)
 	}
@@ -69,6 +69,40 @@ func TestConvertDir(t *testing.T) {
 			"testdata/convert/bin_json/payload.bin",
 		)
 	})
+	t.Run("txtpb-input", func(t *testing.T) {
+		appcmdtesting.RunCommandExitCodeStdout(
+			t,
+			cmd,
+			0,
+			`{"one":"55"}`,
+			nil,
+			nil,
+			"testdata/convert/bin_json/buf.proto",
+			"--type",
+			"buf.Foo",
+			"--from",
+			"testdata/convert/bin_json/payload.txtpb",
+			"--to",
+			"-#format=json",
+		)
+	})
+	t.Run("txtpb-from-stdin", func(t *testing.T) {
+		appcmdtesting.RunCommandExitCodeStdout(
+			t,
+			cmd,
+			0,
+			`{"one":"55"}`,
+			nil,
+			strings.NewReader("one: 55"),
+			"testdata/convert/bin_json/buf.proto",
+			"--type",
+			"buf.Foo",
+			"--from",
+			"-#format=txtpb",
+			"--to",
+			"-#format=json",
+		)
+	})
 	t.Run("wellknowntype", func(t *testing.T) {
 		appcmdtesting.RunCommandExitCodeStdout(
 			t,
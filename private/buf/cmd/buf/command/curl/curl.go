@@ -55,10 +55,26 @@ const (
 	reflectHeaderFlagName   = "reflect-header"
 	reflectProtocolFlagName = "reflect-protocol"
 
+	// Schema inspection flags
+	listServicesFlagName = "list-services"
+	listMethodsFlagName  = "list-methods"
+	describeFlagName     = "describe"
+
+	// Script flags
+	scriptFlagName             = "script"
+	scriptReportFormatFlagName = "script-report-format"
+
+	// Record and replay flags
+	recordFlagName = "record"
+	replayFlagName = "replay"
+
 	// Protocol/transport flags
 	protocolFlagName            = "protocol"
 	unixSocketFlagName          = "unix-socket"
 	http2PriorKnowledgeFlagName = "http2-prior-knowledge"
+	codecFlagName               = "codec"
+	sendCompressionFlagName     = "send-compression"
+	acceptCompressionFlagName   = "accept-compression"
 
 	// TLS flags
 	keyFlagName        = "key"
@@ -73,10 +89,15 @@ const (
 	connectTimeoutFlagName = "connect-timeout"
 
 	// Header and request body flags
-	userAgentFlagName = "user-agent"
-	headerFlagName    = "header"
-	dataFlagName      = "data"
-	outputFlagName    = "output"
+	userAgentFlagName   = "user-agent"
+	headerFlagName      = "header"
+	dataFlagName        = "data"
+	interactiveFlagName = "interactive"
+	outputFlagName      = "output"
+
+	// Compression values
+	compressionGzip     = "gzip"
+	compressionIdentity = "identity"
 )
 
 // NewCommand returns a new Command.
@@ -105,6 +126,12 @@ during protocol negotiation and HTTP 1.1 used only if the server does not suppor
 The default RPC protocol used will be Connect. To use a different protocol (gRPC or gRPC-Web),
 use the --protocol flag. Note that the gRPC protocol cannot be used with HTTP 1.1.
 
+Request and response messages are encoded with the binary Protobuf format by default. Use
+--codec=json to encode them with the Protobuf JSON format instead, for servers that only support
+JSON. Requests are not compressed unless --send-compression=gzip is set, and the server may
+compress responses with gzip unless --accept-compression is set to identity only. The compression
+that the server used for responses is printed in verbose mode.
+
 The input request is specified via the -d or --data flag. If absent, an empty request is sent. If
 the flag value starts with an at-sign (@), then the rest of the flag value is interpreted as a
 filename from which to read the request body. If that filename is just a dash (-), then the request
@@ -156,6 +183,74 @@ headers and request data are both in a heredoc:
     {"sentence": "If you were a fish, what of fish would you be?."}
     EOM
 
+Instead of invoking an RPC, the schema can be inspected with the --list-services, --list-methods
+and --describe flags. In this case, the positional argument is the base URL of the server, and the
+schema is resolved in the same way as for invoking an RPC, via server reflection or --schema. The
+--list-methods flag prints the methods in the form of the last two path components of an endpoint
+URL. The --describe flag prints the definition of a service, method, message, enum or other
+element in Protobuf source form. The definition of a method is followed by the definitions of its
+request and response messages.
+
+List the services of a server that supports reflection:
+
+    $ buf curl --list-services https://demo.connect.build
+
+Describe a method, where the schema comes from the Buf Schema Registry:
+
+    $ buf curl --schema buf.build/bufbuild/eliza                        \
+         --describe buf.connect.demo.eliza.v1.ElizaService/Introduce  \
+         https://demo.connect.build
+
+The request messages of a bidirectional streaming RPC can also be entered interactively in the
+terminal with the --interactive flag, one JSON message per line, while the response messages are
+printed as they arrive. Field names of the request message are completed with Tab. Enter "/close",
+or press Ctrl-D on an empty line, to close the request stream and wait for the remaining response
+messages:
+
+    $ buf curl --interactive  \
+         https://demo.connect.build/buf.connect.demo.eliza.v1.ElizaService/Converse
+
+Instead of invoking a single RPC, a script of RPCs can be invoked in order with the --script
+flag, such as for smoke tests. In this case, the positional argument is the base URL of the
+server, and the headers, TLS and other flags apply to all RPCs of the script. The script is a
+YAML or JSON file, where each call may capture response fields into variables that later calls
+reference as ${name}, and may assert on the code of the RPC and on response fields. Field paths
+use the JSON names of fields separated by dots, and refer to the last response message. A report
+of the results is printed in JSON, or in JUnit XML with --script-report-format=junit, and this
+command fails if any call fails:
+
+    $ cat smoke.yaml
+    name: eliza
+    headers:
+      - "X-Smoke-Test: true"
+    calls:
+      - method: buf.connect.demo.eliza.v1.ElizaService/Say
+        data:
+          sentence: Hello
+        capture:
+          reply: sentence
+      - method: buf.connect.demo.eliza.v1.ElizaService/Say
+        data:
+          sentence: "Why do you say ${reply}?"
+        expect:
+          code: ok
+    $ buf curl --script smoke.yaml --script-report-format junit https://demo.connect.build
+
+An RPC can be recorded to a JSON file with the --record flag, which includes the request headers
+and messages and the response headers, messages, trailers and code, with messages in the Protobuf
+JSON format. The recording can later be replayed with the --replay flag, such as against another
+environment. In this case, the positional argument is the base URL of the server, and the recorded
+request messages and headers are sent, except for the headers of the protocol and the user agent.
+The Authorization, Cookie, Proxy-Authorization and Set-Cookie headers are never recorded or
+replayed, so credentials must be given again with --header when replaying. The response is
+compared to the recorded response, where headers and trailers are ignored, and this command
+fails with a diff of the responses if they differ. Use --record together with --replay to
+update the recording:
+
+    $ buf curl --data '{"sentence": "Hello"}' --record say.json  \
+         https://demo.connect.build/buf.connect.demo.eliza.v1.ElizaService/Say
+    $ buf curl --replay say.json https://staging.example.com
+
 Note that server reflection (i.e. use of the --reflect flag) does not work with HTTP 1.1 since the
 protocol relies on bidirectional streaming. If server reflection is used, the assumed URL for the
 reflection service is the same as the given URL, but with the last two elements removed and
@@ -165,7 +260,7 @@ If an error occurs that is due to incorrect usage or other unexpected error, thi
 return an exit code that is less than 8. If the RPC fails otherwise, this program will return an
 exit code that is the gRPC code, shifted three bits to the left.
 `,
-		Args: checkPositionalArgs,
+		Args: checkPositionalArgs(flags),
 		Run: builder.NewRunFunc(
 			func(ctx context.Context, container appflag.Container) error {
 				return run(ctx, container, flags)
@@ -185,10 +280,26 @@ type flags struct {
 	ReflectHeaders  []string
 	ReflectProtocol string
 
+	// Flags for inspecting the schema instead of invoking an RPC
+	ListServices bool
+	ListMethods  bool
+	Describe     string
+
+	// Flags for invoking a script of RPCs instead of a single RPC
+	Script             string
+	ScriptReportFormat string
+
+	// Flags for recording an RPC and replaying a recorded RPC
+	Record string
+	Replay string
+
 	// Protocol details
 	Protocol            string
 	UnixSocket          string
 	HTTP2PriorKnowledge bool
+	Codec               string
+	SendCompression     string
+	AcceptCompression   []string
 
 	// TLS
 	Key, Cert, CACert, ServerName string
@@ -201,10 +312,11 @@ type flags struct {
 	ConnectTimeoutSeconds float64
 
 	// Handling request and response data and metadata
-	UserAgent string
-	Headers   []string
-	Data      string
-	Output    string
+	UserAgent   string
+	Headers     []string
+	Data        string
+	Interactive bool
+	Output      string
 
 	// so we can inquire about which flags present on command-line
 	// TODO: ideally we'd use cobra directly instead of having the appcmd wrapper,
@@ -268,6 +380,80 @@ named "grpc.reflection.v1.ServerReflection" and "grpc.reflection.v1alpha.ServerR
 respectively`,
 	)
 
+	flagSet.BoolVar(
+		&f.ListServices,
+		listServicesFlagName,
+		false,
+		`If set, the names of all services in the schema are printed instead of invoking
+an RPC. The positional argument is the base URL of the server`,
+	)
+	flagSet.BoolVar(
+		&f.ListMethods,
+		listMethodsFlagName,
+		false,
+		`If set, the names of all methods in the schema are printed, in the form of the last
+two path components of an endpoint URL, instead of invoking an RPC. The positional
+argument is the base URL of the server`,
+	)
+	flagSet.StringVar(
+		&f.Describe,
+		describeFlagName,
+		"",
+		`The fully-qualified name of a service, method, message, enum or other element in the
+schema whose definition is printed in Protobuf source form instead of invoking an RPC.
+The definition of a method is followed by the definitions of its request and response
+messages. The positional argument is the base URL of the server`,
+	)
+
+	flagSet.StringVar(
+		&f.Script,
+		scriptFlagName,
+		"",
+		`Path to a YAML or JSON file with a script of RPCs to invoke in order, instead of invoking
+a single RPC. The positional argument is the base URL of the server, and the headers,
+TLS and other flags apply to all RPCs of the script. The script can capture fields of
+responses into variables for later RPCs, and assert on the codes and response fields of
+RPCs. A report of the results is printed, and this command fails if any RPC fails`,
+	)
+	flagSet.StringVar(
+		&f.ScriptReportFormat,
+		scriptReportFormatFlagName,
+		bufcurl.ScriptReportFormatJSON.String(),
+		fmt.Sprintf(
+			`The format of the report of a script. This flag may only be used when --%s is set.
+Must be one of %s`,
+			scriptFlagName,
+			stringutil.SliceToHumanStringOrQuoted(bufcurl.AllKnownScriptReportFormatStrings),
+		),
+	)
+
+	flagSet.StringVar(
+		&f.Record,
+		recordFlagName,
+		"",
+		`Path to a JSON file to create with a recording of the RPC. The recording includes the
+method, the request headers and messages, and the response headers, messages, trailers
+and code. Headers with credentials, such as Authorization and Cookie, are not recorded.
+Messages are in the Protobuf JSON format. The recording can be replayed with the --replay
+flag`,
+	)
+	flagSet.StringVar(
+		&f.Replay,
+		replayFlagName,
+		"",
+		fmt.Sprintf(
+			`Path to a recording created with --%s to replay, instead of invoking the RPC defined by
+the flags. The positional argument is the base URL of the server. The recorded request
+messages and headers are sent, and the response is compared to the recorded response.
+The differences are printed, and this command fails if the responses differ. Headers
+given with --%s replace recorded headers of the same name. Headers with credentials are
+never replayed, and must be given with --%s`,
+			recordFlagName,
+			headerFlagName,
+			headerFlagName,
+		),
+	)
+
 	flagSet.StringVar(
 		&f.Protocol,
 		protocolFlagName,
@@ -290,6 +476,28 @@ that HTTP/2 should be used. Without this, HTTP 1.1 will be used with URLs with a
 scheme. For https scheme, HTTP/2 will be negotiate during the TLS handshake if the server
 supports it (otherwise HTTP 1.1 is used)`,
 	)
+	flagSet.StringVar(
+		&f.Codec,
+		codecFlagName,
+		"proto",
+		`The codec used to encode request and response messages. This can be one of "proto" or
+"json". The request data and the output are always in JSON format, regardless of the codec`,
+	)
+	flagSet.StringVar(
+		&f.SendCompression,
+		sendCompressionFlagName,
+		compressionIdentity,
+		`The compression used for request messages. This can be one of "gzip" or "identity". If
+"gzip" is used, it must also be one of the values of --accept-compression`,
+	)
+	flagSet.StringSliceVar(
+		&f.AcceptCompression,
+		acceptCompressionFlagName,
+		[]string{compressionGzip},
+		`The compressions that the server may use for response messages. This flag may be specified
+more than once to indicate multiple compressions. Each value can be one of "gzip" or
+"identity". Responses without compression are always accepted`,
+	)
 
 	flagSet.BoolVar(
 		&f.NoKeepAlive,
@@ -390,6 +598,20 @@ headers flags (--header or -H), the file must contain all headers, then a blank
 then the request body. It is not allowed to indicate stdin if the schema is expected to be
 provided via stdin as a file descriptor set or image`,
 	)
+	flagSet.BoolVar(
+		&f.Interactive,
+		interactiveFlagName,
+		false,
+		fmt.Sprintf(
+			`If set, the request messages of a bidirectional streaming RPC are entered interactively
+in the terminal, one JSON message per line, and the response messages are printed as they
+arrive. Field names can be completed with Tab. Enter "/close" or press Ctrl-D on an empty
+line to close the request stream. This flag cannot be used with --%s or --%s, and stdin must
+be a terminal`,
+			dataFlagName,
+			outputFlagName,
+		),
+	)
 	flagSet.StringVarP(
 		&f.Output,
 		outputFlagName,
@@ -399,7 +621,112 @@ provided via stdin as a file descriptor set or image`,
 	)
 }
 
+// isInspectMode returns true if the schema is inspected instead of invoking an RPC.
+func (f *flags) isInspectMode() bool {
+	return f.ListServices || f.ListMethods || f.flagSet.Changed(describeFlagName)
+}
+
+// isScriptMode returns true if a script of RPCs is invoked instead of a single RPC.
+func (f *flags) isScriptMode() bool {
+	return f.flagSet.Changed(scriptFlagName)
+}
+
+// isReplayMode returns true if a recorded RPC is replayed instead of invoking the RPC
+// defined by the flags.
+func (f *flags) isReplayMode() bool {
+	return f.flagSet.Changed(replayFlagName)
+}
+
+// hasServerURL returns true if the positional argument is the base URL of the server
+// instead of the URL of an endpoint.
+func (f *flags) hasServerURL() bool {
+	return f.isInspectMode() || f.isScriptMode() || f.isReplayMode()
+}
+
 func (f *flags) validate(isSecure bool) error {
+	var inspectFlagNames []string
+	if f.ListServices {
+		inspectFlagNames = append(inspectFlagNames, listServicesFlagName)
+	}
+	if f.ListMethods {
+		inspectFlagNames = append(inspectFlagNames, listMethodsFlagName)
+	}
+	if f.flagSet.Changed(describeFlagName) {
+		if f.Describe == "" {
+			return fmt.Errorf("--%s value cannot be blank", describeFlagName)
+		}
+		inspectFlagNames = append(inspectFlagNames, describeFlagName)
+	}
+	if len(inspectFlagNames) > 1 {
+		return fmt.Errorf(
+			"only one of --%s, --%s and --%s may be specified",
+			listServicesFlagName, listMethodsFlagName, describeFlagName)
+	}
+	if len(inspectFlagNames) == 1 && f.Data != "" {
+		return fmt.Errorf("--%s should not be used with --%s since no RPC is invoked", dataFlagName, inspectFlagNames[0])
+	}
+	if f.isScriptMode() {
+		if f.Script == "" {
+			return fmt.Errorf("--%s value cannot be blank", scriptFlagName)
+		}
+		if len(inspectFlagNames) == 1 {
+			return fmt.Errorf("--%s should not be used with --%s", scriptFlagName, inspectFlagNames[0])
+		}
+		if f.Data != "" {
+			return fmt.Errorf("--%s should not be used with --%s since the script defines the request data", dataFlagName, scriptFlagName)
+		}
+		if _, err := bufcurl.ParseScriptReportFormat(f.ScriptReportFormat); err != nil {
+			return fmt.Errorf(
+				"--%s value must be one of %s",
+				scriptReportFormatFlagName,
+				stringutil.SliceToHumanStringOrQuoted(bufcurl.AllKnownScriptReportFormatStrings),
+			)
+		}
+	} else if f.flagSet.Changed(scriptReportFormatFlagName) {
+		return fmt.Errorf("--%s should not be used unless --%s is set", scriptReportFormatFlagName, scriptFlagName)
+	}
+	if f.Interactive {
+		if len(inspectFlagNames) == 1 {
+			return fmt.Errorf("--%s should not be used with --%s since no RPC is invoked", interactiveFlagName, inspectFlagNames[0])
+		}
+		if f.isScriptMode() {
+			return fmt.Errorf("--%s should not be used with --%s", interactiveFlagName, scriptFlagName)
+		}
+		if f.Data != "" {
+			return fmt.Errorf("--%s should not be used with --%s since request messages are entered in the terminal", dataFlagName, interactiveFlagName)
+		}
+		if f.Output != "" {
+			return fmt.Errorf("--%s should not be used with --%s since response messages are printed in the terminal", outputFlagName, interactiveFlagName)
+		}
+	}
+	if f.flagSet.Changed(recordFlagName) {
+		if f.Record == "" {
+			return fmt.Errorf("--%s value cannot be blank", recordFlagName)
+		}
+		if len(inspectFlagNames) == 1 {
+			return fmt.Errorf("--%s should not be used with --%s since no RPC is invoked", recordFlagName, inspectFlagNames[0])
+		}
+		if f.isScriptMode() {
+			return fmt.Errorf("--%s should not be used with --%s", recordFlagName, scriptFlagName)
+		}
+	}
+	if f.isReplayMode() {
+		if f.Replay == "" {
+			return fmt.Errorf("--%s value cannot be blank", replayFlagName)
+		}
+		if len(inspectFlagNames) == 1 {
+			return fmt.Errorf("--%s should not be used with --%s", replayFlagName, inspectFlagNames[0])
+		}
+		if f.isScriptMode() {
+			return fmt.Errorf("--%s should not be used with --%s", replayFlagName, scriptFlagName)
+		}
+		if f.Interactive {
+			return fmt.Errorf("--%s should not be used with --%s", replayFlagName, interactiveFlagName)
+		}
+		if f.Data != "" {
+			return fmt.Errorf("--%s should not be used with --%s since the recording defines the request data", dataFlagName, replayFlagName)
+		}
+	}
 	if (f.Key != "" || f.Cert != "" || f.CACert != "" || f.ServerName != "" || f.flagSet.Changed(insecureFlagName)) &&
 		!isSecure {
 		return fmt.Errorf(
@@ -439,6 +766,31 @@ func (f *flags) validate(isSecure bool) error {
 			"--%s value must be one of %q, %q, or %q",
 			protocolFlagName, connect.ProtocolConnect, connect.ProtocolGRPC, connect.ProtocolGRPCWeb)
 	}
+	if _, err := bufcurl.ParseCodec(f.Codec); err != nil {
+		return fmt.Errorf(
+			"--%s value must be one of %s",
+			codecFlagName,
+			stringutil.SliceToHumanStringOrQuoted(bufcurl.AllKnownCodecStrings),
+		)
+	}
+	switch f.SendCompression {
+	case compressionGzip, compressionIdentity:
+	default:
+		return fmt.Errorf("--%s value must be one of %q or %q", sendCompressionFlagName, compressionGzip, compressionIdentity)
+	}
+	var acceptsGzip bool
+	for _, acceptCompression := range f.AcceptCompression {
+		switch acceptCompression {
+		case compressionGzip:
+			acceptsGzip = true
+		case compressionIdentity:
+		default:
+			return fmt.Errorf("--%s value must be one of %q or %q", acceptCompressionFlagName, compressionGzip, compressionIdentity)
+		}
+	}
+	if f.SendCompression == compressionGzip && !acceptsGzip {
+		return fmt.Errorf("--%s value %q must also be a value of --%s", sendCompressionFlagName, compressionGzip, acceptCompressionFlagName)
+	}
 
 	if f.NoKeepAlive && f.flagSet.Changed(keepAliveFlagName) {
 		return fmt.Errorf("--%s should not be specified if keepalive is disabled", keepAliveFlagName)
@@ -463,6 +815,16 @@ func (f *flags) validate(isSecure bool) error {
 		return fmt.Errorf("must specify --%s if --%s is false", schemaFlagName, reflectFlagName)
 	}
 	schemaIsStdin := strings.HasPrefix(f.Schema, "-")
+	if f.Interactive {
+		if schemaIsStdin {
+			return fmt.Errorf("--%s and --%s flags cannot both use stdin", schemaFlagName, interactiveFlagName)
+		}
+		for _, header := range append(append([]string{}, f.Headers...), f.ReflectHeaders...) {
+			if header == "@-" {
+				return fmt.Errorf("header flags and --%s flag cannot both use stdin", interactiveFlagName)
+			}
+		}
+	}
 
 	var dataFile string
 	if strings.HasPrefix(f.Data, "@") {
@@ -556,16 +918,44 @@ func verifyEndpointURL(urlArg string) (endpointURL *url.URL, service, method, ba
 	return endpointURL, service, method, baseURL, nil
 }
 
-func checkPositionalArgs(_ *cobra.Command, args []string) error {
-	if len(args) != 1 {
-		return errors.New("expecting exactly one positional argument: the URL of the endpoint to invoke")
+// verifyServerURL verifies the URL of a server whose schema is inspected, and returns
+// it as the base URL for server reflection.
+func verifyServerURL(urlArg string) (serverURL *url.URL, baseURL string, err error) {
+	serverURL, err = url.Parse(urlArg)
+	if err != nil {
+		return nil, "", fmt.Errorf("%q is not a valid server URL: %w", urlArg, err)
+	}
+	if serverURL.Scheme != "http" && serverURL.Scheme != "https" {
+		return nil, "", fmt.Errorf("invalid server URL: sceme %q is not supported", serverURL.Scheme)
+	}
+	return serverURL, strings.TrimSuffix(urlArg, "/"), nil
+}
+
+func checkPositionalArgs(f *flags) cobra.PositionalArgs {
+	return func(_ *cobra.Command, args []string) error {
+		if f.hasServerURL() {
+			if len(args) != 1 {
+				return errors.New("expecting exactly one positional argument: the URL of the server")
+			}
+			_, _, err := verifyServerURL(args[0])
+			return err
+		}
+		if len(args) != 1 {
+			return errors.New("expecting exactly one positional argument: the URL of the endpoint to invoke")
+		}
+		_, _, _, _, err := verifyEndpointURL(args[0])
+		return err
 	}
-	_, _, _, _, err := verifyEndpointURL(args[0])
-	return err
 }
 
 func run(ctx context.Context, container appflag.Container, f *flags) (err error) {
-	endpointURL, service, method, baseURL, err := verifyEndpointURL(container.Arg(0))
+	var endpointURL *url.URL
+	var service, method, baseURL string
+	if f.hasServerURL() {
+		endpointURL, baseURL, err = verifyServerURL(container.Arg(0))
+	} else {
+		endpointURL, service, method, baseURL, err = verifyEndpointURL(container.Arg(0))
+	}
 	if err != nil {
 		return err
 	}
@@ -573,6 +963,44 @@ func run(ctx context.Context, container appflag.Container, f *flags) (err error)
 	if err := f.validate(isSecure); err != nil {
 		return err
 	}
+	var script *bufcurl.Script
+	if f.isScriptMode() {
+		data, err := os.ReadFile(f.Script)
+		if err != nil {
+			return bufcurl.ErrorHasFilename(err, f.Script)
+		}
+		script, err = bufcurl.ReadScript(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Script, err)
+		}
+		if script.Name == "" {
+			script.Name = f.Script
+		}
+	}
+	var replayedRecording *bufcurl.Recording
+	if f.isReplayMode() {
+		data, err := os.ReadFile(f.Replay)
+		if err != nil {
+			return bufcurl.ErrorHasFilename(err, f.Replay)
+		}
+		replayedRecording, err = bufcurl.ReadRecording(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Replay, err)
+		}
+		service, method, _ = strings.Cut(replayedRecording.Method, "/")
+	}
+	verbosePrinter := container.VerbosePrinter()
+	var terminal *bufcurl.InteractiveTerminal
+	if f.Interactive {
+		terminal, err = bufcurl.NewInteractiveTerminal(container)
+		if err != nil {
+			if errors.Is(err, bufcurl.ErrNotATerminal) {
+				return fmt.Errorf("--%s requires stdin to be a terminal", interactiveFlagName)
+			}
+			return err
+		}
+		verbosePrinter = terminal.VerbosePrinter()
+	}
 
 	var clientOptions []connect.ClientOption
 	switch f.Protocol {
@@ -587,7 +1015,7 @@ func run(ctx context.Context, container appflag.Container, f *flags) (err error)
 		// in an end-of-stream message for streaming calls. So this interceptor
 		// will print the trailers for streaming calls when the response stream
 		// is drained.
-		clientOptions = append(clientOptions, connect.WithInterceptors(bufcurl.TraceTrailersInterceptor(container.VerbosePrinter())))
+		clientOptions = append(clientOptions, connect.WithInterceptors(bufcurl.TraceTrailersInterceptor(verbosePrinter)))
 	}
 
 	dataSource := "(argument)"
@@ -607,6 +1035,16 @@ func run(ctx context.Context, container appflag.Container, f *flags) (err error)
 	if err != nil {
 		return err
 	}
+	if replayedRecording != nil {
+		// The recorded headers are sent, unless they are replaced by the header flags.
+		for key, values := range replayedRecording.ReplayHeaders() {
+			if len(requestHeaders.Values(key)) == 0 {
+				requestHeaders[key] = values
+			}
+		}
+		dataSource = f.Replay
+		dataReader = io.NopCloser(strings.NewReader(replayedRecording.ReplayData()))
+	}
 	if len(requestHeaders.Values("user-agent")) == 0 {
 		userAgent := f.UserAgent
 		if userAgent == "" {
@@ -634,7 +1072,7 @@ func run(ctx context.Context, container appflag.Container, f *flags) (err error)
 		}
 	}()
 
-	transport, err := makeHTTPClient(f, isSecure, bufcurl.GetAuthority(endpointURL, requestHeaders), container.VerbosePrinter())
+	transport, err := makeHTTPClient(f, isSecure, bufcurl.GetAuthority(endpointURL, requestHeaders), verbosePrinter)
 	if err != nil {
 		return err
 	}
@@ -658,7 +1096,7 @@ func run(ctx context.Context, container appflag.Container, f *flags) (err error)
 			return err
 		}
 		var closeRes func()
-		res, closeRes = bufcurl.NewServerReflectionResolver(ctx, transport, clientOptions, baseURL, reflectProtocol, reflectHeaders, container.VerbosePrinter())
+		res, closeRes = bufcurl.NewServerReflectionResolver(ctx, transport, clientOptions, baseURL, reflectProtocol, reflectHeaders, verbosePrinter)
 		defer closeRes()
 	} else {
 		ref, err := buffetch.NewRefParser(container.Logger(), buffetch.RefParserWithProtoFileRefAllowed()).GetRef(ctx, f.Schema)
@@ -714,14 +1152,171 @@ func run(ctx context.Context, container appflag.Container, f *flags) (err error)
 		}
 	}
 
+	if f.isInspectMode() {
+		return inspect(f, res, output)
+	}
+
+	// The codec and compression only apply to the RPC, not to reflection requests.
+	codec, err := bufcurl.ParseCodec(f.Codec)
+	if err != nil {
+		return err
+	}
+	invokerClientOptions := append([]connect.ClientOption{}, clientOptions...)
+	invokerClientOptions = append(invokerClientOptions, compressionClientOptions(f)...)
+	verbosePrinter.Printf("* Using codec %s and request compression %s\n", codec.String(), f.SendCompression)
+
+	if script != nil {
+		report := bufcurl.RunScript(
+			ctx,
+			container,
+			script,
+			res,
+			transport,
+			invokerClientOptions,
+			baseURL,
+			requestHeaders,
+			bufcurl.InvokerWithCodec(codec),
+		)
+		scriptReportFormat, err := bufcurl.ParseScriptReportFormat(f.ScriptReportFormat)
+		if err != nil {
+			return err
+		}
+		if err := bufcurl.WriteScriptReport(output, report, scriptReportFormat); err != nil {
+			return err
+		}
+		if numFailed := report.NumFailed(); numFailed > 0 {
+			return fmt.Errorf("%d of %d calls of script %s failed", numFailed, len(report.Calls), f.Script)
+		}
+		return nil
+	}
+
 	methodDescriptor, err := bufcurl.ResolveMethodDescriptor(res, service, method)
 	if err != nil {
 		return err
 	}
+	invokerOptions := []bufcurl.InvokerOption{
+		bufcurl.InvokerWithCodec(codec),
+	}
+	if terminal != nil {
+		if !methodDescriptor.IsStreamingClient() || !methodDescriptor.IsStreamingServer() {
+			return fmt.Errorf("--%s can only be used with bidirectional streaming methods", interactiveFlagName)
+		}
+		invokerOptions = append(invokerOptions, bufcurl.InvokerWithInteractiveTerminal(terminal))
+	}
+	var recording *bufcurl.Recording
+	if f.Record != "" || replayedRecording != nil {
+		recording = &bufcurl.Recording{Protocol: f.Protocol}
+		invokerOptions = append(invokerOptions, bufcurl.InvokerWithRecording(recording))
+	}
+	endpoint := container.Arg(0)
+	if replayedRecording != nil {
+		endpoint = baseURL + "/" + replayedRecording.Method
+	}
 
 	// Now we can finally issue the RPC
-	invoker := bufcurl.NewInvoker(container, methodDescriptor, res, transport, clientOptions, container.Arg(0), output)
-	return invoker.Invoke(ctx, dataSource, dataReader, requestHeaders)
+	invoker := bufcurl.NewInvoker(
+		container,
+		methodDescriptor,
+		res,
+		transport,
+		invokerClientOptions,
+		endpoint,
+		output,
+		invokerOptions...,
+	)
+	invokeErr := invoker.Invoke(ctx, dataSource, dataReader, requestHeaders)
+	if recording == nil || (invokeErr != nil && recording.Code == 0) {
+		// The RPC is only recorded if it completed, with or without an RPC error.
+		return invokeErr
+	}
+	if f.Record != "" {
+		if err := writeRecording(f.Record, recording); err != nil {
+			return err
+		}
+	}
+	if replayedRecording != nil {
+		diffData, err := bufcurl.DiffRecordings(ctx, command.NewRunner(), res, replayedRecording, recording, f.Replay, "(replay)")
+		if err != nil {
+			return err
+		}
+		if len(diffData) > 0 {
+			if _, err := container.Stderr().Write(diffData); err != nil {
+				return err
+			}
+			return fmt.Errorf("response differs from the response recorded in %s", f.Replay)
+		}
+		// An RPC error is expected if it was also recorded.
+		return nil
+	}
+	return invokeErr
+}
+
+// writeRecording writes the recording to the file at the given path.
+func writeRecording(path string, recording *bufcurl.Recording) (retErr error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return bufcurl.ErrorHasFilename(err, path)
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, file.Close())
+	}()
+	return bufcurl.WriteRecording(file, recording)
+}
+
+// compressionClientOptions returns the options for the compression flags.
+//
+// Clients accept gzip compressed responses by default, so we only have to remove gzip
+// if it is not accepted.
+func compressionClientOptions(f *flags) []connect.ClientOption {
+	var clientOptions []connect.ClientOption
+	var acceptsGzip bool
+	for _, acceptCompression := range f.AcceptCompression {
+		if acceptCompression == compressionGzip {
+			acceptsGzip = true
+		}
+	}
+	if !acceptsGzip {
+		clientOptions = append(clientOptions, connect.WithAcceptCompression(compressionGzip, nil, nil))
+	}
+	if f.SendCompression == compressionGzip {
+		clientOptions = append(clientOptions, connect.WithSendGzip())
+	}
+	return clientOptions
+}
+
+// inspect prints the part of the schema requested by the flags to the output.
+func inspect(f *flags, res bufcurl.Resolver, output io.Writer) error {
+	switch {
+	case f.ListServices:
+		serviceNames, err := bufcurl.ListServices(res)
+		if err != nil {
+			return err
+		}
+		for _, serviceName := range serviceNames {
+			if _, err := fmt.Fprintln(output, serviceName); err != nil {
+				return err
+			}
+		}
+		return nil
+	case f.ListMethods:
+		methodDescriptors, err := bufcurl.ListMethods(res)
+		if err != nil {
+			return err
+		}
+		for _, methodDescriptor := range methodDescriptors {
+			if _, err := fmt.Fprintf(output, "%s/%s\n", methodDescriptor.Parent().FullName(), methodDescriptor.Name()); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		definition, err := bufcurl.Describe(res, f.Describe)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(output, definition)
+		return err
+	}
 }
 
 func makeHTTPClient(f *flags, isSecure bool, authority string, printer verbose.Printer) (connect.HTTPClient, error) {
@@ -495,6 +495,26 @@ func formatModule(
 		if _, err := io.Copy(container.Stdout(), diffBuffer); err != nil {
 			return false, err
 		}
+		if diffPresent && errorFormat == bufanalysis.FormatGitHubActions.String() {
+			// Annotate the unformatted files so that they show up on the pull request.
+			// We only do this for the github-actions format, as the other formats
+			// only apply to build errors.
+			fileAnnotations, err := fileAnnotationsForUnformattedFiles(
+				ctx,
+				originalReadWriteBucket,
+				formattedReadBucket,
+			)
+			if err != nil {
+				return false, err
+			}
+			if err := bufanalysis.PrintFileAnnotations(
+				container.Stderr(),
+				fileAnnotations,
+				errorFormat,
+			); err != nil {
+				return false, err
+			}
+		}
 		if outputDirectory == "" && singleFileOutputFilename == "" && !rewrite {
 			// If the user specified --diff and has not explicitly overridden
 			// the --output or rewritten the sources in-place with --write, we
@@ -621,3 +641,71 @@ func formatModule(
 	}
 	return diffPresent, nil
 }
+
+// fileAnnotationsForUnformattedFiles returns a FileAnnotation for each file in the
+// originalReadBucket that differs from its formatted content in the formattedReadBucket.
+//
+// The FileAnnotation points at the first line that differs.
+func fileAnnotationsForUnformattedFiles(
+	ctx context.Context,
+	originalReadBucket storage.ReadBucket,
+	formattedReadBucket storage.ReadBucket,
+) ([]bufanalysis.FileAnnotation, error) {
+	var fileAnnotations []bufanalysis.FileAnnotation
+	if err := storage.WalkReadObjects(
+		ctx,
+		originalReadBucket,
+		"",
+		func(readObject storage.ReadObject) error {
+			originalData, err := io.ReadAll(readObject)
+			if err != nil {
+				return err
+			}
+			formattedObjectInfo, err := formattedReadBucket.Stat(ctx, readObject.Path())
+			if err != nil {
+				return err
+			}
+			formattedData, err := storage.ReadPath(ctx, formattedReadBucket, readObject.Path())
+			if err != nil {
+				return err
+			}
+			if bytes.Equal(originalData, formattedData) {
+				return nil
+			}
+			line := firstDifferentLine(originalData, formattedData)
+			fileAnnotations = append(
+				fileAnnotations,
+				bufanalysis.NewFileAnnotation(
+					formattedObjectInfo,
+					line,
+					0,
+					line,
+					0,
+					"FORMAT",
+					"File is not formatted. Run buf format -w to format it.",
+				),
+			)
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+	bufanalysis.SortFileAnnotations(fileAnnotations)
+	return fileAnnotations, nil
+}
+
+// firstDifferentLine returns the 1-indexed line number of the first line
+// that differs between the two given contents.
+func firstDifferentLine(original []byte, formatted []byte) int {
+	originalLines := bytes.Split(original, []byte("\n"))
+	formattedLines := bytes.Split(formatted, []byte("\n"))
+	for i := 0; i < len(originalLines) && i < len(formattedLines); i++ {
+		if !bytes.Equal(originalLines[i], formattedLines[i]) {
+			return i + 1
+		}
+	}
+	if len(originalLines) < len(formattedLines) {
+		return len(originalLines)
+	}
+	return len(formattedLines)
+}
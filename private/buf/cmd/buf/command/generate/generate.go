@@ -16,11 +16,13 @@ package generate
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/buf/bufgen"
+	"github.com/bufbuild/buf/private/buf/bufwire"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
@@ -46,6 +48,11 @@ const (
 	disableSymlinksFlagName     = "disable-symlinks"
 	typeFlagName                = "type"
 	typeDeprecatedFlagName      = "include-types"
+	cleanFlagName               = "clean"
+	checkFlagName               = "check"
+	disableCacheFlagName        = "disable-cache"
+	maxParallelPluginsFlagName  = "max-parallel-plugins"
+	manifestFlagName            = "manifest"
 )
 
 // NewCommand returns a new Command.
@@ -62,7 +69,7 @@ func NewCommand(
     # buf.gen.yaml
     # The version of the generation template.
     # Required.
-    # The valid values are v1beta1, v1.
+    # The valid values are v1beta1, v1, v2.
     version: v1
     # The plugins to run. "plugin" is required.
     plugins:
@@ -79,6 +86,9 @@ func NewCommand(
         # Optional.
         opt: paths=source_relative
         # The custom path to the plugin binary, if not protoc-gen-NAME on your $PATH.
+        # If the path ends in .wasm, the plugin is a WebAssembly plugin compiled for WASI,
+        # and is run in-process without access to the filesystem, the network or the
+        # environment variables.
         # Optional, and exclusive with "remote".
         path: custom-gen-go
         # The generation strategy to use. There are two options:
@@ -107,6 +117,31 @@ func NewCommand(
         # If omitted, "directory" is used. Most users should not need to set this option.
         # Optional.
         strategy: directory
+        # The maximum duration of every invocation of the plugin, such as 30s or 5m.
+        # With the "directory" strategy, the timeout applies to the invocation for each
+        # directory separately. An invocation that takes longer fails buf generate.
+        # Optional, and only valid for local plugins. If omitted, plugins do not time out.
+        timeout: 5m
+        # Whether to delete the files in the out directory that a previous buf generate
+        # produced, but are no longer produced, for example after a .proto file is deleted.
+        # The generated files are recorded in a .buf.gen.manifest file in the out directory,
+        # and files that are not recorded there are never deleted.
+        # Optional, and has no effect for .jar and .zip outputs. Ignored with --path,
+        # --exclude-path or --type, as these only generate a subset of the files.
+        clean: true
+        # Only generate for the files in these directories or files, relative to the root
+        # of the input, and for the files that are not in the exclude_paths. These are applied
+        # in addition to --path and --exclude-path.
+        # Optional.
+        include_paths:
+          - proto/foo
+        exclude_paths:
+          - proto/foo/internal
+        # Only generate for these types and their dependencies. This is applied in addition
+        # to --type.
+        # Optional.
+        types:
+          - foo.v1.FooService
       - plugin: java
         out: gen/java
         # Use the plugin hosted at buf.build/protocolbuffers/python at version v21.9.
@@ -134,6 +169,36 @@ for the set of plugins you want to invoke.
 The first argument is the source, module, or image to generate from.
 Defaults to "." if no argument is specified.
 
+A template with version v2 has the same shape as a v1 template, and can additionally
+declare the inputs to generate from, so that no argument needs to be specified:
+
+    # buf.gen.yaml
+    version: v2
+    plugins:
+      - plugin: go
+        out: gen/go
+    # The inputs to generate from. These are ignored if an argument is specified.
+    inputs:
+        # The source, module, or image, in the same format as the first argument.
+        # Required.
+      - input: https://github.com/foo/bar.git#branch=main
+        # Only generate for these paths and not for the exclude_paths, like --path
+        # and --exclude-path. Overridden by --path and --exclude-path.
+        # Optional.
+        paths:
+          - proto/foo
+        exclude_paths:
+          - proto/foo/internal
+        # Only generate for these types, like --type. Overridden by --type.
+        # Optional.
+        types:
+          - foo.v1.Foo
+      - input: ../protos
+
+All inputs are built, and the plugins are invoked once with all of the files of all inputs.
+As for the first argument, relative paths of local inputs are interpreted as relative to the
+current directory, not to the directory of the template.
+
 Use buf.gen.yaml as template, current directory as input:
 
     $ buf generate
@@ -180,6 +245,42 @@ has a per-directory parallel invocation, with results from each invocation combi
 before writing the result.
 
 Insertion points are processed in the order the plugins are specified in the template.
+
+To check that the generated files on disk are up to date without writing anything, for
+example in CI:
+
+    $ buf generate --check
+
+This prints a diff of the generated files that are out of date to stdout, and exits with
+a non-zero exit code if there are any. Files in the out directories that are not produced
+by the plugins are ignored, unless they would be deleted by clean.
+
+The responses of local plugins are cached in the buf cache directory, keyed by a digest of
+the inputs to the plugin, the plugin binary and the plugin options. If none of these changed
+since an earlier invocation, the plugin is not invoked again. The cache statistics are printed
+with --verbose. Use --disable-cache to always invoke the plugins, for example for plugins that
+depend on state other than their inputs. Remote plugins, and local plugins with a path with
+arguments such as [sh, gen.sh], are never cached, as the code they run is not part of the key.
+
+Build systems that need to know which files were generated from which inputs can ask for a
+generation manifest in every out directory with --manifest:
+
+    $ buf generate --manifest json
+
+This writes buf.gen.outputs.json (or buf.gen.outputs.yaml with --manifest yaml) to the out
+directory of every plugin. The manifest lists the inputs, the plugins that generated files in
+the out directory with the source files of every request sent to them, and the generated files
+with their shake256 digests, the plugins that generated them and the source files of the request
+they were generated for. Plugin versions are only recorded for remote plugins that pin a version,
+and the shake256 digests of the plugin binaries only for local plugins with a path without
+arguments. Plugins that write to a .jar or .zip archive are not included, and no manifests are
+written with --check.
+
+The generation manifest is separate from the .buf.gen.manifest file written for clean. The
+generation manifest is only written with --manifest, and is only read by other tools. The
+.buf.gen.manifest file is written whenever clean is enabled, only lists the generated files, and
+is what buf generate reads to decide which files to delete, so it must not depend on whether or
+in which format a generation manifest is requested.
 `,
 		Args: cobra.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
@@ -207,6 +308,12 @@ type flags struct {
 	// want to find out what will break if we do.
 	Types           []string
 	TypesDeprecated []string
+	Clean           bool
+	Check           bool
+	DisableCache    bool
+	// MaxParallelPlugins is not limited if <1.
+	MaxParallelPlugins int
+	Manifest           string
 	// special
 	InputHashtag string
 }
@@ -275,6 +382,41 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 		nil,
 		"The types (message, enum, service) that should be included in this image. When specified, the resulting image will only include descriptors to describe the requested types. Flag usage overrides buf.gen.yaml",
 	)
+	flagSet.BoolVar(
+		&f.Clean,
+		cleanFlagName,
+		false,
+		fmt.Sprintf(
+			"Delete the previously generated files that are no longer generated from the out directories of all plugins. Equivalent to setting clean on every plugin in the generation template. Cannot be set with --%s, --%s or --%s",
+			pathsFlagName,
+			excludePathsFlagName,
+			typeFlagName,
+		),
+	)
+	flagSet.BoolVar(
+		&f.Check,
+		checkFlagName,
+		false,
+		"Check that the generated files on disk are up to date instead of writing them. Prints a diff to stdout and exits with a non-zero exit code if they are not",
+	)
+	flagSet.BoolVar(
+		&f.DisableCache,
+		disableCacheFlagName,
+		false,
+		"Always invoke local plugins instead of reusing the cached responses of earlier invocations with the same inputs",
+	)
+	flagSet.IntVar(
+		&f.MaxParallelPlugins,
+		maxParallelPluginsFlagName,
+		0,
+		"The maximum number of local plugin invocations to run at once. Plugins with the directory strategy are invoked once per directory. If unset, the number of local plugin invocations is not limited beyond the number of CPUs per plugin",
+	)
+	flagSet.StringVar(
+		&f.Manifest,
+		manifestFlagName,
+		"",
+		`Write a generation manifest to the out directory of every plugin, in the given format. Must be one of "json" or "yaml"`,
+	)
 	_ = flagSet.MarkDeprecated(typeDeprecatedFlagName, fmt.Sprintf("Use --%s instead", typeFlagName))
 	_ = flagSet.MarkHidden(typeDeprecatedFlagName)
 }
@@ -295,11 +437,7 @@ func run(
 	if err := bufcli.ValidateErrorFormatFlag(flags.ErrorFormat, errorFormatFlagName); err != nil {
 		return err
 	}
-	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
-	if err != nil {
-		return err
-	}
-	ref, err := buffetch.NewRefParser(container.Logger(), buffetch.RefParserWithProtoFileRefAllowed()).GetRef(ctx, input)
+	input, err := bufcli.GetInputValue(container, flags.InputHashtag, "")
 	if err != nil {
 		return err
 	}
@@ -322,6 +460,44 @@ func run(
 	if err != nil {
 		return err
 	}
+	// The files generated with --path, --exclude-path or --type are only a subset of the
+	// files that are generated without them, so cleaning would delete the files that were
+	// generated for the files and types that were filtered out.
+	if len(flags.Paths) > 0 || len(flags.ExcludePaths) > 0 || len(flags.Types) > 0 || len(flags.TypesDeprecated) > 0 {
+		if flags.Clean {
+			return appcmd.NewInvalidArgumentErrorf(
+				"--%s cannot be used with --%s, --%s or --%s",
+				cleanFlagName,
+				pathsFlagName,
+				excludePathsFlagName,
+				typeFlagName,
+			)
+		}
+		for _, pluginConfig := range genConfig.PluginConfigs {
+			if pluginConfig.Clean {
+				logger.Sugar().Warnf(
+					"plugin %s is not cleaned, as clean cannot be used with --%s, --%s or --%s",
+					pluginConfig.PluginName(),
+					pathsFlagName,
+					excludePathsFlagName,
+					typeFlagName,
+				)
+				pluginConfig.Clean = false
+			}
+		}
+	}
+	inputConfigs := genConfig.InputConfigs
+	if input != "" || len(inputConfigs) == 0 {
+		// An input on the command line takes precedence over the inputs in the template.
+		if input == "" {
+			input = "."
+		}
+		inputConfigs = []*bufgen.InputConfig{
+			{
+				InputRef: input,
+			},
+		}
+	}
 	clientConfig, err := bufcli.NewConnectClientConfig(container)
 	if err != nil {
 		return err
@@ -335,28 +511,20 @@ func run(
 	if err != nil {
 		return err
 	}
-	imageConfigs, fileAnnotations, err := imageConfigReader.GetImageConfigs(
-		ctx,
-		container,
-		ref,
-		flags.Config,
-		flags.Paths,        // we filter on files
-		flags.ExcludePaths, // we exclude these paths
-		false,              // input files must exist
-		false,              // we must include source info for generation
-	)
-	if err != nil {
-		return err
-	}
-	if len(fileAnnotations) > 0 {
-		if err := bufanalysis.PrintFileAnnotations(container.Stderr(), fileAnnotations, flags.ErrorFormat); err != nil {
+	images := make([]bufimage.Image, 0, len(inputConfigs))
+	for _, inputConfig := range inputConfigs {
+		image, err := getInputImage(
+			ctx,
+			container,
+			imageConfigReader,
+			flags,
+			genConfig,
+			inputConfig,
+		)
+		if err != nil {
 			return err
 		}
-		return bufcli.ErrFileAnnotation
-	}
-	images := make([]bufimage.Image, 0, len(imageConfigs))
-	for _, imageConfig := range imageConfigs {
-		images = append(images, imageConfig.Image())
+		images = append(images, image)
 	}
 	image, err := bufimage.MergeImages(images...)
 	if err != nil {
@@ -377,29 +545,136 @@ func run(
 			bufgen.GenerateWithIncludeWellKnownTypes(),
 		)
 	}
-	var includedTypes []string
-	if len(flags.Types) > 0 || len(flags.TypesDeprecated) > 0 {
-		// command-line flags take precedence
-		includedTypes = append(flags.Types, flags.TypesDeprecated...)
-	} else if genConfig.TypesConfig != nil {
-		includedTypes = genConfig.TypesConfig.Include
+	if flags.Clean {
+		generateOptions = append(
+			generateOptions,
+			bufgen.GenerateWithClean(),
+		)
 	}
-	if len(includedTypes) > 0 {
-		image, err = bufimageutil.ImageFilteredByTypes(image, includedTypes...)
+	if flags.Check {
+		generateOptions = append(
+			generateOptions,
+			bufgen.GenerateWithCheck(container.Stdout()),
+		)
+	}
+	if flags.Manifest != "" {
+		manifestFormat, err := bufgen.ParseManifestFormat(flags.Manifest)
+		if err != nil {
+			return appcmd.NewInvalidArgumentErrorf("--%s: %v", manifestFlagName, err)
+		}
+		inputRefs := make([]string, 0, len(inputConfigs))
+		for _, inputConfig := range inputConfigs {
+			inputRefs = append(inputRefs, inputConfig.InputRef)
+		}
+		generateOptions = append(
+			generateOptions,
+			bufgen.GenerateWithManifest(manifestFormat, inputRefs...),
+		)
+	}
+	if flags.MaxParallelPlugins < 0 {
+		return appcmd.NewInvalidArgumentErrorf("--%s cannot be negative", maxParallelPluginsFlagName)
+	}
+	generatorOptions := []bufgen.GeneratorOption{
+		bufgen.GeneratorWithVerbosePrinter(container.VerbosePrinter()),
+		bufgen.GeneratorWithMaxParallelPlugins(flags.MaxParallelPlugins),
+	}
+	// The cache directory is empty if neither $BUF_CACHE_DIR nor $HOME are set, in which
+	// case we do not cache instead of writing the cache to the current directory.
+	if !flags.DisableCache && container.CacheDirPath() != "" {
+		cacheReadWriteBucket, err := bufcli.NewGenerateCacheBucketAndCreateCacheDirs(container)
 		if err != nil {
 			return err
 		}
+		generatorOptions = append(
+			generatorOptions,
+			bufgen.GeneratorWithCacheBucket(cacheReadWriteBucket),
+		)
 	}
-	return bufgen.NewGenerator(
+	if err := bufgen.NewGenerator(
 		logger,
 		storageosProvider,
 		runner,
 		clientConfig,
+		generatorOptions...,
 	).Generate(
 		ctx,
 		container,
 		genConfig,
 		image,
 		generateOptions...,
+	); err != nil {
+		if errors.Is(err, bufgen.ErrCheckFailed) {
+			return bufcli.ErrFileAnnotation
+		}
+		return err
+	}
+	return nil
+}
+
+// getInputImage builds the image for the input.
+//
+// The paths, exclude paths and types on the command line take precedence over the paths,
+// exclude paths and types of the input, and the types of the input take precedence over
+// the types of the template.
+func getInputImage(
+	ctx context.Context,
+	container appflag.Container,
+	imageConfigReader bufwire.ImageConfigReader,
+	flags *flags,
+	genConfig *bufgen.Config,
+	inputConfig *bufgen.InputConfig,
+) (bufimage.Image, error) {
+	ref, err := buffetch.NewRefParser(container.Logger(), buffetch.RefParserWithProtoFileRefAllowed()).GetRef(ctx, inputConfig.InputRef)
+	if err != nil {
+		return nil, err
+	}
+	paths := inputConfig.Paths
+	excludePaths := inputConfig.ExcludePaths
+	if len(flags.Paths) > 0 || len(flags.ExcludePaths) > 0 {
+		paths = flags.Paths
+		excludePaths = flags.ExcludePaths
+	}
+	imageConfigs, fileAnnotations, err := imageConfigReader.GetImageConfigs(
+		ctx,
+		container,
+		ref,
+		flags.Config,
+		paths,        // we filter on files
+		excludePaths, // we exclude these paths
+		false,        // input files must exist
+		false,        // we must include source info for generation
 	)
+	if err != nil {
+		return nil, err
+	}
+	if len(fileAnnotations) > 0 {
+		if err := bufanalysis.PrintFileAnnotations(container.Stderr(), fileAnnotations, flags.ErrorFormat); err != nil {
+			return nil, err
+		}
+		return nil, bufcli.ErrFileAnnotation
+	}
+	images := make([]bufimage.Image, 0, len(imageConfigs))
+	for _, imageConfig := range imageConfigs {
+		images = append(images, imageConfig.Image())
+	}
+	image, err := bufimage.MergeImages(images...)
+	if err != nil {
+		return nil, err
+	}
+	var includedTypes []string
+	if len(flags.Types) > 0 || len(flags.TypesDeprecated) > 0 {
+		// command-line flags take precedence
+		includedTypes = append(flags.Types, flags.TypesDeprecated...)
+	} else if len(inputConfig.Types) > 0 {
+		includedTypes = inputConfig.Types
+	} else if genConfig.TypesConfig != nil {
+		includedTypes = genConfig.TypesConfig.Include
+	}
+	if len(includedTypes) > 0 {
+		image, err = bufimageutil.ImageFilteredByTypes(image, includedTypes...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return image, nil
 }
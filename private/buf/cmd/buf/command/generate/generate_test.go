@@ -150,6 +150,41 @@ func TestOutputFlag(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestTemplateInputsRelativeToCurrentDirectory(t *testing.T) {
+	tempDirPath := t.TempDir()
+	templateDirPath := t.TempDir()
+	templatePath := filepath.Join(templateDirPath, "buf.gen.yaml")
+	// Relative inputs are resolved against the current directory, not the directory of the template.
+	require.NoError(
+		t,
+		os.WriteFile(
+			templatePath,
+			[]byte(
+				fmt.Sprintf(
+					`version: v2
+plugins:
+  - name: java
+    out: java
+inputs:
+  - input: %s
+`,
+					filepath.ToSlash(filepath.Join("testdata", "simple")),
+				),
+			),
+			0600,
+		),
+	)
+	testRunSuccess(
+		t,
+		"--output",
+		tempDirPath,
+		"--template",
+		templatePath,
+	)
+	_, err := os.Stat(filepath.Join(tempDirPath, "java", "a", "v1", "A.java"))
+	require.NoError(t, err)
+}
+
 func TestProtoFileRefIncludePackageFiles(t *testing.T) {
 	tempDirPath := t.TempDir()
 	testRunSuccess(
@@ -202,6 +237,34 @@ func TestOutputWithPathEqualToExclude(t *testing.T) {
 	)
 }
 
+func TestCleanWithPathFail(t *testing.T) {
+	tempDirPath := t.TempDir()
+	stderr := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandExitCode(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+			)
+		},
+		1,
+		internaltesting.NewEnvFunc(t),
+		nil,
+		nil,
+		stderr,
+		"--output",
+		tempDirPath,
+		"--template",
+		filepath.Join("testdata", "paths", "buf.gen.yaml"),
+		"--clean",
+		"--path",
+		filepath.Join("testdata", "paths", "a", "v1", "a.proto"),
+		filepath.Join("testdata", "paths"),
+	)
+	assert.Contains(t, stderr.String(), "Failure: --clean cannot be used with --path, --exclude-path or --type")
+}
+
 func TestGenerateInsertionPoint(t *testing.T) {
 	t.Parallel()
 	runner := command.NewRunner()
@@ -16,21 +16,32 @@
 package lint
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/buf/buflintfix"
+	"github.com/bufbuild/buf/private/buf/bufwire"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appflag"
 	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"go.uber.org/multierr"
 )
 
 const (
@@ -39,6 +50,10 @@ const (
 	pathsFlagName           = "path"
 	excludePathsFlagName    = "exclude-path"
 	disableSymlinksFlagName = "disable-symlinks"
+	fixFlagName             = "fix"
+	diffFlagName            = "diff"
+	diffFlagShortName       = "d"
+	allowPluginsFlagName    = "allow-plugins"
 )
 
 // NewCommand returns a new Command.
@@ -50,8 +65,21 @@ func NewCommand(
 	return &appcmd.Command{
 		Use:   name + " <input>",
 		Short: "Run linting on Protobuf files",
-		Long:  bufcli.GetInputLong(`the source, module, or Image to lint`),
-		Args:  cobra.MaximumNArgs(1),
+		Long: bufcli.GetInputLong(`the source, module, or Image to lint`) + `
+
+Use the --fix flag to rewrite the source files in-place with the machine-applicable fixes
+for the violations of the ` + stringutil.SliceToHumanString(buflintfix.FixableIDs) + ` rules.
+Fixed files are also formatted as with buf format. Only the violations that could not be
+fixed are printed.
+
+Use the --diff flag with --fix to display a diff of the fixes instead of rewriting the files.
+The command then fails if the diff is not empty, as the files still have the violations.
+
+The check plugins configured in the lint section of the configuration are arbitrary commands,
+so they are only run with the --allow-plugins flag, and only if the configuration is read
+from a local directory or proto file, or is set with --config. Linting fails if rules provided
+by a plugin are in use but plugins are not run.`,
+		Args: cobra.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
 			func(ctx context.Context, container appflag.Container) error {
 				return run(ctx, container, flags)
@@ -68,6 +96,9 @@ type flags struct {
 	Paths           []string
 	ExcludePaths    []string
 	DisableSymlinks bool
+	Fix             bool
+	Diff            bool
+	AllowPlugins    bool
 	// special
 	InputHashtag string
 }
@@ -96,6 +127,28 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 		"",
 		`The file or data to use for configuration`,
 	)
+	flagSet.BoolVar(
+		&f.Fix,
+		fixFlagName,
+		false,
+		"Rewrite source files in-place to fix the violations that have machine-applicable fixes",
+	)
+	flagSet.BoolVarP(
+		&f.Diff,
+		diffFlagName,
+		diffFlagShortName,
+		false,
+		fmt.Sprintf("Display a diff of the fixes instead of rewriting files. Requires --%s", fixFlagName),
+	)
+	flagSet.BoolVar(
+		&f.AllowPlugins,
+		allowPluginsFlagName,
+		false,
+		fmt.Sprintf(
+			"Run the check plugins in the configuration. Requires a local input or --%s",
+			configFlagName,
+		),
+	)
 }
 
 func run(
@@ -106,6 +159,9 @@ func run(
 	if err := bufcli.ValidateErrorFormatFlagLint(flags.ErrorFormat, errorFormatFlagName); err != nil {
 		return err
 	}
+	if flags.Diff && !flags.Fix {
+		return appcmd.NewInvalidArgumentErrorf("--%s requires --%s", diffFlagName, fixFlagName)
+	}
 	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
 	if err != nil {
 		return err
@@ -114,6 +170,27 @@ func run(
 	if err != nil {
 		return err
 	}
+	// The fixed files are written to the paths of the sources, so these must be
+	// files on the local filesystem.
+	if flags.Fix && !buffetch.IsLocalRef(ref) {
+		return appcmd.NewInvalidArgumentErrorf(
+			"--%s can only be used with local directory or proto file inputs",
+			fixFlagName,
+		)
+	}
+	var handlerOptions []buflint.HandlerOption
+	if flags.AllowPlugins {
+		// Plugins run arbitrary commands, so they are only run from configuration that
+		// the user controls, and not from for example the buf.yaml of a remote module.
+		if !buffetch.IsLocalRef(ref) && flags.Config == "" {
+			return appcmd.NewInvalidArgumentErrorf(
+				"--%s can only be used with local inputs or with --%s",
+				allowPluginsFlagName,
+				configFlagName,
+			)
+		}
+		handlerOptions = append(handlerOptions, buflint.HandlerWithPlugins())
+	}
 	storageosProvider := bufcli.NewStorageosProvider(flags.DisableSymlinks)
 	runner := command.NewRunner()
 	clientConfig, err := bufcli.NewConnectClientConfig(container)
@@ -143,36 +220,250 @@ func run(
 		return err
 	}
 	if len(fileAnnotations) > 0 {
-		formatString := flags.ErrorFormat
-		if formatString == "config-ignore-yaml" {
-			formatString = "text"
-		}
-		if err := bufanalysis.PrintFileAnnotations(container.Stdout(), fileAnnotations, formatString); err != nil {
+		return printBuildFileAnnotations(container, fileAnnotations, flags.ErrorFormat)
+	}
+	var sourceReadBucket storage.ReadBucket
+	if flags.Fix {
+		sourceReadBucket, err = newSourceReadBucket(ctx, imageConfigs)
+		if err != nil {
 			return err
 		}
-		return bufcli.ErrFileAnnotation
 	}
 	var allFileAnnotations []bufanalysis.FileAnnotation
+	var fixed bool
 	for _, imageConfig := range imageConfigs {
-		fileAnnotations, err := buflint.NewHandler(container.Logger()).Check(
+		fileAnnotations, err := lintImageConfig(ctx, container, runner, imageConfig, handlerOptions)
+		if err != nil {
+			return err
+		}
+		if flags.Fix {
+			var imageConfigFixed bool
+			fileAnnotations, imageConfigFixed, err = fixFileAnnotations(
+				ctx,
+				container,
+				runner,
+				sourceReadBucket,
+				imageConfig.Config().Lint,
+				fileAnnotations,
+				flags.Diff,
+			)
+			if err != nil {
+				return err
+			}
+			fixed = fixed || imageConfigFixed
+		}
+		allFileAnnotations = append(allFileAnnotations, fileAnnotations...)
+	}
+	// The fixed files are formatted, so the locations of the violations that could not
+	// be fixed may have changed. The fixed files are linted again so that the violations
+	// are printed at their new locations. With --diff, the files are not changed.
+	if fixed && !flags.Diff && len(allFileAnnotations) > 0 {
+		imageConfigs, fileAnnotations, err = imageConfigReader.GetImageConfigs(
 			ctx,
-			imageConfig.Config().Lint,
-			bufimage.ImageWithoutImports(imageConfig.Image()),
+			container,
+			ref,
+			flags.Config,
+			flags.Paths,
+			flags.ExcludePaths,
+			false,
+			false,
 		)
 		if err != nil {
 			return err
 		}
-		allFileAnnotations = append(allFileAnnotations, fileAnnotations...)
+		if len(fileAnnotations) > 0 {
+			return printBuildFileAnnotations(container, fileAnnotations, flags.ErrorFormat)
+		}
+		allFileAnnotations = nil
+		for _, imageConfig := range imageConfigs {
+			fileAnnotations, err := lintImageConfig(ctx, container, runner, imageConfig, handlerOptions)
+			if err != nil {
+				return err
+			}
+			allFileAnnotations = append(allFileAnnotations, fileAnnotations...)
+		}
 	}
 	if len(allFileAnnotations) > 0 {
+		var allRules []bufcheck.Rule
+		// The rules are only used to describe the rules for the sarif format.
+		if format, err := bufanalysis.ParseFormat(flags.ErrorFormat); err == nil && format == bufanalysis.FormatSARIF {
+			for _, imageConfig := range imageConfigs {
+				rules, err := buflint.RulesForConfig(imageConfig.Config().Lint)
+				if err != nil {
+					return err
+				}
+				allRules = append(allRules, rules...)
+			}
+		}
 		if err := buflintconfig.PrintFileAnnotations(
 			container.Stdout(),
 			bufanalysis.DeduplicateAndSortFileAnnotations(allFileAnnotations),
 			flags.ErrorFormat,
+			bufanalysis.PrintFileAnnotationsWithRules(bufcheck.AnalysisRulesForRules(allRules)...),
 		); err != nil {
 			return err
 		}
 		return bufcli.ErrFileAnnotation
 	}
+	// With --diff, the files still have the violations that were fixed in the diff.
+	if fixed && flags.Diff {
+		return bufcli.ErrFileAnnotation
+	}
 	return nil
+}
+
+// printBuildFileAnnotations prints the FileAnnotations of a failed build and
+// returns bufcli.ErrFileAnnotation.
+func printBuildFileAnnotations(
+	container appflag.Container,
+	fileAnnotations []bufanalysis.FileAnnotation,
+	errorFormat string,
+) error {
+	if errorFormat == "config-ignore-yaml" {
+		errorFormat = "text"
+	}
+	if err := bufanalysis.PrintFileAnnotations(container.Stdout(), fileAnnotations, errorFormat); err != nil {
+		return err
+	}
+	return bufcli.ErrFileAnnotation
+}
+
+// lintImageConfig lints the image of the ImageConfig and returns the FileAnnotations.
+func lintImageConfig(
+	ctx context.Context,
+	container appflag.Container,
+	runner command.Runner,
+	imageConfig bufwire.ImageConfig,
+	handlerOptions []buflint.HandlerOption,
+) ([]bufanalysis.FileAnnotation, error) {
+	fileAnnotations, err := buflint.NewHandler(container.Logger(), runner, handlerOptions...).Check(
+		ctx,
+		imageConfig.Config().Lint,
+		bufimage.ImageWithoutImports(imageConfig.Image()),
+	)
+	if errors.Is(err, bufcheck.ErrPluginsNotEnabled) {
+		return nil, fmt.Errorf("%w, plugins are only run with --%s", err, allowPluginsFlagName)
+	}
+	return fileAnnotations, err
+}
+
+// newSourceReadBucket returns a bucket with the sources of the files of the images
+// of the ImageConfigs, read from their external paths.
+//
+// All the files are read, and not only the files with FileAnnotations, so that the
+// fixes do not rename anything that is referenced from the other files.
+func newSourceReadBucket(
+	ctx context.Context,
+	imageConfigs []bufwire.ImageConfig,
+) (storage.ReadBucket, error) {
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	for _, imageConfig := range imageConfigs {
+		for _, imageFile := range imageConfig.Image().Files() {
+			if _, err := readWriteBucket.Stat(ctx, imageFile.Path()); err == nil {
+				continue
+			}
+			if err := putSourceFile(ctx, readWriteBucket, imageFile); err != nil {
+				// Imports that are not on the local filesystem, such as the files of
+				// remote dependencies, are never fixed.
+				if imageFile.IsImport() && errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				return nil, err
+			}
+		}
+	}
+	return readWriteBucket, nil
+}
+
+// fixFileAnnotations applies the machine-applicable fixes for the given FileAnnotations
+// to the source files and returns the FileAnnotations that could not be fixed, and
+// whether any file was fixed.
+//
+// If diff is true, the diff between the original and fixed files is written to stdout
+// instead of rewriting the files in-place.
+func fixFileAnnotations(
+	ctx context.Context,
+	container appflag.Container,
+	runner command.Runner,
+	sourceReadBucket storage.ReadBucket,
+	config *buflintconfig.Config,
+	fileAnnotations []bufanalysis.FileAnnotation,
+	diff bool,
+) ([]bufanalysis.FileAnnotation, bool, error) {
+	fixedReadBucket, unfixedFileAnnotations, err := buflintfix.Fix(
+		ctx,
+		sourceReadBucket,
+		config,
+		fileAnnotations,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	fixedPaths, err := storage.AllPaths(ctx, fixedReadBucket, "")
+	if err != nil {
+		return nil, false, err
+	}
+	if len(fixedPaths) == 0 {
+		return unfixedFileAnnotations, false, nil
+	}
+	if diff {
+		matchers := make([]storage.Matcher, len(fixedPaths))
+		for i, fixedPath := range fixedPaths {
+			matchers[i] = storage.MatchPathEqual(fixedPath)
+		}
+		diffBuffer := bytes.NewBuffer(nil)
+		if err := storage.Diff(
+			ctx,
+			runner,
+			diffBuffer,
+			storage.MapReadBucket(sourceReadBucket, storage.MatchOr(matchers...)),
+			fixedReadBucket,
+			storage.DiffWithExternalPaths(), // No need to set prefixes as the buckets are from the same location.
+		); err != nil {
+			return nil, false, err
+		}
+		if _, err := io.Copy(container.Stdout(), diffBuffer); err != nil {
+			return nil, false, err
+		}
+		return unfixedFileAnnotations, true, nil
+	}
+	if err := storage.WalkReadObjects(
+		ctx,
+		fixedReadBucket,
+		"",
+		func(readObject storage.ReadObject) error {
+			data, err := io.ReadAll(readObject)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(readObject.ExternalPath(), data, 0644)
+		},
+	); err != nil {
+		return nil, false, err
+	}
+	return unfixedFileAnnotations, true, nil
+}
+
+// putSourceFile reads the source file from its external path and puts it
+// into the bucket at its path.
+func putSourceFile(
+	ctx context.Context,
+	writeBucket storage.WriteBucket,
+	fileInfo bufanalysis.FileInfo,
+) (retErr error) {
+	data, err := os.ReadFile(fileInfo.ExternalPath())
+	if err != nil {
+		return err
+	}
+	writeObjectCloser, err := writeBucket.Put(ctx, fileInfo.Path())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, writeObjectCloser.Close())
+	}()
+	if _, err := writeObjectCloser.Write(data); err != nil {
+		return err
+	}
+	return writeObjectCloser.SetExternalPath(fileInfo.ExternalPath())
 }
\ No newline at end of file
@@ -27,6 +27,7 @@ import (
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
@@ -138,12 +139,20 @@ func handle(
 	if err != nil {
 		return err
 	}
-	fileAnnotations, err := bufbreaking.NewHandler(logger).Check(
+	var handlerOptions []bufbreaking.HandlerOption
+	if externalConfig.AllowPlugins {
+		// The configuration is always read from the local filesystem or the parameter.
+		handlerOptions = append(handlerOptions, bufbreaking.HandlerWithPlugins())
+	}
+	fileAnnotations, err := bufbreaking.NewHandler(logger, command.NewRunner(), handlerOptions...).Check(
 		ctx,
 		config.Breaking,
 		againstImage,
 		image,
 	)
+	if errors.Is(err, bufcheck.ErrPluginsNotEnabled) {
+		return fmt.Errorf("%w, plugins are only run with allow_plugins set in the parameter", err)
+	}
 	if err != nil {
 		return err
 	}
@@ -167,6 +176,7 @@ type externalConfig struct {
 	LogFormat          string          `json:"log_format,omitempty" yaml:"log_format,omitempty"`
 	ErrorFormat        string          `json:"error_format,omitempty" yaml:"error_format,omitempty"`
 	Timeout            time.Duration   `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	AllowPlugins       bool            `json:"allow_plugins,omitempty" yaml:"allow_plugins,omitempty"`
 }
 
 type container struct {
@@ -19,9 +19,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
@@ -29,6 +32,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/applog"
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/encoding"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"google.golang.org/protobuf/types/pluginpb"
@@ -105,11 +109,19 @@ func handle(
 		return err
 	}
 	image = bufimage.ImageWithoutImports(image)
-	fileAnnotations, err := buflint.NewHandler(logger).Check(
+	var handlerOptions []buflint.HandlerOption
+	if externalConfig.AllowPlugins {
+		// The configuration is always read from the local filesystem or the parameter.
+		handlerOptions = append(handlerOptions, buflint.HandlerWithPlugins())
+	}
+	fileAnnotations, err := buflint.NewHandler(logger, command.NewRunner(), handlerOptions...).Check(
 		ctx,
 		config.Lint,
 		image,
 	)
+	if errors.Is(err, bufcheck.ErrPluginsNotEnabled) {
+		return fmt.Errorf("%w, plugins are only run with allow_plugins set in the parameter", err)
+	}
 	if err != nil {
 		return err
 	}
@@ -124,9 +136,10 @@ func handle(
 }
 
 type externalConfig struct {
-	InputConfig json.RawMessage `json:"input_config,omitempty" yaml:"input_config,omitempty"`
-	LogLevel    string          `json:"log_level,omitempty" yaml:"log_level,omitempty"`
-	LogFormat   string          `json:"log_format,omitempty" yaml:"log_format,omitempty"`
-	ErrorFormat string          `json:"error_format,omitempty" yaml:"error_format,omitempty"`
-	Timeout     time.Duration   `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	InputConfig  json.RawMessage `json:"input_config,omitempty" yaml:"input_config,omitempty"`
+	LogLevel     string          `json:"log_level,omitempty" yaml:"log_level,omitempty"`
+	LogFormat    string          `json:"log_format,omitempty" yaml:"log_format,omitempty"`
+	ErrorFormat  string          `json:"error_format,omitempty" yaml:"error_format,omitempty"`
+	Timeout      time.Duration   `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	AllowPlugins bool            `json:"allow_plugins,omitempty" yaml:"allow_plugins,omitempty"`
 }
\ No newline at end of file
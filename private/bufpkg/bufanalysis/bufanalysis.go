@@ -32,6 +32,10 @@ const (
 	FormatMSVS
 	// FormatJUnit is the JUnit format for FileAnnotations.
 	FormatJUnit
+	// FormatSARIF is the SARIF 2.1.0 format for FileAnnotations.
+	FormatSARIF
+	// FormatGitHubActions is the GitHub Actions workflow command format for FileAnnotations.
+	FormatGitHubActions
 )
 
 var (
@@ -43,6 +47,8 @@ var (
 		"json",
 		"msvs",
 		"junit",
+		"sarif",
+		"github-actions",
 	}
 	// AllFormatStringsWithAliases is all format strings with aliases.
 	//
@@ -53,21 +59,27 @@ var (
 		"json",
 		"msvs",
 		"junit",
+		"sarif",
+		"github-actions",
 	}
 
 	stringToFormat = map[string]Format{
 		"text": FormatText,
 		// alias for text
-		"gcc":   FormatText,
-		"json":  FormatJSON,
-		"msvs":  FormatMSVS,
-		"junit": FormatJUnit,
+		"gcc":            FormatText,
+		"json":           FormatJSON,
+		"msvs":           FormatMSVS,
+		"junit":          FormatJUnit,
+		"sarif":          FormatSARIF,
+		"github-actions": FormatGitHubActions,
 	}
 	formatToString = map[Format]string{
-		FormatText:  "text",
-		FormatJSON:  "json",
-		FormatMSVS:  "msvs",
-		FormatJUnit: "junit",
+		FormatText:          "text",
+		FormatJSON:          "json",
+		FormatMSVS:          "msvs",
+		FormatJUnit:         "junit",
+		FormatSARIF:         "sarif",
+		FormatGitHubActions: "github-actions",
 	}
 )
 
@@ -140,6 +152,19 @@ type FileAnnotation interface {
 	Message() string
 }
 
+// Rule is the metadata of a rule that produces FileAnnotations.
+//
+// The FileAnnotation Type is expected to match the ID of the Rule.
+// This is satisfied by the lint and breaking rules in bufcheck.
+type Rule interface {
+	// ID returns the ID of the Rule.
+	ID() string
+	// Categories returns the categories of the Rule.
+	Categories() []string
+	// Purpose returns the purpose of the Rule.
+	Purpose() string
+}
+
 // NewFileAnnotation returns a new FileAnnotation.
 func NewFileAnnotation(
 	fileInfo FileInfo,
@@ -194,11 +219,20 @@ func DeduplicateAndSortFileAnnotations(fileAnnotations []FileAnnotation) []FileA
 }
 
 // PrintFileAnnotations prints the file annotations separated by newlines.
-func PrintFileAnnotations(writer io.Writer, fileAnnotations []FileAnnotation, formatString string) error {
+func PrintFileAnnotations(
+	writer io.Writer,
+	fileAnnotations []FileAnnotation,
+	formatString string,
+	options ...PrintFileAnnotationsOption,
+) error {
 	format, err := ParseFormat(formatString)
 	if err != nil {
 		return err
 	}
+	printFileAnnotationsOptions := newPrintFileAnnotationsOptions()
+	for _, option := range options {
+		option(printFileAnnotationsOptions)
+	}
 
 	switch format {
 	case FormatText:
@@ -209,11 +243,30 @@ func PrintFileAnnotations(writer io.Writer, fileAnnotations []FileAnnotation, fo
 		return printAsMSVS(writer, fileAnnotations)
 	case FormatJUnit:
 		return printAsJUnit(writer, fileAnnotations)
+	case FormatSARIF:
+		return printAsSARIF(writer, fileAnnotations, printFileAnnotationsOptions.rules)
+	case FormatGitHubActions:
+		return printAsGitHubActions(writer, fileAnnotations)
 	default:
 		return fmt.Errorf("unknown FileAnnotation Format: %v", format)
 	}
 }
 
+// PrintFileAnnotationsOption is an option for PrintFileAnnotations.
+type PrintFileAnnotationsOption func(*printFileAnnotationsOptions)
+
+// PrintFileAnnotationsWithRules returns a new PrintFileAnnotationsOption that provides
+// the metadata of the Rules that produced the FileAnnotations.
+//
+// This is only used by formats that describe rules, such as FormatSARIF. FileAnnotations
+// with a Type that does not match the ID of any of the given Rules are still printed,
+// but without any rule metadata.
+func PrintFileAnnotationsWithRules(rules ...Rule) PrintFileAnnotationsOption {
+	return func(printFileAnnotationsOptions *printFileAnnotationsOptions) {
+		printFileAnnotationsOptions.rules = append(printFileAnnotationsOptions.rules, rules...)
+	}
+}
+
 // hash returns a hash value that uniquely identifies the given FileAnnotation.
 func hash(fileAnnotation FileAnnotation) string {
 	path := ""
@@ -231,6 +284,14 @@ func hash(fileAnnotation FileAnnotation) string {
 	return string(hash.Sum(nil))
 }
 
+type printFileAnnotationsOptions struct {
+	rules []Rule
+}
+
+func newPrintFileAnnotationsOptions() *printFileAnnotationsOptions {
+	return &printFileAnnotationsOptions{}
+}
+
 type sortFileAnnotations []FileAnnotation
 
 func (a sortFileAnnotations) Len() int               { return len(a) }
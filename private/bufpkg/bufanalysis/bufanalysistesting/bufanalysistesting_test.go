@@ -94,4 +94,214 @@ path/to/file.proto(2,1) : error FOO : Hello.
 `,
 		sb.String(),
 	)
+	sb.Reset()
+	err = bufanalysis.PrintFileAnnotations(sb, fileAnnotations, "github-actions")
+	require.NoError(t, err)
+	assert.Equal(t,
+		`::error file=path/to/file.proto,line=1,endLine=1,title=FOO::Hello.
+::error file=path/to/file.proto,line=2,col=1,endLine=2,endColumn=1,title=FOO::Hello.
+`,
+		sb.String(),
+	)
+}
+
+func TestGitHubActionsEscape(t *testing.T) {
+	t.Parallel()
+	fileAnnotations := []bufanalysis.FileAnnotation{
+		newFileAnnotation(
+			t,
+			"path/to/a,b:c.proto",
+			0,
+			0,
+			0,
+			0,
+			"FOO",
+			"100% broken\nsecond line",
+		),
+	}
+	sb := &strings.Builder{}
+	err := bufanalysis.PrintFileAnnotations(sb, fileAnnotations, "github-actions")
+	require.NoError(t, err)
+	assert.Equal(t,
+		`::error file=path/to/a%2Cb%3Ac.proto,title=FOO::100%25 broken%0Asecond line
+`,
+		sb.String(),
+	)
+}
+
+func TestSARIF(t *testing.T) {
+	t.Parallel()
+	fileAnnotations := []bufanalysis.FileAnnotation{
+		newFileAnnotation(
+			t,
+			"path/to/file.proto",
+			1,
+			0,
+			1,
+			0,
+			"FOO",
+			"Hello.",
+		),
+		newFileAnnotation(
+			t,
+			"path/to/file.proto",
+			2,
+			1,
+			2,
+			5,
+			"BAR",
+			"Goodbye.",
+		),
+		newFileAnnotation(
+			t,
+			"path/to/file.proto",
+			3,
+			1,
+			3,
+			1,
+			"FOO",
+			"Hello again.",
+		),
+	}
+	sb := &strings.Builder{}
+	err := bufanalysis.PrintFileAnnotations(
+		sb,
+		fileAnnotations,
+		"sarif",
+		bufanalysis.PrintFileAnnotationsWithRules(
+			newRule("FOO", "Checks that foo.", "DEFAULT", "FOO_CATEGORY"),
+		),
+	)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "buf",
+          "informationUri": "https://github.com/bufbuild/buf",
+          "rules": [
+            {
+              "id": "FOO",
+              "shortDescription": {
+                "text": "Checks that foo."
+              },
+              "properties": {
+                "tags": [
+                  "DEFAULT",
+                  "FOO_CATEGORY"
+                ]
+              }
+            },
+            {
+              "id": "BAR"
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "FOO",
+          "ruleIndex": 0,
+          "level": "error",
+          "message": {
+            "text": "Hello."
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "path/to/file.proto"
+                },
+                "region": {
+                  "startLine": 1,
+                  "endLine": 1
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "BAR",
+          "ruleIndex": 1,
+          "level": "error",
+          "message": {
+            "text": "Goodbye."
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "path/to/file.proto"
+                },
+                "region": {
+                  "startLine": 2,
+                  "startColumn": 1,
+                  "endLine": 2,
+                  "endColumn": 5
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "FOO",
+          "ruleIndex": 0,
+          "level": "error",
+          "message": {
+            "text": "Hello again."
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "path/to/file.proto"
+                },
+                "region": {
+                  "startLine": 3,
+                  "startColumn": 1,
+                  "endLine": 3,
+                  "endColumn": 1
+                }
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`,
+		sb.String(),
+	)
+}
+
+type testRule struct {
+	id         string
+	purpose    string
+	categories []string
+}
+
+func newRule(id string, purpose string, categories ...string) *testRule {
+	return &testRule{
+		id:         id,
+		purpose:    purpose,
+		categories: categories,
+	}
+}
+
+func (r *testRule) ID() string {
+	return r.id
+}
+
+func (r *testRule) Categories() []string {
+	return r.categories
+}
+
+func (r *testRule) Purpose() string {
+	return r.purpose
 }
\ No newline at end of file
@@ -21,6 +21,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -49,6 +50,14 @@ func printAsJSON(writer io.Writer, fileAnnotations []FileAnnotation) error {
 	)
 }
 
+func printAsGitHubActions(writer io.Writer, fileAnnotations []FileAnnotation) error {
+	return printEachAnnotationOnNewLine(
+		writer,
+		fileAnnotations,
+		printFileAnnotationAsGitHubActions,
+	)
+}
+
 func printAsJUnit(writer io.Writer, fileAnnotations []FileAnnotation) error {
 	encoder := xml.NewEncoder(writer)
 	encoder.Indent("", "  ")
@@ -97,6 +106,57 @@ func printAsJUnit(writer io.Writer, fileAnnotations []FileAnnotation) error {
 	return nil
 }
 
+func printAsSARIF(writer io.Writer, fileAnnotations []FileAnnotation, rules []Rule) error {
+	idToRule := make(map[string]Rule, len(rules))
+	for _, rule := range rules {
+		if _, ok := idToRule[rule.ID()]; !ok {
+			idToRule[rule.ID()] = rule
+		}
+	}
+	// We only describe the rules that are referenced by results, in the
+	// order that they are first referenced.
+	sarifRules := make([]*sarifReportingDescriptor, 0)
+	ruleIDToIndex := make(map[string]int)
+	sarifResults := make([]*sarifResult, 0, len(fileAnnotations))
+	for _, fileAnnotation := range fileAnnotations {
+		if fileAnnotation == nil {
+			continue
+		}
+		sarifResult := newSARIFResult(fileAnnotation)
+		if ruleID := fileAnnotation.Type(); ruleID != "" {
+			index, ok := ruleIDToIndex[ruleID]
+			if !ok {
+				index = len(sarifRules)
+				ruleIDToIndex[ruleID] = index
+				sarifRules = append(sarifRules, newSARIFReportingDescriptor(ruleID, idToRule[ruleID]))
+			}
+			sarifResult.RuleID = ruleID
+			sarifResult.RuleIndex = &index
+		}
+		sarifResults = append(sarifResults, sarifResult)
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(
+		&sarifLog{
+			Schema:  sarifSchema,
+			Version: sarifVersion,
+			Runs: []*sarifRun{
+				{
+					Tool: &sarifTool{
+						Driver: &sarifToolComponent{
+							Name:           sarifToolName,
+							InformationURI: sarifToolInformationURI,
+							Rules:          sarifRules,
+						},
+					},
+					Results: sarifResults,
+				},
+			},
+		},
+	)
+}
+
 func printFileAnnotationAsJUnit(encoder *xml.Encoder, annotation FileAnnotation) error {
 	testcase := xml.StartElement{Name: xml.Name{Local: "testcase"}}
 	name := annotation.Type()
@@ -193,6 +253,75 @@ func printFileAnnotationAsMSVS(buffer *bytes.Buffer, f FileAnnotation) error {
 	return nil
 }
 
+// printFileAnnotationAsGitHubActions prints the FileAnnotation as a GitHub Actions
+// workflow command, which results in an annotation on the pull request.
+//
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-an-error-message
+func printFileAnnotationAsGitHubActions(buffer *bytes.Buffer, f FileAnnotation) error {
+	// This will work as long as f != (*fileAnnotation)(nil)
+	if f == nil {
+		return nil
+	}
+	message := f.Message()
+	if message == "" {
+		message = f.Type()
+		// should never happen but just in case
+		if message == "" {
+			message = "FAILURE"
+		}
+	}
+	_, _ = buffer.WriteString("::error")
+	separator := " "
+	writeProperty := func(key string, value string) {
+		_, _ = buffer.WriteString(separator)
+		_, _ = buffer.WriteString(key)
+		_, _ = buffer.WriteRune('=')
+		_, _ = buffer.WriteString(escapeGitHubActionsProperty(value))
+		separator = ","
+	}
+	if f.FileInfo() != nil {
+		writeProperty("file", f.FileInfo().ExternalPath())
+	}
+	if f.StartLine() != 0 {
+		writeProperty("line", strconv.Itoa(f.StartLine()))
+		if f.StartColumn() != 0 {
+			writeProperty("col", strconv.Itoa(f.StartColumn()))
+		}
+		if f.EndLine() != 0 {
+			writeProperty("endLine", strconv.Itoa(f.EndLine()))
+			if f.EndColumn() != 0 {
+				writeProperty("endColumn", strconv.Itoa(f.EndColumn()))
+			}
+		}
+	}
+	if f.Type() != "" {
+		writeProperty("title", f.Type())
+	}
+	_, _ = buffer.WriteString("::")
+	_, _ = buffer.WriteString(escapeGitHubActionsData(message))
+	return nil
+}
+
+// escapeGitHubActionsData escapes the message of a GitHub Actions workflow command.
+func escapeGitHubActionsData(s string) string {
+	return strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+	).Replace(s)
+}
+
+// escapeGitHubActionsProperty escapes a property value of a GitHub Actions workflow command.
+func escapeGitHubActionsProperty(s string) string {
+	return strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+		":", "%3A",
+		",", "%2C",
+	).Replace(s)
+}
+
 func printFileAnnotationAsJSON(buffer *bytes.Buffer, f FileAnnotation) error {
 	data, err := json.Marshal(newExternalFileAnnotation(f))
 	if err != nil {
@@ -245,4 +374,148 @@ func printEachAnnotationOnNewLine(
 		}
 	}
 	return nil
+}
+
+const (
+	sarifSchema             = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion            = "2.1.0"
+	sarifToolName           = "buf"
+	sarifToolInformationURI = "https://github.com/bufbuild/buf"
+	sarifLevelError         = "error"
+)
+
+// sarifLog is the top-level object of a SARIF 2.1.0 document.
+//
+// Only the subset of the specification that we produce is modeled.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    *sarifTool     `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver *sarifToolComponent `json:"driver"`
+}
+
+type sarifToolComponent struct {
+	Name           string                      `json:"name"`
+	InformationURI string                      `json:"informationUri,omitempty"`
+	Rules          []*sarifReportingDescriptor `json:"rules,omitempty"`
+}
+
+type sarifReportingDescriptor struct {
+	ID               string            `json:"id"`
+	ShortDescription *sarifMessage     `json:"shortDescription,omitempty"`
+	Properties       *sarifPropertyBag `json:"properties,omitempty"`
+}
+
+type sarifPropertyBag struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string           `json:"ruleId,omitempty"`
+	RuleIndex *int             `json:"ruleIndex,omitempty"`
+	Level     string           `json:"level"`
+	Message   *sarifMessage    `json:"message"`
+	Locations []*sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation *sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion           `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+func newSARIFReportingDescriptor(ruleID string, rule Rule) *sarifReportingDescriptor {
+	sarifReportingDescriptor := &sarifReportingDescriptor{
+		ID: ruleID,
+	}
+	if rule == nil {
+		return sarifReportingDescriptor
+	}
+	if purpose := rule.Purpose(); purpose != "" {
+		sarifReportingDescriptor.ShortDescription = &sarifMessage{
+			Text: purpose,
+		}
+	}
+	if categories := rule.Categories(); len(categories) > 0 {
+		sarifReportingDescriptor.Properties = &sarifPropertyBag{
+			Tags: categories,
+		}
+	}
+	return sarifReportingDescriptor
+}
+
+func newSARIFResult(f FileAnnotation) *sarifResult {
+	message := f.Message()
+	if message == "" {
+		message = f.Type()
+		// should never happen but just in case
+		if message == "" {
+			message = "FAILURE"
+		}
+	}
+	sarifResult := &sarifResult{
+		Level: sarifLevelError,
+		Message: &sarifMessage{
+			Text: message,
+		},
+	}
+	fileInfo := f.FileInfo()
+	if fileInfo == nil {
+		return sarifResult
+	}
+	sarifPhysicalLocation := &sarifPhysicalLocation{
+		ArtifactLocation: &sarifArtifactLocation{
+			URI: filepath.ToSlash(fileInfo.ExternalPath()),
+		},
+	}
+	// SARIF requires that lines and columns are 1-indexed, so we only
+	// add a region if we know where the annotation starts.
+	if f.StartLine() > 0 {
+		sarifRegion := &sarifRegion{
+			StartLine: f.StartLine(),
+		}
+		if f.StartColumn() > 0 {
+			sarifRegion.StartColumn = f.StartColumn()
+		}
+		if f.EndLine() >= f.StartLine() {
+			sarifRegion.EndLine = f.EndLine()
+			if f.EndColumn() > 0 {
+				sarifRegion.EndColumn = f.EndColumn()
+			}
+		}
+		sarifPhysicalLocation.Region = sarifRegion
+	}
+	sarifResult.Locations = []*sarifLocation{
+		{
+			PhysicalLocation: sarifPhysicalLocation,
+		},
+	}
+	return sarifResult
 }
\ No newline at end of file
@@ -26,9 +26,11 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/bufbreakingconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingv1"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/internal/bufbreakingv1beta1"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufcheckplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"go.uber.org/zap"
 )
 
@@ -49,15 +51,35 @@ type Handler interface {
 }
 
 // NewHandler returns a new Handler.
-func NewHandler(logger *zap.Logger) Handler {
-	return newHandler(logger)
+//
+// The runner is used to run the external check plugins configured in the Config,
+// if HandlerWithPlugins is set.
+func NewHandler(logger *zap.Logger, runner command.Runner, options ...HandlerOption) Handler {
+	return newHandler(logger, runner, options...)
+}
+
+// HandlerOption is an option for a new Handler.
+type HandlerOption func(*handler)
+
+// HandlerWithPlugins returns a new HandlerOption that runs the external check
+// plugins configured in the Config.
+//
+// Plugins are arbitrary commands, so this should only be set if the Config comes
+// from a trusted source, such as a configuration file on the local filesystem.
+// Only the Config of the current image is used, never the configuration of the
+// previous image. By default, plugins are not run, and Check returns an error
+// wrapping bufcheck.ErrPluginsNotEnabled if rules provided by a plugin are in use.
+func HandlerWithPlugins() HandlerOption {
+	return func(handler *handler) {
+		handler.runPlugins = true
+	}
 }
 
 // RulesForConfig returns the rules for a given config.
 //
 // Should only be used for printing.
 func RulesForConfig(config *bufbreakingconfig.Config) ([]bufcheck.Rule, error) {
-	internalConfig, err := internalConfigForConfig(config)
+	internalConfig, err := internalConfigForConfig(config, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +95,7 @@ func GetAllRulesV1Beta1() ([]bufcheck.Rule, error) {
 			Use:     internal.AllIDsForVersionSpec(bufbreakingv1beta1.VersionSpec),
 			Version: bufconfig.V1Beta1Version,
 		},
+		nil,
 	)
 	if err != nil {
 		return nil, err
@@ -89,6 +112,7 @@ func GetAllRulesV1() ([]bufcheck.Rule, error) {
 			Use:     internal.AllIDsForVersionSpec(bufbreakingv1.VersionSpec),
 			Version: bufconfig.V1Version,
 		},
+		nil,
 	)
 	if err != nil {
 		return nil, err
@@ -110,7 +134,14 @@ func GetAllRulesAndCategoriesV1() []string {
 	return internal.AllCategoriesAndIDsForVersionSpec(bufbreakingv1.VersionSpec)
 }
 
-func internalConfigForConfig(config *bufbreakingconfig.Config) (*internal.Config, error) {
+// internalConfigForConfig returns the internal.Config for the Config.
+//
+// newPluginCheckFunc returns the function used to run a given plugin. If newPluginCheckFunc
+// is nil, the resulting rules can only be used for printing.
+func internalConfigForConfig(
+	config *bufbreakingconfig.Config,
+	newPluginCheckFunc func(*bufcheckplugin.Config) internal.PluginCheckFunc,
+) (*internal.Config, error) {
 	var versionSpec *internal.VersionSpec
 	switch config.Version {
 	case bufconfig.V1Beta1Version:
@@ -124,6 +155,7 @@ func internalConfigForConfig(config *bufbreakingconfig.Config) (*internal.Config
 		IgnoreRootPaths:               config.IgnoreRootPaths,
 		IgnoreIDOrCategoryToRootPaths: config.IgnoreIDOrCategoryToRootPaths,
 		IgnoreUnstablePackages:        config.IgnoreUnstablePackages,
+		Plugins:                       internal.PluginsForConfigs(config.Plugins, newPluginCheckFunc),
 	}.NewConfig(
 		versionSpec,
 	)
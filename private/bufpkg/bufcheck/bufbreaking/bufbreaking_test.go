@@ -23,12 +23,14 @@ import (
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis/bufanalysistesting"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagebuild"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/stretchr/testify/assert"
@@ -46,6 +48,29 @@ func TestRunBreakingEnumNoDelete(t *testing.T) {
 	)
 }
 
+func TestRunBreakingPlugin(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_plugin",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 6, 3, 6, 16, "GATEWAY_PATH_PARAM_SAME_TYPE"),
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "c.proto", "GATEWAY_PATH_PARAM_SAME_TYPE"),
+	)
+}
+
+func TestRunBreakingPluginNotEnabled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	config, previousImage, image := testGetConfigAndImages(ctx, t, "breaking_plugin")
+	_, err := bufbreaking.NewHandler(zap.NewNop(), command.NewRunner()).Check(
+		ctx,
+		config.Breaking,
+		previousImage,
+		image,
+	)
+	assert.ErrorIs(t, err, bufcheck.ErrPluginsNotEnabled)
+}
+
 func TestRunBreakingEnumValueNoDelete(t *testing.T) {
 	testBreaking(
 		t,
@@ -710,6 +735,47 @@ func TestRunBreakingMessageMessage(t *testing.T) {
 	)
 }
 
+func TestRunBreakingDeprecation(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_deprecation",
+		bufanalysistesting.NewFileAnnotationNoLocationOrPath(t, "ENUM_VALUE_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotationNoLocationOrPath(t, "MESSAGE_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotationNoLocationOrPath(t, "RPC_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "a.proto", "ENUM_VALUE_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "a.proto", "ENUM_VALUE_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "a.proto", "MESSAGE_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "a.proto", "MESSAGE_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "a.proto", "RPC_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 1, 7, 2, "FIELD_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 9, 1, 9, 17, "MESSAGE_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 11, 1, 14, 2, "ENUM_VALUE_NO_DELETE_UNLESS_DEPRECATED"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 16, 1, 18, 2, "RPC_NO_DELETE_UNLESS_DEPRECATED"),
+	)
+}
+
+func TestRunBreakingValidation(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_validation",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 8, 1, 37, 2, "MESSAGE_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 17, 3, 17, 59, "FIELD_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 19, 3, 19, 80, "FIELD_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 19, 3, 19, 80, "FIELD_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 20, 3, 20, 85, "FIELD_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 21, 3, 21, 59, "FIELD_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 22, 3, 22, 15, "FIELD_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 23, 3, 27, 4, "ONEOF_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 32, 3, 32, 71, "FIELD_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 34, 3, 34, 61, "FIELD_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 36, 3, 36, 100, "FIELD_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 39, 1, 41, 2, "MESSAGE_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "b.proto", 11, 3, 11, 97, "FIELD_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "b.proto", 12, 3, 12, 19, "FIELD_NO_VALIDATION_NARROWING"),
+		bufanalysistesting.NewFileAnnotation(t, "b.proto", 13, 3, 13, 64, "FIELD_NO_VALIDATION_NARROWING"),
+	)
+}
+
 func testBreaking(
 	t *testing.T,
 	relDirPath string,
@@ -720,6 +786,28 @@ func testBreaking(
 	defer cancel()
 	logger := zap.NewNop()
 
+	config, previousImage, image := testGetConfigAndImages(ctx, t, relDirPath)
+
+	handler := bufbreaking.NewHandler(logger, command.NewRunner(), bufbreaking.HandlerWithPlugins())
+	fileAnnotations, err := handler.Check(
+		ctx,
+		config.Breaking,
+		previousImage,
+		image,
+	)
+	assert.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		expectedFileAnnotations,
+		fileAnnotations,
+	)
+}
+
+func testGetConfigAndImages(
+	ctx context.Context,
+	t *testing.T,
+	relDirPath string,
+) (*bufconfig.Config, bufimage.Image, bufimage.Image) {
 	previousDirPath := filepath.Join("testdata_previous", relDirPath)
 	dirPath := filepath.Join("testdata", relDirPath)
 
@@ -782,20 +870,7 @@ func testBreaking(
 	require.NoError(t, err)
 	require.Empty(t, fileAnnotations)
 	image = bufimage.ImageWithoutImports(image)
-
-	handler := bufbreaking.NewHandler(logger)
-	fileAnnotations, err = handler.Check(
-		ctx,
-		config.Breaking,
-		previousImage,
-		image,
-	)
-	assert.NoError(t, err)
-	bufanalysistesting.AssertFileAnnotationsEqual(
-		t,
-		expectedFileAnnotations,
-		fileAnnotations,
-	)
+	return config, previousImage, image
 }
 
 func testGetConfig(
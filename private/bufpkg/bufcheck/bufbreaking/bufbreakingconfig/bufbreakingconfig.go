@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"sort"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufcheckplugin"
 	breakingv1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/breaking/v1"
 )
 
@@ -46,6 +47,12 @@ type Config struct {
 	//   v\d+(alpha|beta)\d+
 	//   v\d+p\d+(alpha|beta)\d+
 	IgnoreUnstablePackages bool
+	// Plugins are the external check plugins that provide additional breaking change rules.
+	//
+	// Rules provided by plugins can be used in Use, Except and IgnoreIDOrCategoryToRootPaths
+	// like built-in rules. Plugins are only supported for v1 and are not part of the proto
+	// representation of the Config.
+	Plugins []*bufcheckplugin.Config
 	// Version represents the version of the breaking change rule and category IDs that should be used with this config.
 	Version string
 }
@@ -63,15 +70,20 @@ func NewConfigV1Beta1(externalConfig ExternalConfigV1Beta1) *Config {
 }
 
 // NewConfigV1 returns a new Config.
-func NewConfigV1(externalConfig ExternalConfigV1) *Config {
+func NewConfigV1(externalConfig ExternalConfigV1) (*Config, error) {
+	plugins, err := bufcheckplugin.NewConfigsV1(externalConfig.Plugins)
+	if err != nil {
+		return nil, err
+	}
 	return &Config{
 		Use:                           externalConfig.Use,
 		Except:                        externalConfig.Except,
 		IgnoreRootPaths:               externalConfig.Ignore,
 		IgnoreIDOrCategoryToRootPaths: externalConfig.IgnoreOnly,
 		IgnoreUnstablePackages:        externalConfig.IgnoreUnstablePackages,
+		Plugins:                       plugins,
 		Version:                       v1Version,
-	}
+	}, nil
 }
 
 // ConfigForProto returns the Config given the proto.
@@ -116,8 +128,9 @@ type ExternalConfigV1 struct {
 	// IgnoreRootPaths
 	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
 	// IgnoreIDOrCategoryToRootPaths
-	IgnoreOnly             map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
-	IgnoreUnstablePackages bool                `json:"ignore_unstable_packages,omitempty" yaml:"ignore_unstable_packages,omitempty"`
+	IgnoreOnly             map[string][]string               `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
+	IgnoreUnstablePackages bool                              `json:"ignore_unstable_packages,omitempty" yaml:"ignore_unstable_packages,omitempty"`
+	Plugins                []bufcheckplugin.ExternalConfigV1 `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
 // ExternalConfigV1Beta1ForConfig takes a *Config and returns the v1beta1 external config representation.
@@ -139,6 +152,7 @@ func ExternalConfigV1ForConfig(config *Config) ExternalConfigV1 {
 		Ignore:                 config.IgnoreRootPaths,
 		IgnoreOnly:             config.IgnoreIDOrCategoryToRootPaths,
 		IgnoreUnstablePackages: config.IgnoreUnstablePackages,
+		Plugins:                bufcheckplugin.ExternalConfigsV1ForConfigs(config.Plugins),
 	}
 }
 
@@ -153,12 +167,13 @@ func BytesForConfig(config *Config) ([]byte, error) {
 }
 
 type configJSON struct {
-	Use                           []string      `json:"use,omitempty"`
-	Except                        []string      `json:"except,omitempty"`
-	IgnoreRootPaths               []string      `json:"ignore_root_paths,omitempty"`
-	IgnoreIDOrCategoryToRootPaths []idPathsJSON `json:"ignore_id_to_root_paths,omitempty"`
-	IgnoreUnstablePackages        bool          `json:"ignore_unstable_packages,omitempty"`
-	Version                       string        `json:"version,omitempty"`
+	Use                           []string                          `json:"use,omitempty"`
+	Except                        []string                          `json:"except,omitempty"`
+	IgnoreRootPaths               []string                          `json:"ignore_root_paths,omitempty"`
+	IgnoreIDOrCategoryToRootPaths []idPathsJSON                     `json:"ignore_id_to_root_paths,omitempty"`
+	IgnoreUnstablePackages        bool                              `json:"ignore_unstable_packages,omitempty"`
+	Plugins                       []bufcheckplugin.ExternalConfigV1 `json:"plugins,omitempty"`
+	Version                       string                            `json:"version,omitempty"`
 }
 
 type idPathsJSON struct {
@@ -195,6 +210,7 @@ func configToJSON(config *Config) *configJSON {
 		IgnoreRootPaths:               ignoreRootPaths,
 		IgnoreIDOrCategoryToRootPaths: ignoreIDPathsJSON,
 		IgnoreUnstablePackages:        config.IgnoreUnstablePackages,
+		Plugins:                       bufcheckplugin.ExternalConfigsV1ForConfigs(config.Plugins),
 		Version:                       config.Version,
 	}
 }
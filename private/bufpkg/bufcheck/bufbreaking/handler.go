@@ -16,30 +16,43 @@ package bufbreaking
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufbreaking/bufbreakingconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufcheckplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/protosource"
 	"go.uber.org/zap"
 )
 
 type handler struct {
-	logger *zap.Logger
-	runner *internal.Runner
+	logger        *zap.Logger
+	runner        *internal.Runner
+	commandRunner command.Runner
+	runPlugins    bool
 }
 
 func newHandler(
 	logger *zap.Logger,
+	commandRunner command.Runner,
+	options ...HandlerOption,
 ) *handler {
-	return &handler{
-		logger: logger,
+	handler := &handler{
+		logger:        logger,
+		commandRunner: commandRunner,
 		// comment ignores are not allowed for breaking changes
 		// so do not set the ignore prefix per the RunnerWithIgnorePrefix comments
 		runner: internal.NewRunner(logger),
 	}
+	for _, option := range options {
+		option(handler)
+	}
+	return handler
 }
 
 func (h *handler) Check(
@@ -56,9 +69,32 @@ func (h *handler) Check(
 	if err != nil {
 		return nil, err
 	}
-	internalConfig, err := internalConfigForConfig(config)
+	internalConfig, err := internalConfigForConfig(config, h.newPluginCheckFuncForImages(previousImage, image))
 	if err != nil {
 		return nil, err
 	}
 	return h.runner.Check(ctx, internalConfig, previousFiles, files)
 }
+
+func (h *handler) newPluginCheckFuncForImages(
+	previousImage bufimage.Image,
+	image bufimage.Image,
+) func(*bufcheckplugin.Config) internal.PluginCheckFunc {
+	return func(pluginConfig *bufcheckplugin.Config) internal.PluginCheckFunc {
+		if !h.runPlugins {
+			return func(context.Context, []string) ([]bufanalysis.FileAnnotation, error) {
+				return nil, fmt.Errorf("breaking plugin %s has rules in use: %w", pluginConfig.Name(), bufcheck.ErrPluginsNotEnabled)
+			}
+		}
+		return func(ctx context.Context, ruleIDs []string) ([]bufanalysis.FileAnnotation, error) {
+			return bufcheckplugin.Run(
+				ctx,
+				h.commandRunner,
+				pluginConfig,
+				bufimage.ImageToProtoImage(image),
+				bufimage.ImageToProtoImage(previousImage),
+				ruleIDs,
+			)
+		}
+	}
+}
@@ -39,6 +39,12 @@ var (
 		"enum values are not deleted from a given enum",
 		bufbreakingcheck.CheckEnumValueNoDelete,
 	)
+	// EnumValueNoDeleteUnlessDeprecatedRuleBuilder is a rule builder.
+	EnumValueNoDeleteUnlessDeprecatedRuleBuilder = internal.NewNopRuleBuilder(
+		"ENUM_VALUE_NO_DELETE_UNLESS_DEPRECATED",
+		"enum values are not deleted from a given enum unless they were deprecated",
+		bufbreakingcheck.CheckEnumValueNoDeleteUnlessDeprecated,
+	)
 	// EnumValueNoDeleteUnlessNameReservedRuleBuilder is a rule builder.
 	EnumValueNoDeleteUnlessNameReservedRuleBuilder = internal.NewNopRuleBuilder(
 		"ENUM_VALUE_NO_DELETE_UNLESS_NAME_RESERVED",
@@ -69,6 +75,12 @@ var (
 		"fields are not deleted from a given message",
 		bufbreakingcheck.CheckFieldNoDelete,
 	)
+	// FieldNoDeleteUnlessDeprecatedRuleBuilder is a rule builder.
+	FieldNoDeleteUnlessDeprecatedRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_NO_DELETE_UNLESS_DEPRECATED",
+		"fields are not deleted from a given message unless they were deprecated",
+		bufbreakingcheck.CheckFieldNoDeleteUnlessDeprecated,
+	)
 	// FieldNoDeleteUnlessNameReservedRuleBuilder is a rule builder.
 	FieldNoDeleteUnlessNameReservedRuleBuilder = internal.NewNopRuleBuilder(
 		"FIELD_NO_DELETE_UNLESS_NAME_RESERVED",
@@ -81,6 +93,12 @@ var (
 		"fields are not deleted from a given message unless the number is reserved",
 		bufbreakingcheck.CheckFieldNoDeleteUnlessNumberReserved,
 	)
+	// FieldNoValidationNarrowingRuleBuilder is a rule builder.
+	FieldNoValidationNarrowingRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_NO_VALIDATION_NARROWING",
+		"the protovalidate and protoc-gen-validate constraints of fields are not narrowed",
+		bufbreakingcheck.CheckFieldNoValidationNarrowing,
+	)
 	// FieldSameCTypeRuleBuilder is a rule builder.
 	FieldSameCTypeRuleBuilder = internal.NewNopRuleBuilder(
 		"FIELD_SAME_CTYPE",
@@ -267,12 +285,24 @@ var (
 		"messages are not deleted from a given file",
 		bufbreakingcheck.CheckMessageNoDelete,
 	)
+	// MessageNoDeleteUnlessDeprecatedRuleBuilder is a rule builder.
+	MessageNoDeleteUnlessDeprecatedRuleBuilder = internal.NewNopRuleBuilder(
+		"MESSAGE_NO_DELETE_UNLESS_DEPRECATED",
+		"messages are not deleted from a given file unless they were deprecated",
+		bufbreakingcheck.CheckMessageNoDeleteUnlessDeprecated,
+	)
 	// MessageNoRemoveStandardDescriptorAccessorRuleBuilder is a rule builder.
 	MessageNoRemoveStandardDescriptorAccessorRuleBuilder = internal.NewNopRuleBuilder(
 		"MESSAGE_NO_REMOVE_STANDARD_DESCRIPTOR_ACCESSOR",
 		"messages do not change the no_standard_descriptor_accessor option from false or unset to true",
 		bufbreakingcheck.CheckMessageNoRemoveStandardDescriptorAccessor,
 	)
+	// MessageNoValidationNarrowingRuleBuilder is a rule builder.
+	MessageNoValidationNarrowingRuleBuilder = internal.NewNopRuleBuilder(
+		"MESSAGE_NO_VALIDATION_NARROWING",
+		"the protovalidate and protoc-gen-validate constraints of messages are not narrowed",
+		bufbreakingcheck.CheckMessageNoValidationNarrowing,
+	)
 	// MessageSameMessageSetWireFormatRuleBuilder is a rule builder.
 	MessageSameMessageSetWireFormatRuleBuilder = internal.NewNopRuleBuilder(
 		"MESSAGE_SAME_MESSAGE_SET_WIRE_FORMAT",
@@ -291,6 +321,12 @@ var (
 		"oneofs are not deleted from a given message",
 		bufbreakingcheck.CheckOneofNoDelete,
 	)
+	// OneofNoValidationNarrowingRuleBuilder is a rule builder.
+	OneofNoValidationNarrowingRuleBuilder = internal.NewNopRuleBuilder(
+		"ONEOF_NO_VALIDATION_NARROWING",
+		"the protovalidate and protoc-gen-validate constraints of oneofs are not narrowed",
+		bufbreakingcheck.CheckOneofNoValidationNarrowing,
+	)
 	// PackageEnumNoDeleteRuleBuilder is a rule builder.
 	PackageEnumNoDeleteRuleBuilder = internal.NewNopRuleBuilder(
 		"PACKAGE_ENUM_NO_DELETE",
@@ -333,6 +369,12 @@ var (
 		"rpcs are not deleted from a given service",
 		bufbreakingcheck.CheckRPCNoDelete,
 	)
+	// RPCNoDeleteUnlessDeprecatedRuleBuilder is a rule builder.
+	RPCNoDeleteUnlessDeprecatedRuleBuilder = internal.NewNopRuleBuilder(
+		"RPC_NO_DELETE_UNLESS_DEPRECATED",
+		"rpcs are not deleted from a given service unless they were deprecated",
+		bufbreakingcheck.CheckRPCNoDeleteUnlessDeprecated,
+	)
 	// RPCSameClientStreamingRuleBuilder is a rule builder.
 	RPCSameClientStreamingRuleBuilder = internal.NewNopRuleBuilder(
 		"RPC_SAME_CLIENT_STREAMING",
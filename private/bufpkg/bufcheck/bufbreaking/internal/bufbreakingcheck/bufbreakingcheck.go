@@ -125,6 +125,79 @@ func isDeletedEnumValueAllowedWithRules(previousNumber int, previousNameToEnumVa
 	return false
 }
 
+// CheckEnumValueNoDeleteUnlessDeprecated is a check function.
+var CheckEnumValueNoDeleteUnlessDeprecated = newFilesCheckFunc(checkEnumValueNoDeleteUnlessDeprecated)
+
+func checkEnumValueNoDeleteUnlessDeprecated(add addFunc, corpus *corpus) error {
+	previousFullNameToEnum, err := protosource.FullNameToEnum(corpus.previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToEnum, err := protosource.FullNameToEnum(corpus.files...)
+	if err != nil {
+		return err
+	}
+	filePathToFile, err := protosource.FilePathToFile(corpus.files...)
+	if err != nil {
+		return err
+	}
+	for previousFullName, previousEnum := range previousFullNameToEnum {
+		if enum, ok := fullNameToEnum[previousFullName]; ok {
+			if err := checkEnumValueNoDeleteUnlessDeprecatedForEnum(add, previousEnum, enum); err != nil {
+				return err
+			}
+			continue
+		}
+		// Deleting an enum, or the message or file that contains it, deletes its values.
+		if previousEnum.Deprecated() || isMessageOrParentDeprecated(previousEnum.Parent()) || previousEnum.File().Deprecated() {
+			continue
+		}
+		previousNumberToNameToEnumValue, err := protosource.NumberToNameToEnumValue(previousEnum)
+		if err != nil {
+			return err
+		}
+		for previousNumber, previousNameToEnumValue := range previousNumberToNameToEnumValue {
+			if isEnumValueDeprecated(previousNameToEnumValue) {
+				continue
+			}
+			if file, ok := filePathToFile[previousEnum.File().Path()]; ok {
+				add(file, nil, nil, `Previously present enum value "%d" on enum %q was deleted with the enum without first being deprecated.`, previousNumber, previousEnum.Name())
+			} else {
+				// Add previous descriptor to check for ignores, as the file no longer exists.
+				add(nil, []protosource.Descriptor{previousEnum}, nil, `Previously present enum value "%d" on enum %q was deleted with the enum without first being deprecated.`, previousNumber, previousEnum.Name())
+			}
+		}
+	}
+	return nil
+}
+
+func checkEnumValueNoDeleteUnlessDeprecatedForEnum(add addFunc, previousEnum protosource.Enum, enum protosource.Enum) error {
+	previousNumberToNameToEnumValue, err := protosource.NumberToNameToEnumValue(previousEnum)
+	if err != nil {
+		return err
+	}
+	numberToNameToEnumValue, err := protosource.NumberToNameToEnumValue(enum)
+	if err != nil {
+		return err
+	}
+	for previousNumber, previousNameToEnumValue := range previousNumberToNameToEnumValue {
+		if _, ok := numberToNameToEnumValue[previousNumber]; !ok && !isEnumValueDeprecated(previousNameToEnumValue) {
+			add(enum, nil, enum.Location(), `Previously present enum value "%d" on enum %q was deleted without first being deprecated.`, previousNumber, enum.Name())
+		}
+	}
+	return nil
+}
+
+// isEnumValueDeprecated returns true if all aliases of the enum value number are deprecated.
+func isEnumValueDeprecated(nameToEnumValue map[string]protosource.EnumValue) bool {
+	for _, enumValue := range nameToEnumValue {
+		if !enumValue.Deprecated() {
+			return false
+		}
+	}
+	return true
+}
+
 // CheckEnumValueSameName is a check function.
 var CheckEnumValueSameName = newEnumValuePairCheckFunc(checkEnumValueSameName)
 
@@ -220,6 +293,44 @@ func isDeletedFieldAllowedWithRules(previousField protosource.Field, message pro
 		(allowIfNameReserved && protosource.NameInReservedNames(previousField.Name(), message.ReservedNames()...))
 }
 
+// CheckFieldNoDeleteUnlessDeprecated is a check function.
+var CheckFieldNoDeleteUnlessDeprecated = newMessagePairCheckFunc(checkFieldNoDeleteUnlessDeprecated)
+
+func checkFieldNoDeleteUnlessDeprecated(add addFunc, corpus *corpus, previousMessage protosource.Message, message protosource.Message) error {
+	previousNumberToField, err := protosource.NumberToMessageField(previousMessage)
+	if err != nil {
+		return err
+	}
+	numberToField, err := protosource.NumberToMessageField(message)
+	if err != nil {
+		return err
+	}
+	for previousNumber, previousField := range previousNumberToField {
+		if _, ok := numberToField[previousNumber]; !ok && !previousField.Deprecated() {
+			// otherwise prints as hex
+			previousNumberString := strconv.FormatInt(int64(previousNumber), 10)
+			add(message, nil, message.Location(), `Previously present field %q with name %q on message %q was deleted without first being deprecated.`, previousNumberString, previousField.Name(), message.Name())
+		}
+	}
+	return nil
+}
+
+// CheckFieldNoValidationNarrowing is a check function.
+var CheckFieldNoValidationNarrowing = newFieldPairCheckFunc(checkFieldNoValidationNarrowing)
+
+func checkFieldNoValidationNarrowing(add addFunc, corpus *corpus, previousField protosource.Field, field protosource.Field) error {
+	narrowings, err := fieldValidationNarrowings(previousField, field)
+	if err != nil {
+		return fmt.Errorf("invalid validation constraints on field %q: %w", field.FullName(), err)
+	}
+	for _, narrowing := range narrowings {
+		// otherwise prints as hex
+		numberString := strconv.FormatInt(int64(field.Number()), 10)
+		add(field, nil, field.Location(), `Field %q with name %q on message %q %s.`, numberString, field.Name(), field.Message().Name(), narrowing)
+	}
+	return nil
+}
+
 // CheckFieldSameCType is a check function.
 var CheckFieldSameCType = newFieldPairCheckFunc(checkFieldSameCType)
 
@@ -715,6 +826,64 @@ func checkMessageNoDelete(add addFunc, corpus *corpus, previousFile protosource.
 	return nil
 }
 
+// CheckMessageNoDeleteUnlessDeprecated is a check function.
+var CheckMessageNoDeleteUnlessDeprecated = newFilesCheckFunc(checkMessageNoDeleteUnlessDeprecated)
+
+func checkMessageNoDeleteUnlessDeprecated(add addFunc, corpus *corpus) error {
+	previousFullNameToMessage, err := protosource.FullNameToMessage(corpus.previousFiles...)
+	if err != nil {
+		return err
+	}
+	// Messages are matched by full name, so that messages moved to another file are not deleted.
+	fullNameToMessage, err := protosource.FullNameToMessage(corpus.files...)
+	if err != nil {
+		return err
+	}
+	filePathToFile, err := protosource.FilePathToFile(corpus.files...)
+	if err != nil {
+		return err
+	}
+	filePathToNestedNameToMessage := make(map[string]map[string]protosource.Message)
+	for previousFullName, previousMessage := range previousFullNameToMessage {
+		if _, ok := fullNameToMessage[previousFullName]; ok {
+			continue
+		}
+		// Deleting a message, or the message or file that contains it, deletes its nested messages.
+		if isMessageOrParentDeprecated(previousMessage) || previousMessage.File().Deprecated() {
+			continue
+		}
+		previousFilePath := previousMessage.File().Path()
+		file, ok := filePathToFile[previousFilePath]
+		if !ok {
+			// Add previous descriptor to check for ignores, as the file no longer exists.
+			add(nil, []protosource.Descriptor{previousMessage.File()}, nil, `Previously present message %q was deleted with file %q without first being deprecated.`, previousMessage.NestedName(), previousFilePath)
+			continue
+		}
+		nestedNameToMessage, ok := filePathToNestedNameToMessage[previousFilePath]
+		if !ok {
+			nestedNameToMessage, err = protosource.NestedNameToMessage(file)
+			if err != nil {
+				return err
+			}
+			filePathToNestedNameToMessage[previousFilePath] = nestedNameToMessage
+		}
+		descriptor, location := getDescriptorAndLocationForDeletedMessage(file, nestedNameToMessage, previousMessage.NestedName())
+		add(descriptor, nil, location, `Previously present message %q was deleted from file without first being deprecated.`, previousMessage.NestedName())
+	}
+	return nil
+}
+
+// isMessageOrParentDeprecated returns true if the message or any of its parent messages
+// is deprecated, as deleting a deprecated message also deletes its nested messages.
+func isMessageOrParentDeprecated(message protosource.Message) bool {
+	for ; message != nil; message = message.Parent() {
+		if message.Deprecated() {
+			return true
+		}
+	}
+	return false
+}
+
 // CheckMessageNoRemoveStandardDescriptorAccessor is a check function.
 var CheckMessageNoRemoveStandardDescriptorAccessor = newMessagePairCheckFunc(checkMessageNoRemoveStandardDescriptorAccessor)
 
@@ -727,6 +896,20 @@ func checkMessageNoRemoveStandardDescriptorAccessor(add addFunc, corpus *corpus,
 	return nil
 }
 
+// CheckMessageNoValidationNarrowing is a check function.
+var CheckMessageNoValidationNarrowing = newMessagePairCheckFunc(checkMessageNoValidationNarrowing)
+
+func checkMessageNoValidationNarrowing(add addFunc, corpus *corpus, previousMessage protosource.Message, message protosource.Message) error {
+	narrowings, err := messageValidationNarrowings(previousMessage, message)
+	if err != nil {
+		return fmt.Errorf("invalid validation constraints on message %q: %w", message.FullName(), err)
+	}
+	for _, narrowing := range narrowings {
+		add(message, nil, message.Location(), `Message %q %s.`, message.Name(), narrowing)
+	}
+	return nil
+}
+
 // CheckMessageSameMessageSetWireFormat is a check function.
 var CheckMessageSameMessageSetWireFormat = newMessagePairCheckFunc(checkMessageSameMessageSetWireFormat)
 
@@ -792,6 +975,20 @@ func checkOneofNoDelete(add addFunc, corpus *corpus, previousMessage protosource
 	return nil
 }
 
+// CheckOneofNoValidationNarrowing is a check function.
+var CheckOneofNoValidationNarrowing = newOneofPairCheckFunc(checkOneofNoValidationNarrowing)
+
+func checkOneofNoValidationNarrowing(add addFunc, corpus *corpus, previousOneof protosource.Oneof, oneof protosource.Oneof) error {
+	narrowings, err := oneofValidationNarrowings(previousOneof, oneof)
+	if err != nil {
+		return fmt.Errorf("invalid validation constraints on oneof %q: %w", oneof.FullName(), err)
+	}
+	for _, narrowing := range narrowings {
+		add(oneof, nil, oneof.Location(), `Oneof %q on message %q %s.`, oneof.Name(), oneof.Message().Name(), narrowing)
+	}
+	return nil
+}
+
 // CheckPackageEnumNoDelete is a check function.
 var CheckPackageEnumNoDelete = newFilesCheckFunc(checkPackageEnumNoDelete)
 
@@ -1021,6 +1218,65 @@ func checkRPCNoDelete(add addFunc, corpus *corpus, previousService protosource.S
 	return nil
 }
 
+// CheckRPCNoDeleteUnlessDeprecated is a check function.
+var CheckRPCNoDeleteUnlessDeprecated = newFilesCheckFunc(checkRPCNoDeleteUnlessDeprecated)
+
+func checkRPCNoDeleteUnlessDeprecated(add addFunc, corpus *corpus) error {
+	previousFullNameToService, err := protosource.FullNameToService(corpus.previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToService, err := protosource.FullNameToService(corpus.files...)
+	if err != nil {
+		return err
+	}
+	filePathToFile, err := protosource.FilePathToFile(corpus.files...)
+	if err != nil {
+		return err
+	}
+	for previousFullName, previousService := range previousFullNameToService {
+		if service, ok := fullNameToService[previousFullName]; ok {
+			if err := checkRPCNoDeleteUnlessDeprecatedForService(add, previousService, service); err != nil {
+				return err
+			}
+			continue
+		}
+		// Deleting a service deletes its RPCs.
+		if previousService.Deprecated() || previousService.File().Deprecated() {
+			continue
+		}
+		for _, previousMethod := range previousService.Methods() {
+			if previousMethod.Deprecated() {
+				continue
+			}
+			if file, ok := filePathToFile[previousService.File().Path()]; ok {
+				add(file, nil, nil, `Previously present RPC %q on service %q was deleted with the service without first being deprecated.`, previousMethod.Name(), previousService.Name())
+			} else {
+				// Add previous descriptor to check for ignores, as the file no longer exists.
+				add(nil, []protosource.Descriptor{previousService}, nil, `Previously present RPC %q on service %q was deleted with the service without first being deprecated.`, previousMethod.Name(), previousService.Name())
+			}
+		}
+	}
+	return nil
+}
+
+func checkRPCNoDeleteUnlessDeprecatedForService(add addFunc, previousService protosource.Service, service protosource.Service) error {
+	previousNameToMethod, err := protosource.NameToMethod(previousService)
+	if err != nil {
+		return err
+	}
+	nameToMethod, err := protosource.NameToMethod(service)
+	if err != nil {
+		return err
+	}
+	for previousName, previousMethod := range previousNameToMethod {
+		if _, ok := nameToMethod[previousName]; !ok && !previousMethod.Deprecated() {
+			add(service, nil, service.Location(), `Previously present RPC %q on service %q was deleted without first being deprecated.`, previousName, service.Name())
+		}
+	}
+	return nil
+}
+
 // CheckRPCSameClientStreaming is a check function.
 var CheckRPCSameClientStreaming = newMethodPairCheckFunc(checkRPCSameClientStreaming)
 
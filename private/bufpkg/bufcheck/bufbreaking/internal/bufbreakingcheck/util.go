@@ -247,6 +247,31 @@ func newFieldPairCheckFunc(
 	)
 }
 
+func newOneofPairCheckFunc(
+	f func(addFunc, *corpus, protosource.Oneof, protosource.Oneof) error,
+) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+	return newMessagePairCheckFunc(
+		func(add addFunc, corpus *corpus, previousMessage protosource.Message, message protosource.Message) error {
+			previousNameToOneof, err := protosource.NameToMessageOneof(previousMessage)
+			if err != nil {
+				return err
+			}
+			nameToOneof, err := protosource.NameToMessageOneof(message)
+			if err != nil {
+				return err
+			}
+			for previousName, previousOneof := range previousNameToOneof {
+				if oneof, ok := nameToOneof[previousName]; ok {
+					if err := f(add, corpus, previousOneof, oneof); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	)
+}
+
 func newServicePairCheckFunc(
 	f func(addFunc, *corpus, protosource.Service, protosource.Service) error,
 ) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
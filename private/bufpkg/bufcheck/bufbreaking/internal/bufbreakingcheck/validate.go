@@ -0,0 +1,1054 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreakingcheck
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/bufbuild/buf/private/pkg/protosource"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The validation constraints of protovalidate and protoc-gen-validate are read from the
+// wire format of the options, as we do not link in their generated types. The field
+// numbers below are from buf/validate/validate.proto and validate/validate.proto.
+
+const (
+	// protovalidateExtensionNumber is the number of the buf.validate.field,
+	// buf.validate.message and buf.validate.oneof extensions.
+	protovalidateExtensionNumber = 1159
+	// pgvExtensionNumber is the number of the validate.rules, validate.disabled
+	// and validate.required extensions.
+	pgvExtensionNumber = 1071
+	// pgvIgnoredExtensionNumber is the number of the validate.ignored extension.
+	pgvIgnoredExtensionNumber = 1072
+)
+
+type validationRuleKind int
+
+const (
+	// validationRuleKindExact rules narrow when they are added or changed.
+	validationRuleKindExact validationRuleKind = iota + 1
+	// validationRuleKindMin rules are unsigned minimums that narrow when they are added or increased.
+	validationRuleKindMin
+	// validationRuleKindMax rules are unsigned maximums that narrow when they are added or decreased.
+	validationRuleKindMax
+	// validationRuleKindMaxDuration rules are google.protobuf.Duration maximums that narrow
+	// when they are added or decreased.
+	validationRuleKindMaxDuration
+	// validationRuleKindIn rules narrow when they are added or when a value is removed.
+	validationRuleKindIn
+	// validationRuleKindNotIn rules narrow when a value is added.
+	validationRuleKindNotIn
+	// validationRuleKindEnable rules are bools that narrow when they are enabled.
+	validationRuleKindEnable
+	// validationRuleKindDisable rules are bools that narrow when they are disabled.
+	validationRuleKindDisable
+	// validationRuleKindEnableByDefault rules are bools that are enabled if they are
+	// not set, and narrow when they are enabled.
+	validationRuleKindEnableByDefault
+	// validationRuleKindCEL rules are CEL constraints that narrow when a constraint is added.
+	validationRuleKindCEL
+	// validationRuleKindNested rules are nested field constraints that are compared recursively.
+	validationRuleKindNested
+)
+
+type validationRule struct {
+	name string
+	kind validationRuleKind
+}
+
+// validationRulesSpec specifies the type-specific rules, such as StringRules.
+type validationRulesSpec struct {
+	name string
+	// elementType is the wire type of the values of in and not_in rules.
+	elementType protowire.Type
+	rules       map[protowire.Number]validationRule
+	// gt, gte, lt, and lte are the numbers of the bound rules, or 0 if the
+	// rules do not have bounds.
+	gt  protowire.Number
+	gte protowire.Number
+	lt  protowire.Number
+	lte protowire.Number
+	// decodeBound is set if the rules have bounds.
+	decodeBound func(wireValue) (*big.Float, error)
+}
+
+// validationFramework specifies the field constraints of a validation framework.
+type validationFramework struct {
+	// rules are the rules directly on the field constraints.
+	rules map[protowire.Number]validationRule
+	// typeRulesSpecs are the type-specific rules of the field constraints.
+	typeRulesSpecs map[protowire.Number]*validationRulesSpec
+}
+
+var (
+	protovalidateFramework = &validationFramework{
+		rules: map[protowire.Number]validationRule{
+			23: {"cel", validationRuleKindCEL},
+			24: {"skipped", validationRuleKindDisable},
+			25: {"required", validationRuleKindEnable},
+			26: {"ignore_empty", validationRuleKindDisable},
+		},
+		typeRulesSpecs: newValidationTypeRulesSpecs(),
+	}
+	pgvFramework = &validationFramework{
+		typeRulesSpecs: newPGVTypeRulesSpecs(),
+	}
+	protovalidateMessageRules = map[protowire.Number]validationRule{
+		1: {"disabled", validationRuleKindDisable},
+		3: {"cel", validationRuleKindCEL},
+	}
+	pgvMessageRules = map[protowire.Number]validationRule{
+		pgvExtensionNumber:        {"(validate.disabled)", validationRuleKindDisable},
+		pgvIgnoredExtensionNumber: {"(validate.ignored)", validationRuleKindDisable},
+	}
+	protovalidateOneofRules = map[protowire.Number]validationRule{
+		1: {"required", validationRuleKindEnable},
+	}
+	pgvOneofRules = map[protowire.Number]validationRule{
+		pgvExtensionNumber: {"(validate.required)", validationRuleKindEnable},
+	}
+)
+
+// newValidationTypeRulesSpecs returns the type-specific rules shared by protovalidate
+// and protoc-gen-validate, with the extra specs added.
+func newValidationTypeRulesSpecs(extraSpecs ...map[protowire.Number]*validationRulesSpec) map[protowire.Number]*validationRulesSpec {
+	specs := map[protowire.Number]*validationRulesSpec{
+		1: newNumericValidationRulesSpec("float", protowire.Fixed32Type, func(value uint64) (*big.Float, error) {
+			return newBigFloatFromFloat64(float64(math.Float32frombits(uint32(value))))
+		}),
+		2: newNumericValidationRulesSpec("double", protowire.Fixed64Type, func(value uint64) (*big.Float, error) {
+			return newBigFloatFromFloat64(math.Float64frombits(value))
+		}),
+		3:  newNumericValidationRulesSpec("int32", protowire.VarintType, decodeSignedBound),
+		4:  newNumericValidationRulesSpec("int64", protowire.VarintType, decodeSignedBound),
+		5:  newNumericValidationRulesSpec("uint32", protowire.VarintType, decodeUnsignedBound),
+		6:  newNumericValidationRulesSpec("uint64", protowire.VarintType, decodeUnsignedBound),
+		7:  newNumericValidationRulesSpec("sint32", protowire.VarintType, decodeZigZagBound),
+		8:  newNumericValidationRulesSpec("sint64", protowire.VarintType, decodeZigZagBound),
+		9:  newNumericValidationRulesSpec("fixed32", protowire.Fixed32Type, decodeUnsignedBound),
+		10: newNumericValidationRulesSpec("fixed64", protowire.Fixed64Type, decodeUnsignedBound),
+		11: newNumericValidationRulesSpec("sfixed32", protowire.Fixed32Type, func(value uint64) (*big.Float, error) {
+			return new(big.Float).SetInt64(int64(int32(uint32(value)))), nil
+		}),
+		12: newNumericValidationRulesSpec("sfixed64", protowire.Fixed64Type, decodeSignedBound),
+		13: {
+			name:        "bool",
+			elementType: protowire.VarintType,
+			rules: map[protowire.Number]validationRule{
+				1: {"const", validationRuleKindExact},
+			},
+		},
+		14: {
+			name:        "string",
+			elementType: protowire.BytesType,
+			rules: map[protowire.Number]validationRule{
+				1:  {"const", validationRuleKindExact},
+				2:  {"min_len", validationRuleKindMin},
+				3:  {"max_len", validationRuleKindMax},
+				4:  {"min_bytes", validationRuleKindMin},
+				5:  {"max_bytes", validationRuleKindMax},
+				6:  {"pattern", validationRuleKindExact},
+				7:  {"prefix", validationRuleKindExact},
+				8:  {"suffix", validationRuleKindExact},
+				9:  {"contains", validationRuleKindExact},
+				10: {"in", validationRuleKindIn},
+				11: {"not_in", validationRuleKindNotIn},
+				12: {"email", validationRuleKindEnable},
+				13: {"hostname", validationRuleKindEnable},
+				14: {"ip", validationRuleKindEnable},
+				15: {"ipv4", validationRuleKindEnable},
+				16: {"ipv6", validationRuleKindEnable},
+				17: {"uri", validationRuleKindEnable},
+				18: {"uri_ref", validationRuleKindEnable},
+				19: {"len", validationRuleKindExact},
+				20: {"len_bytes", validationRuleKindExact},
+				21: {"address", validationRuleKindEnable},
+				22: {"uuid", validationRuleKindEnable},
+				23: {"not_contains", validationRuleKindExact},
+				24: {"well_known_regex", validationRuleKindExact},
+				25: {"strict", validationRuleKindEnableByDefault},
+			},
+		},
+		15: {
+			name:        "bytes",
+			elementType: protowire.BytesType,
+			rules: map[protowire.Number]validationRule{
+				1:  {"const", validationRuleKindExact},
+				2:  {"min_len", validationRuleKindMin},
+				3:  {"max_len", validationRuleKindMax},
+				4:  {"pattern", validationRuleKindExact},
+				5:  {"prefix", validationRuleKindExact},
+				6:  {"suffix", validationRuleKindExact},
+				7:  {"contains", validationRuleKindExact},
+				8:  {"in", validationRuleKindIn},
+				9:  {"not_in", validationRuleKindNotIn},
+				10: {"ip", validationRuleKindEnable},
+				11: {"ipv4", validationRuleKindEnable},
+				12: {"ipv6", validationRuleKindEnable},
+				13: {"len", validationRuleKindExact},
+			},
+		},
+		16: {
+			name:        "enum",
+			elementType: protowire.VarintType,
+			rules: map[protowire.Number]validationRule{
+				1: {"const", validationRuleKindExact},
+				2: {"defined_only", validationRuleKindEnable},
+				3: {"in", validationRuleKindIn},
+				4: {"not_in", validationRuleKindNotIn},
+			},
+		},
+		18: {
+			name: "repeated",
+			rules: map[protowire.Number]validationRule{
+				1: {"min_items", validationRuleKindMin},
+				2: {"max_items", validationRuleKindMax},
+				3: {"unique", validationRuleKindEnable},
+				4: {"items", validationRuleKindNested},
+				5: {"ignore_empty", validationRuleKindDisable},
+			},
+		},
+		19: {
+			name: "map",
+			rules: map[protowire.Number]validationRule{
+				1: {"min_pairs", validationRuleKindMin},
+				2: {"max_pairs", validationRuleKindMax},
+				3: {"no_sparse", validationRuleKindEnable},
+				4: {"keys", validationRuleKindNested},
+				5: {"values", validationRuleKindNested},
+				6: {"ignore_empty", validationRuleKindDisable},
+			},
+		},
+		20: {
+			name:        "any",
+			elementType: protowire.BytesType,
+			rules: map[protowire.Number]validationRule{
+				1: {"required", validationRuleKindEnable},
+				2: {"in", validationRuleKindIn},
+				3: {"not_in", validationRuleKindNotIn},
+			},
+		},
+		21: {
+			name:        "duration",
+			elementType: protowire.BytesType,
+			rules: map[protowire.Number]validationRule{
+				1: {"required", validationRuleKindEnable},
+				2: {"const", validationRuleKindExact},
+				7: {"in", validationRuleKindIn},
+				8: {"not_in", validationRuleKindNotIn},
+			},
+			lt:          3,
+			lte:         4,
+			gt:          5,
+			gte:         6,
+			decodeBound: decodeSecondsAndNanosBound,
+		},
+		22: {
+			name:        "timestamp",
+			elementType: protowire.BytesType,
+			rules: map[protowire.Number]validationRule{
+				1: {"required", validationRuleKindEnable},
+				2: {"const", validationRuleKindExact},
+				7: {"lt_now", validationRuleKindEnable},
+				8: {"gt_now", validationRuleKindEnable},
+				9: {"within", validationRuleKindMaxDuration},
+			},
+			lt:          3,
+			lte:         4,
+			gt:          5,
+			gte:         6,
+			decodeBound: decodeSecondsAndNanosBound,
+		},
+	}
+	for _, extraSpec := range extraSpecs {
+		for number, spec := range extraSpec {
+			specs[number] = spec
+		}
+	}
+	return specs
+}
+
+// newPGVTypeRulesSpecs returns the type-specific rules of protoc-gen-validate.
+func newPGVTypeRulesSpecs() map[protowire.Number]*validationRulesSpec {
+	specs := newValidationTypeRulesSpecs(
+		map[protowire.Number]*validationRulesSpec{
+			17: {
+				name: "message",
+				rules: map[protowire.Number]validationRule{
+					1: {"skip", validationRuleKindDisable},
+					2: {"required", validationRuleKindEnable},
+				},
+			},
+		},
+	)
+	// protovalidate has ignore_empty on the field constraints, while protoc-gen-validate
+	// has it on the string and bytes rules, with numbers that are used by other rules
+	// in protovalidate.
+	specs[14].rules[26] = validationRule{"ignore_empty", validationRuleKindDisable}
+	specs[15].rules[14] = validationRule{"ignore_empty", validationRuleKindDisable}
+	return specs
+}
+
+func newNumericValidationRulesSpec(
+	name string,
+	elementType protowire.Type,
+	decode func(uint64) (*big.Float, error),
+) *validationRulesSpec {
+	return &validationRulesSpec{
+		name:        name,
+		elementType: elementType,
+		rules: map[protowire.Number]validationRule{
+			1: {"const", validationRuleKindExact},
+			6: {"in", validationRuleKindIn},
+			7: {"not_in", validationRuleKindNotIn},
+			8: {"ignore_empty", validationRuleKindDisable},
+		},
+		lt:  2,
+		lte: 3,
+		gt:  4,
+		gte: 5,
+		decodeBound: func(value wireValue) (*big.Float, error) {
+			return decode(value.scalar)
+		},
+	}
+}
+
+// fieldValidationNarrowings returns descriptions of how the validation constraints
+// of the field were narrowed.
+func fieldValidationNarrowings(previousField protosource.Field, field protosource.Field) ([]string, error) {
+	comparer := &validationComparer{}
+	for _, framework := range []struct {
+		name                string
+		extensionNumber     int32
+		validationFramework *validationFramework
+	}{
+		{"(buf.validate.field)", protovalidateExtensionNumber, protovalidateFramework},
+		{"(validate.rules)", pgvExtensionNumber, pgvFramework},
+	} {
+		previousConstraints, err := optionExtensionMessage(previousField, framework.extensionNumber)
+		if err != nil {
+			return nil, err
+		}
+		constraints, err := optionExtensionMessage(field, framework.extensionNumber)
+		if err != nil {
+			return nil, err
+		}
+		comparer.framework = framework.validationFramework
+		if err := comparer.compareFieldConstraints(framework.name, previousConstraints, constraints); err != nil {
+			return nil, err
+		}
+	}
+	return comparer.narrowings, nil
+}
+
+// messageValidationNarrowings returns descriptions of how the validation constraints
+// of the message were narrowed.
+func messageValidationNarrowings(previousMessage protosource.Message, message protosource.Message) ([]string, error) {
+	return optionValidationNarrowings(
+		previousMessage,
+		message,
+		"(buf.validate.message)",
+		protovalidateMessageRules,
+		pgvMessageRules,
+	)
+}
+
+// oneofValidationNarrowings returns descriptions of how the validation constraints
+// of the oneof were narrowed.
+func oneofValidationNarrowings(previousOneof protosource.Oneof, oneof protosource.Oneof) ([]string, error) {
+	return optionValidationNarrowings(
+		previousOneof,
+		oneof,
+		"(buf.validate.oneof)",
+		protovalidateOneofRules,
+		pgvOneofRules,
+	)
+}
+
+func optionValidationNarrowings(
+	previousDescriptor protosource.OptionExtensionDescriptor,
+	descriptor protosource.OptionExtensionDescriptor,
+	protovalidateName string,
+	protovalidateRules map[protowire.Number]validationRule,
+	pgvRules map[protowire.Number]validationRule,
+) ([]string, error) {
+	comparer := &validationComparer{}
+	previousConstraints, err := optionExtensionMessage(previousDescriptor, protovalidateExtensionNumber)
+	if err != nil {
+		return nil, err
+	}
+	constraints, err := optionExtensionMessage(descriptor, protovalidateExtensionNumber)
+	if err != nil {
+		return nil, err
+	}
+	if err := comparer.compareRules(protovalidateName, protovalidateRules, 0, previousConstraints, constraints); err != nil {
+		return nil, err
+	}
+	// The protoc-gen-validate options are scalar extensions, so we compare the
+	// extensions themselves.
+	previousExtensions, err := optionExtensions(previousDescriptor, pgvRules)
+	if err != nil {
+		return nil, err
+	}
+	extensions, err := optionExtensions(descriptor, pgvRules)
+	if err != nil {
+		return nil, err
+	}
+	if err := comparer.compareRules("", pgvRules, 0, previousExtensions, extensions); err != nil {
+		return nil, err
+	}
+	return comparer.narrowings, nil
+}
+
+type validationComparer struct {
+	framework  *validationFramework
+	narrowings []string
+}
+
+func (c *validationComparer) addf(format string, args ...interface{}) {
+	c.narrowings = append(c.narrowings, fmt.Sprintf(format, args...))
+}
+
+func (c *validationComparer) compareFieldConstraints(path string, previous wireMessage, current wireMessage) error {
+	if err := c.compareRules(path, c.framework.rules, 0, previous, current); err != nil {
+		return err
+	}
+	for _, number := range sortedValidationNumbers(c.framework.typeRulesSpecs) {
+		spec := c.framework.typeRulesSpecs[number]
+		currentRules, err := current.message(number)
+		if err != nil {
+			return err
+		}
+		previousRules, err := previous.message(number)
+		if err != nil {
+			return err
+		}
+		// removing rules can still narrow, for example if skip was set
+		if currentRules == nil && previousRules == nil {
+			continue
+		}
+		rulesPath := joinValidationPath(path, spec.name)
+		if err := c.compareRules(rulesPath, spec.rules, spec.elementType, previousRules, currentRules); err != nil {
+			return err
+		}
+		if spec.decodeBound != nil {
+			if err := c.compareBounds(rulesPath, spec, previousRules, currentRules); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *validationComparer) compareRules(
+	path string,
+	rules map[protowire.Number]validationRule,
+	elementType protowire.Type,
+	previous wireMessage,
+	current wireMessage,
+) error {
+	for _, number := range sortedValidationNumbers(rules) {
+		rule := rules[number]
+		rulePath := joinValidationPath(path, rule.name)
+		switch rule.kind {
+		case validationRuleKindExact:
+			if !current.has(number) || previous.equal(current, number) {
+				continue
+			}
+			if previous.has(number) {
+				c.addf("changed validation constraint %q", rulePath)
+			} else {
+				c.addf("added validation constraint %q", rulePath)
+			}
+		case validationRuleKindMin, validationRuleKindMax:
+			currentValue, ok := current.last(number)
+			if !ok {
+				continue
+			}
+			previousValue, ok := previous.last(number)
+			switch {
+			case !ok:
+				c.addf("added validation constraint %q", rulePath)
+			case rule.kind == validationRuleKindMin && currentValue.scalar > previousValue.scalar:
+				c.addf("increased validation constraint %q from %d to %d", rulePath, previousValue.scalar, currentValue.scalar)
+			case rule.kind == validationRuleKindMax && currentValue.scalar < previousValue.scalar:
+				c.addf("decreased validation constraint %q from %d to %d", rulePath, previousValue.scalar, currentValue.scalar)
+			}
+		case validationRuleKindMaxDuration:
+			currentValue, ok := current.last(number)
+			if !ok {
+				continue
+			}
+			previousValue, ok := previous.last(number)
+			if !ok {
+				c.addf("added validation constraint %q", rulePath)
+				continue
+			}
+			currentDuration, err := decodeSecondsAndNanosBound(currentValue)
+			if err != nil {
+				return err
+			}
+			previousDuration, err := decodeSecondsAndNanosBound(previousValue)
+			if err != nil {
+				return err
+			}
+			if currentDuration.Cmp(previousDuration) < 0 {
+				c.addf(
+					"decreased validation constraint %q from %ss to %ss",
+					rulePath,
+					previousDuration.Text('g', -1),
+					currentDuration.Text('g', -1),
+				)
+			}
+		case validationRuleKindIn:
+			currentKeys, err := current.keys(number, elementType)
+			if err != nil {
+				return err
+			}
+			if len(currentKeys) == 0 {
+				continue
+			}
+			previousKeys, err := previous.keys(number, elementType)
+			if err != nil {
+				return err
+			}
+			if len(previousKeys) == 0 {
+				c.addf("added validation constraint %q", rulePath)
+			} else if !isValidationKeySubset(previousKeys, currentKeys) {
+				c.addf("removed values from validation constraint %q", rulePath)
+			}
+		case validationRuleKindNotIn:
+			currentKeys, err := current.keys(number, elementType)
+			if err != nil {
+				return err
+			}
+			previousKeys, err := previous.keys(number, elementType)
+			if err != nil {
+				return err
+			}
+			if !isValidationKeySubset(currentKeys, previousKeys) {
+				c.addf("added values to validation constraint %q", rulePath)
+			}
+		case validationRuleKindEnable:
+			if current.bool(number) && !previous.bool(number) {
+				c.addf("enabled validation constraint %q", rulePath)
+			}
+		case validationRuleKindDisable:
+			if previous.bool(number) && !current.bool(number) {
+				c.addf("disabled validation constraint %q", rulePath)
+			}
+		case validationRuleKindEnableByDefault:
+			// the rule has no effect if the rules were removed
+			if current != nil && current.boolOrTrue(number) && !previous.boolOrTrue(number) {
+				c.addf("enabled validation constraint %q", rulePath)
+			}
+		case validationRuleKindCEL:
+			previousExpressions, err := previous.celExpressions(number)
+			if err != nil {
+				return err
+			}
+			currentExpressions, err := current.celExpressions(number)
+			if err != nil {
+				return err
+			}
+			for _, currentExpression := range currentExpressions {
+				if _, ok := previousExpressions[currentExpression.expression]; !ok {
+					c.addf("added CEL validation constraint %q to %q", currentExpression.name(), rulePath)
+				}
+			}
+		case validationRuleKindNested:
+			currentConstraints, err := current.message(number)
+			if err != nil {
+				return err
+			}
+			previousConstraints, err := previous.message(number)
+			if err != nil {
+				return err
+			}
+			if currentConstraints == nil && previousConstraints == nil {
+				continue
+			}
+			if err := c.compareFieldConstraints(rulePath, previousConstraints, currentConstraints); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown validationRuleKind: %v", rule.kind)
+		}
+	}
+	return nil
+}
+
+func (c *validationComparer) compareBounds(
+	path string,
+	spec *validationRulesSpec,
+	previous wireMessage,
+	current wireMessage,
+) error {
+	currentLower, currentUpper, err := getValidationBounds(spec, current)
+	if err != nil {
+		return err
+	}
+	if currentLower == nil && currentUpper == nil {
+		return nil
+	}
+	previousLower, previousUpper, err := getValidationBounds(spec, previous)
+	if err != nil {
+		return err
+	}
+	currentInverted := isInvertedValidationBounds(currentLower, currentUpper)
+	previousInverted := isInvertedValidationBounds(previousLower, previousUpper)
+	switch {
+	case !currentInverted && !previousInverted, currentInverted && previousInverted:
+		// The ends of the range of invalid values between inverted bounds move in
+		// the same directions as the ends of a range of valid values, so bounds are
+		// compared the same way if both are inverted.
+		c.compareBound(path, previousLower, currentLower, true)
+		c.compareBound(path, previousUpper, currentUpper, false)
+	case currentInverted:
+		// The values that were valid must all be less than the current upper bound,
+		// or all be greater than the current lower bound.
+		if (previousUpper == nil || currentUpper.narrows(previousUpper, false)) &&
+			(previousLower == nil || currentLower.narrows(previousLower, true)) {
+			c.addf(
+				"changed the bounds of validation constraint %q from %s to %s",
+				path,
+				validationBoundsString(previousLower, previousUpper),
+				validationBoundsString(currentLower, currentUpper),
+			)
+		}
+	default:
+		// Inverted bounds allow values that are arbitrarily small and large, so any
+		// bound that is not inverted narrows them.
+		c.addf(
+			"changed the bounds of validation constraint %q from %s to %s",
+			path,
+			validationBoundsString(previousLower, previousUpper),
+			validationBoundsString(currentLower, currentUpper),
+		)
+	}
+	return nil
+}
+
+func (c *validationComparer) compareBound(
+	path string,
+	previousBound *validationBound,
+	currentBound *validationBound,
+	lower bool,
+) {
+	if currentBound == nil {
+		return
+	}
+	boundName := "upper"
+	if lower {
+		boundName = "lower"
+	}
+	if previousBound == nil {
+		c.addf("added a %s bound of %s to validation constraint %q", boundName, currentBound.String(lower), path)
+		return
+	}
+	if currentBound.narrows(previousBound, lower) {
+		c.addf(
+			"narrowed the %s bound of validation constraint %q from %s to %s",
+			boundName,
+			path,
+			previousBound.String(lower),
+			currentBound.String(lower),
+		)
+	}
+}
+
+// validationBound is a lower or upper bound.
+//
+// If the lower bound is greater than the upper bound, the bounds are inverted,
+// and the valid values are outside of the range between the bounds. For example,
+// gt: 10 and lt: 5 allow values that are less than 5 or greater than 10.
+type validationBound struct {
+	value     *big.Float
+	exclusive bool
+}
+
+// getValidationBounds returns the lower and upper bounds of the rules, either of
+// which may be nil.
+func getValidationBounds(spec *validationRulesSpec, rules wireMessage) (*validationBound, *validationBound, error) {
+	lower, err := getValidationBound(spec, rules, spec.gt, spec.gte)
+	if err != nil {
+		return nil, nil, err
+	}
+	upper, err := getValidationBound(spec, rules, spec.lt, spec.lte)
+	if err != nil {
+		return nil, nil, err
+	}
+	return lower, upper, nil
+}
+
+func getValidationBound(
+	spec *validationRulesSpec,
+	rules wireMessage,
+	exclusiveNumber protowire.Number,
+	inclusiveNumber protowire.Number,
+) (*validationBound, error) {
+	if value, ok := rules.last(exclusiveNumber); ok {
+		decoded, err := spec.decodeBound(value)
+		if err != nil {
+			return nil, err
+		}
+		return &validationBound{value: decoded, exclusive: true}, nil
+	}
+	if value, ok := rules.last(inclusiveNumber); ok {
+		decoded, err := spec.decodeBound(value)
+		if err != nil {
+			return nil, err
+		}
+		return &validationBound{value: decoded}, nil
+	}
+	return nil, nil
+}
+
+// narrows returns true if the bound narrows the previous bound.
+func (b *validationBound) narrows(previous *validationBound, lower bool) bool {
+	cmp := b.value.Cmp(previous.value)
+	if !lower {
+		cmp = -cmp
+	}
+	return cmp > 0 || (cmp == 0 && b.exclusive && !previous.exclusive)
+}
+
+func (b *validationBound) String(lower bool) string {
+	operator := "<"
+	if lower {
+		operator = ">"
+	}
+	if !b.exclusive {
+		operator += "="
+	}
+	return strconv.Quote(operator + " " + b.value.Text('g', -1))
+}
+
+// isInvertedValidationBounds returns true if the lower bound is greater than the
+// upper bound.
+func isInvertedValidationBounds(lower *validationBound, upper *validationBound) bool {
+	return lower != nil && upper != nil && lower.value.Cmp(upper.value) > 0
+}
+
+// validationBoundsString returns the bounds for use in messages.
+func validationBoundsString(lower *validationBound, upper *validationBound) string {
+	switch {
+	case lower == nil && upper == nil:
+		return "no bounds"
+	case lower == nil:
+		return upper.String(false)
+	case upper == nil:
+		return lower.String(true)
+	case isInvertedValidationBounds(lower, upper):
+		return lower.String(true) + " or " + upper.String(false)
+	default:
+		return lower.String(true) + " and " + upper.String(false)
+	}
+}
+
+type celExpression struct {
+	id         string
+	expression string
+}
+
+func (e *celExpression) name() string {
+	if e.id != "" {
+		return e.id
+	}
+	return e.expression
+}
+
+// wireMessage is a wire-format message parsed into its field values, keyed by field number.
+type wireMessage map[protowire.Number][]wireValue
+
+type wireValue struct {
+	wireType protowire.Type
+	// scalar is set for varint, fixed32, and fixed64 values.
+	scalar uint64
+	// bytes is set for length-delimited values.
+	bytes []byte
+}
+
+func parseWireMessage(data []byte) (wireMessage, error) {
+	message := make(wireMessage)
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		value := wireValue{
+			wireType: wireType,
+		}
+		switch wireType {
+		case protowire.VarintType:
+			value.scalar, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed32Type:
+			var fixed32 uint32
+			fixed32, n = protowire.ConsumeFixed32(data)
+			value.scalar = uint64(fixed32)
+		case protowire.Fixed64Type:
+			value.scalar, n = protowire.ConsumeFixed64(data)
+		case protowire.BytesType:
+			value.bytes, n = protowire.ConsumeBytes(data)
+		default:
+			// groups are not used by validation constraints
+			n = protowire.ConsumeFieldValue(number, wireType, data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		message[number] = append(message[number], value)
+	}
+	return message, nil
+}
+
+// optionExtensionMessage returns the message value of the options extension
+// with the given number, or nil if the extension is not set.
+func optionExtensionMessage(descriptor protosource.OptionExtensionDescriptor, number int32) (wireMessage, error) {
+	rawFields, err := descriptor.OptionExtensionRawFields(number)
+	if err != nil {
+		return nil, err
+	}
+	extensions, err := parseWireMessage(rawFields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options extension %d: %w", number, err)
+	}
+	message, err := extensions.message(protowire.Number(number))
+	if err != nil {
+		return nil, fmt.Errorf("invalid options extension %d: %w", number, err)
+	}
+	return message, nil
+}
+
+// optionExtensions returns the options extensions with the numbers
+// of the rules.
+func optionExtensions(
+	descriptor protosource.OptionExtensionDescriptor,
+	rules map[protowire.Number]validationRule,
+) (wireMessage, error) {
+	extensions := make(wireMessage)
+	for number := range rules {
+		rawFields, err := descriptor.OptionExtensionRawFields(int32(number))
+		if err != nil {
+			return nil, err
+		}
+		numberExtensions, err := parseWireMessage(rawFields)
+		if err != nil {
+			return nil, fmt.Errorf("invalid options extension %d: %w", number, err)
+		}
+		for numberExtensionNumber, values := range numberExtensions {
+			extensions[numberExtensionNumber] = append(extensions[numberExtensionNumber], values...)
+		}
+	}
+	return extensions, nil
+}
+
+func (m wireMessage) has(number protowire.Number) bool {
+	return len(m[number]) > 0
+}
+
+// last returns the last value for the number, which wins for singular fields.
+func (m wireMessage) last(number protowire.Number) (wireValue, bool) {
+	values := m[number]
+	if len(values) == 0 {
+		return wireValue{}, false
+	}
+	return values[len(values)-1], true
+}
+
+func (m wireMessage) bool(number protowire.Number) bool {
+	value, ok := m.last(number)
+	return ok && value.scalar != 0
+}
+
+// boolOrTrue returns the bool value for the number, or true if the number is not present.
+func (m wireMessage) boolOrTrue(number protowire.Number) bool {
+	value, ok := m.last(number)
+	return !ok || value.scalar != 0
+}
+
+// message returns the message value for the number, merging all occurrences.
+//
+// Returns nil if the number is not present.
+func (m wireMessage) message(number protowire.Number) (wireMessage, error) {
+	values := m[number]
+	if len(values) == 0 {
+		return nil, nil
+	}
+	var data []byte
+	for _, value := range values {
+		if value.wireType != protowire.BytesType {
+			return nil, fmt.Errorf("field %d is not a message", number)
+		}
+		data = append(data, value.bytes...)
+	}
+	return parseWireMessage(data)
+}
+
+// equal returns true if the values for the number are equal in both messages.
+func (m wireMessage) equal(other wireMessage, number protowire.Number) bool {
+	value, ok := m.last(number)
+	otherValue, otherOK := other.last(number)
+	if !ok || !otherOK {
+		return ok == otherOK
+	}
+	return value.wireType == otherValue.wireType &&
+		value.scalar == otherValue.scalar &&
+		bytes.Equal(value.bytes, otherValue.bytes)
+}
+
+// keys returns the values for the repeated field with the given number as comparable
+// keys, unpacking packed values.
+func (m wireMessage) keys(number protowire.Number, elementType protowire.Type) (map[string]struct{}, error) {
+	keys := make(map[string]struct{})
+	for _, value := range m[number] {
+		if value.wireType != protowire.BytesType || elementType == protowire.BytesType {
+			keys[value.key()] = struct{}{}
+			continue
+		}
+		for data := value.bytes; len(data) > 0; {
+			var scalar uint64
+			var n int
+			switch elementType {
+			case protowire.VarintType:
+				scalar, n = protowire.ConsumeVarint(data)
+			case protowire.Fixed32Type:
+				var fixed32 uint32
+				fixed32, n = protowire.ConsumeFixed32(data)
+				scalar = uint64(fixed32)
+			case protowire.Fixed64Type:
+				scalar, n = protowire.ConsumeFixed64(data)
+			default:
+				return nil, fmt.Errorf("field %d has unexpected packed values", number)
+			}
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+			keys[strconv.FormatUint(scalar, 10)] = struct{}{}
+		}
+	}
+	return keys, nil
+}
+
+// celExpressions returns the CEL constraints for the number, keyed by expression.
+func (m wireMessage) celExpressions(number protowire.Number) (map[string]*celExpression, error) {
+	expressions := make(map[string]*celExpression)
+	for _, value := range m[number] {
+		if value.wireType != protowire.BytesType {
+			return nil, fmt.Errorf("field %d is not a message", number)
+		}
+		constraint, err := parseWireMessage(value.bytes)
+		if err != nil {
+			return nil, err
+		}
+		expression := &celExpression{}
+		if id, ok := constraint.last(1); ok {
+			expression.id = string(id.bytes)
+		}
+		if expressionValue, ok := constraint.last(3); ok {
+			expression.expression = string(expressionValue.bytes)
+		}
+		expressions[expression.expression] = expression
+	}
+	return expressions, nil
+}
+
+func (v wireValue) key() string {
+	if v.wireType == protowire.BytesType {
+		return string(v.bytes)
+	}
+	return strconv.FormatUint(v.scalar, 10)
+}
+
+func decodeSignedBound(value uint64) (*big.Float, error) {
+	return new(big.Float).SetInt64(int64(value)), nil
+}
+
+func decodeUnsignedBound(value uint64) (*big.Float, error) {
+	return new(big.Float).SetUint64(value), nil
+}
+
+func decodeZigZagBound(value uint64) (*big.Float, error) {
+	return new(big.Float).SetInt64(protowire.DecodeZigZag(value)), nil
+}
+
+// decodeSecondsAndNanosBound decodes a google.protobuf.Duration or google.protobuf.Timestamp.
+func decodeSecondsAndNanosBound(value wireValue) (*big.Float, error) {
+	if value.wireType != protowire.BytesType {
+		return nil, errors.New("bound is not a message")
+	}
+	message, err := parseWireMessage(value.bytes)
+	if err != nil {
+		return nil, err
+	}
+	result := new(big.Float).SetPrec(128)
+	if seconds, ok := message.last(1); ok {
+		result.SetInt64(int64(seconds.scalar))
+	}
+	if nanos, ok := message.last(2); ok {
+		nanosFloat := new(big.Float).SetPrec(128).SetInt64(int64(int32(nanos.scalar)))
+		result.Add(result, nanosFloat.Quo(nanosFloat, big.NewFloat(1e9)))
+	}
+	return result, nil
+}
+
+func newBigFloatFromFloat64(value float64) (*big.Float, error) {
+	if math.IsNaN(value) {
+		return nil, errors.New("bound is NaN")
+	}
+	return big.NewFloat(value), nil
+}
+
+// isValidationKeySubset returns true if all keys of subset are in superset.
+func isValidationKeySubset(subset map[string]struct{}, superset map[string]struct{}) bool {
+	for key := range subset {
+		if _, ok := superset[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func joinValidationPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func sortedValidationNumbers[V any](m map[protowire.Number]V) []protowire.Number {
+	numbers := make([]protowire.Number, 0, len(m))
+	for number := range m {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i int, j int) bool { return numbers[i] < numbers[j] })
+	return numbers
+}
@@ -25,13 +25,16 @@ var (
 	v1RuleBuilders = []*internal.RuleBuilder{
 		bufbreakingbuild.EnumNoDeleteRuleBuilder,
 		bufbreakingbuild.EnumValueNoDeleteRuleBuilder,
+		bufbreakingbuild.EnumValueNoDeleteUnlessDeprecatedRuleBuilder,
 		bufbreakingbuild.EnumValueNoDeleteUnlessNameReservedRuleBuilder,
 		bufbreakingbuild.EnumValueNoDeleteUnlessNumberReservedRuleBuilder,
 		bufbreakingbuild.EnumValueSameNameRuleBuilder,
 		bufbreakingbuild.ExtensionMessageNoDeleteRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteRuleBuilder,
+		bufbreakingbuild.FieldNoDeleteUnlessDeprecatedRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteUnlessNameReservedRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteUnlessNumberReservedRuleBuilder,
+		bufbreakingbuild.FieldNoValidationNarrowingRuleBuilder,
 		bufbreakingbuild.FieldSameCTypeRuleBuilder,
 		bufbreakingbuild.FieldSameJSONNameRuleBuilder,
 		bufbreakingbuild.FieldSameJSTypeRuleBuilder,
@@ -63,10 +66,13 @@ var (
 		bufbreakingbuild.FileSameCcEnableArenasRuleBuilder,
 		bufbreakingbuild.FileSameSyntaxRuleBuilder,
 		bufbreakingbuild.MessageNoDeleteRuleBuilder,
+		bufbreakingbuild.MessageNoDeleteUnlessDeprecatedRuleBuilder,
 		bufbreakingbuild.MessageNoRemoveStandardDescriptorAccessorRuleBuilder,
+		bufbreakingbuild.MessageNoValidationNarrowingRuleBuilder,
 		bufbreakingbuild.MessageSameMessageSetWireFormatRuleBuilder,
 		bufbreakingbuild.MessageSameRequiredFieldsRuleBuilder,
 		bufbreakingbuild.OneofNoDeleteRuleBuilder,
+		bufbreakingbuild.OneofNoValidationNarrowingRuleBuilder,
 		bufbreakingbuild.PackageEnumNoDeleteRuleBuilder,
 		bufbreakingbuild.PackageMessageNoDeleteRuleBuilder,
 		bufbreakingbuild.PackageNoDeleteRuleBuilder,
@@ -74,6 +80,7 @@ var (
 		bufbreakingbuild.ReservedEnumNoDeleteRuleBuilder,
 		bufbreakingbuild.ReservedMessageNoDeleteRuleBuilder,
 		bufbreakingbuild.RPCNoDeleteRuleBuilder,
+		bufbreakingbuild.RPCNoDeleteUnlessDeprecatedRuleBuilder,
 		bufbreakingbuild.RPCSameClientStreamingRuleBuilder,
 		bufbreakingbuild.RPCSameIdempotencyLevelRuleBuilder,
 		bufbreakingbuild.RPCSameRequestTypeRuleBuilder,
@@ -95,6 +102,9 @@ var (
 			"FILE",
 			"PACKAGE",
 		},
+		"ENUM_VALUE_NO_DELETE_UNLESS_DEPRECATED": {
+			"DEPRECATION",
+		},
 		"ENUM_VALUE_NO_DELETE_UNLESS_NAME_RESERVED": {
 			"WIRE_JSON",
 		},
@@ -115,6 +125,9 @@ var (
 			"FILE",
 			"PACKAGE",
 		},
+		"FIELD_NO_DELETE_UNLESS_DEPRECATED": {
+			"DEPRECATION",
+		},
 		"FIELD_NO_DELETE_UNLESS_NAME_RESERVED": {
 			"WIRE_JSON",
 		},
@@ -122,6 +135,9 @@ var (
 			"WIRE_JSON",
 			"WIRE",
 		},
+		"FIELD_NO_VALIDATION_NARROWING": {
+			"VALIDATION",
+		},
 		"FIELD_SAME_CTYPE": {
 			"FILE",
 			"PACKAGE",
@@ -250,10 +266,16 @@ var (
 		"MESSAGE_NO_DELETE": {
 			"FILE",
 		},
+		"MESSAGE_NO_DELETE_UNLESS_DEPRECATED": {
+			"DEPRECATION",
+		},
 		"MESSAGE_NO_REMOVE_STANDARD_DESCRIPTOR_ACCESSOR": {
 			"FILE",
 			"PACKAGE",
 		},
+		"MESSAGE_NO_VALIDATION_NARROWING": {
+			"VALIDATION",
+		},
 		"MESSAGE_SAME_MESSAGE_SET_WIRE_FORMAT": {
 			"FILE",
 			"PACKAGE",
@@ -270,6 +292,9 @@ var (
 			"FILE",
 			"PACKAGE",
 		},
+		"ONEOF_NO_VALIDATION_NARROWING": {
+			"VALIDATION",
+		},
 		"PACKAGE_ENUM_NO_DELETE": {
 			"PACKAGE",
 		},
@@ -298,6 +323,9 @@ var (
 			"FILE",
 			"PACKAGE",
 		},
+		"RPC_NO_DELETE_UNLESS_DEPRECATED": {
+			"DEPRECATION",
+		},
 		"RPC_SAME_CLIENT_STREAMING": {
 			"FILE",
 			"PACKAGE",
@@ -0,0 +1,21 @@
+#!/bin/sh
+
+request="$(cat)"
+# the previous image must be sent
+case "${request}" in
+  *\"against_image\"*) ;;
+  *) echo "against_image not set" >&2; exit 1 ;;
+esac
+# excluded rules must not be requested
+case "${request}" in
+  *GATEWAY_SERVICE_NO_DELETE*) echo "GATEWAY_SERVICE_NO_DELETE requested" >&2; exit 1 ;;
+esac
+cat <<'RESPONSE'
+{
+  "annotations": [
+    {"path": "a.proto", "start_line": 6, "start_column": 3, "end_line": 6, "end_column": 16, "type": "GATEWAY_PATH_PARAM_SAME_TYPE", "message": "Field \"1\" with name \"id\" on message \"Foo\" changed type from \"string\" to \"int64\"."},
+    {"path": "b/b.proto", "start_line": 6, "start_column": 3, "end_line": 6, "end_column": 16, "type": "GATEWAY_PATH_PARAM_SAME_TYPE", "message": "Field \"1\" with name \"id\" on message \"Bar\" changed type from \"string\" to \"int64\"."},
+    {"path": "c.proto", "type": "GATEWAY_PATH_PARAM_SAME_TYPE", "message": "Previously present file \"c.proto\" with gateway path parameters was deleted."}
+  ]
+}
+RESPONSE
@@ -22,14 +22,20 @@ package bufcheck
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
 	"text/tabwriter"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"go.uber.org/multierr"
 )
 
+// ErrPluginsNotEnabled is returned when rules provided by a check plugin are in use,
+// but running plugins is not enabled.
+var ErrPluginsNotEnabled = errors.New("running plugins is not enabled")
+
 // AllRuleFormatStrings is all rule format strings.
 var AllRuleFormatStrings = []string{
 	"text",
@@ -56,6 +62,20 @@ type Rule interface {
 	Purpose() string
 }
 
+// AnalysisRulesForRules returns the Rules as bufanalysis.Rules.
+//
+// This is used to provide rule metadata when printing FileAnnotations.
+func AnalysisRulesForRules(rules []Rule) []bufanalysis.Rule {
+	if rules == nil {
+		return nil
+	}
+	analysisRules := make([]bufanalysis.Rule, len(rules))
+	for i, rule := range rules {
+		analysisRules[i] = rule
+	}
+	return analysisRules
+}
+
 // PrintRules prints the rules to the writer.
 //
 // The empty string defaults to text.
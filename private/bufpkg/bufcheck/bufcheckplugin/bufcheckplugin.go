@@ -0,0 +1,234 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufcheckplugin contains the functionality for external check plugins.
+//
+// A check plugin is an executable that provides additional lint or breaking
+// change detection rules. The plugin is invoked with a JSON-encoded Request on
+// stdin, and is expected to write a JSON-encoded Response to stdout. Any output
+// on stderr is only used for error messages.
+//
+// The FileAnnotations returned by a plugin are subject to the same ignores as
+// the built-in rules.
+package bufcheckplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	imagev1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/image/v1"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/encoding"
+)
+
+// Config is the configuration for an external check plugin.
+type Config struct {
+	// Path is the path to the plugin executable.
+	//
+	// If the path has more than one element, the first is the plugin binary and the
+	// others are optional additional arguments to pass to the binary.
+	// Always non-empty.
+	Path []string
+	// Rules are the rules that the plugin provides.
+	//
+	// Always non-empty.
+	Rules []*RuleConfig
+	// Options are the plugin-specific options.
+	//
+	// These are passed to the plugin as-is.
+	Options map[string]interface{}
+	// DirPath is the directory of the configuration file that declares the plugin.
+	//
+	// The plugin is run in this directory, and a relative plugin binary path is
+	// resolved against it. If empty, the current directory is used.
+	DirPath string
+}
+
+// Name returns the name of the plugin, for use in messages.
+func (c *Config) Name() string {
+	return c.Path[0]
+}
+
+// RuleConfig is the configuration for a rule provided by an external check plugin.
+type RuleConfig struct {
+	// ID is the ID of the rule.
+	//
+	// UPPER_SNAKE_CASE.
+	ID string
+	// Categories are the categories of the rule.
+	//
+	// UPPER_SNAKE_CASE.
+	// May be empty.
+	Categories []string
+	// Purpose is the purpose of the rule.
+	//
+	// Full sentence.
+	Purpose string
+}
+
+// ExternalConfigV1 is an external plugin config.
+type ExternalConfigV1 struct {
+	// Path is a string or a list of strings.
+	Path    interface{}            `json:"path,omitempty" yaml:"path,omitempty"`
+	Rules   []ExternalRuleConfigV1 `json:"rules,omitempty" yaml:"rules,omitempty"`
+	Options map[string]interface{} `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// ExternalRuleConfigV1 is an external plugin rule config.
+type ExternalRuleConfigV1 struct {
+	ID         string   `json:"id,omitempty" yaml:"id,omitempty"`
+	Categories []string `json:"categories,omitempty" yaml:"categories,omitempty"`
+	Purpose    string   `json:"purpose,omitempty" yaml:"purpose,omitempty"`
+}
+
+// NewConfigsV1 returns new Configs for the ExternalConfigV1s.
+func NewConfigsV1(externalConfigs []ExternalConfigV1) ([]*Config, error) {
+	if len(externalConfigs) == 0 {
+		return nil, nil
+	}
+	configs := make([]*Config, 0, len(externalConfigs))
+	for _, externalConfig := range externalConfigs {
+		path, err := encoding.InterfaceSliceOrStringToStringSlice(externalConfig.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid plugin path: %w", err)
+		}
+		if len(path) == 0 || path[0] == "" {
+			return nil, errors.New("plugin path is required")
+		}
+		if len(externalConfig.Rules) == 0 {
+			return nil, fmt.Errorf("plugin %s: no rules set", path[0])
+		}
+		ruleConfigs := make([]*RuleConfig, 0, len(externalConfig.Rules))
+		for _, externalRuleConfig := range externalConfig.Rules {
+			if externalRuleConfig.ID == "" {
+				return nil, fmt.Errorf("plugin %s: rule id is required", path[0])
+			}
+			ruleConfigs = append(
+				ruleConfigs,
+				&RuleConfig{
+					ID:         externalRuleConfig.ID,
+					Categories: externalRuleConfig.Categories,
+					Purpose:    externalRuleConfig.Purpose,
+				},
+			)
+		}
+		configs = append(
+			configs,
+			&Config{
+				Path:    path,
+				Rules:   ruleConfigs,
+				Options: externalConfig.Options,
+			},
+		)
+	}
+	return configs, nil
+}
+
+// ExternalConfigsV1ForConfigs takes Configs and returns the v1 externalconfig representation.
+func ExternalConfigsV1ForConfigs(configs []*Config) []ExternalConfigV1 {
+	if len(configs) == 0 {
+		return nil
+	}
+	externalConfigs := make([]ExternalConfigV1, 0, len(configs))
+	for _, config := range configs {
+		externalRuleConfigs := make([]ExternalRuleConfigV1, 0, len(config.Rules))
+		for _, ruleConfig := range config.Rules {
+			externalRuleConfigs = append(
+				externalRuleConfigs,
+				ExternalRuleConfigV1{
+					ID:         ruleConfig.ID,
+					Categories: ruleConfig.Categories,
+					Purpose:    ruleConfig.Purpose,
+				},
+			)
+		}
+		var path interface{} = config.Path
+		if len(config.Path) == 1 {
+			path = config.Path[0]
+		}
+		externalConfigs = append(
+			externalConfigs,
+			ExternalConfigV1{
+				Path:    path,
+				Rules:   externalRuleConfigs,
+				Options: config.Options,
+			},
+		)
+	}
+	return externalConfigs
+}
+
+// Request is the request sent to a plugin on stdin, encoded as JSON.
+type Request struct {
+	// Image is the binary-encoded buf.alpha.image.v1.Image to check.
+	//
+	// This is wire-compatible with google.protobuf.FileDescriptorSet.
+	Image []byte `json:"image,omitempty"`
+	// AgainstImage is the binary-encoded buf.alpha.image.v1.Image to check against.
+	//
+	// This is only set for breaking change detection.
+	AgainstImage []byte `json:"against_image,omitempty"`
+	// RuleIDs are the IDs of the rules that the plugin should run.
+	//
+	// Only FileAnnotations for these rules are accepted.
+	RuleIDs []string `json:"rule_ids,omitempty"`
+	// Options are the plugin-specific options from the configuration.
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// Response is the response read from a plugin on stdout, encoded as JSON.
+type Response struct {
+	// Annotations are the annotations produced by the plugin.
+	Annotations []*Annotation `json:"annotations,omitempty"`
+}
+
+// Annotation is a single annotation produced by a plugin.
+//
+// This has the same shape as the FileAnnotations printed by buf with --error-format=json.
+type Annotation struct {
+	// Path is the path of the file relative to the root of the Image.
+	Path string `json:"path,omitempty"`
+	// StartLine is the 1-indexed starting line, or 0 if unknown.
+	StartLine int `json:"start_line,omitempty"`
+	// StartColumn is the 1-indexed starting column, or 0 if unknown.
+	StartColumn int `json:"start_column,omitempty"`
+	// EndLine is the 1-indexed ending line, or 0 if unknown.
+	EndLine int `json:"end_line,omitempty"`
+	// EndColumn is the 1-indexed ending column, or 0 if unknown.
+	EndColumn int `json:"end_column,omitempty"`
+	// Type is the ID of the rule that produced the annotation.
+	Type string `json:"type,omitempty"`
+	// Message is the message of the annotation.
+	Message string `json:"message,omitempty"`
+}
+
+// Run runs the plugin and returns the resulting FileAnnotations.
+//
+// againstImage is only set for breaking change detection.
+//
+// The FileInfos of the returned FileAnnotations only have paths set, and the
+// external paths are equal to the paths. Callers are expected to resolve
+// the paths against the files they checked.
+func Run(
+	ctx context.Context,
+	runner command.Runner,
+	config *Config,
+	image *imagev1.Image,
+	againstImage *imagev1.Image,
+	ruleIDs []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	return run(ctx, runner, config, image, againstImage, ruleIDs)
+}
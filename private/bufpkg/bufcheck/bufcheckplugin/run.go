@@ -0,0 +1,154 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcheckplugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	imagev1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/image/v1"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/encoding"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func run(
+	ctx context.Context,
+	runner command.Runner,
+	config *Config,
+	image *imagev1.Image,
+	againstImage *imagev1.Image,
+	ruleIDs []string,
+) (_ []bufanalysis.FileAnnotation, retErr error) {
+	ctx, span := otel.GetTracerProvider().Tracer("bufbuild/buf").Start(
+		ctx,
+		"check_plugin",
+		trace.WithAttributes(
+			attribute.Key("plugin").String(config.Name()),
+		),
+	)
+	defer span.End()
+	defer func() {
+		if retErr != nil {
+			span.RecordError(retErr)
+			span.SetStatus(codes.Error, retErr.Error())
+		}
+	}()
+	request := &Request{
+		RuleIDs: ruleIDs,
+		Options: config.Options,
+	}
+	var err error
+	request.Image, err = marshalImage(image)
+	if err != nil {
+		return nil, err
+	}
+	if againstImage != nil {
+		request.AgainstImage, err = marshalImage(againstImage)
+		if err != nil {
+			return nil, err
+		}
+	}
+	requestData, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	runOptions := []command.RunOption{
+		command.RunWithStdin(bytes.NewReader(requestData)),
+		command.RunWithStdout(stdout),
+		command.RunWithStderr(stderr),
+	}
+	if len(config.Path) > 1 {
+		runOptions = append(runOptions, command.RunWithArgs(config.Path[1:]...))
+	}
+	pluginPath := config.Path[0]
+	if config.DirPath != "" {
+		dirPath, err := filepath.Abs(config.DirPath)
+		if err != nil {
+			return nil, err
+		}
+		runOptions = append(runOptions, command.RunWithDir(dirPath))
+		// A binary name without a separator is looked up on the PATH.
+		if filepath.Base(pluginPath) != pluginPath && !filepath.IsAbs(pluginPath) {
+			pluginPath = filepath.Join(dirPath, pluginPath)
+		}
+	}
+	if err := runner.Run(ctx, pluginPath, runOptions...); err != nil {
+		if stderrString := strings.TrimSpace(stderr.String()); stderrString != "" {
+			return nil, fmt.Errorf("plugin %s: %w: %s", config.Name(), err, stderrString)
+		}
+		return nil, fmt.Errorf("plugin %s: %w", config.Name(), err)
+	}
+	response := &Response{}
+	if err := encoding.UnmarshalJSONNonStrict(stdout.Bytes(), response); err != nil {
+		return nil, fmt.Errorf("plugin %s: invalid response: %w", config.Name(), err)
+	}
+	fileAnnotations := make([]bufanalysis.FileAnnotation, 0, len(response.Annotations))
+	for _, annotation := range response.Annotations {
+		if annotation == nil {
+			continue
+		}
+		var annotationFileInfo bufanalysis.FileInfo
+		if annotation.Path != "" {
+			annotationFileInfo = newFileInfo(annotation.Path)
+		}
+		fileAnnotations = append(
+			fileAnnotations,
+			bufanalysis.NewFileAnnotation(
+				annotationFileInfo,
+				annotation.StartLine,
+				annotation.StartColumn,
+				annotation.EndLine,
+				annotation.EndColumn,
+				annotation.Type,
+				annotation.Message,
+			),
+		)
+	}
+	return fileAnnotations, nil
+}
+
+func marshalImage(image *imagev1.Image) ([]byte, error) {
+	return protoencoding.NewWireMarshaler().Marshal(image)
+}
+
+type fileInfo struct {
+	path string
+}
+
+func newFileInfo(path string) *fileInfo {
+	return &fileInfo{
+		path: path,
+	}
+}
+
+func (f *fileInfo) Path() string {
+	return f.path
+}
+
+func (f *fileInfo) ExternalPath() string {
+	return f.path
+}
@@ -23,12 +23,14 @@ import (
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis/bufanalysistesting"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimagebuild"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/stretchr/testify/assert"
@@ -148,6 +150,39 @@ func TestRunComments(t *testing.T) {
 	)
 }
 
+func TestRunCommentQuality(t *testing.T) {
+	testLint(
+		t,
+		"comment_quality",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 10, 3, 10, 19, "COMMENT_FIELD_MIN_LENGTH"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 12, 3, 12, 20, "COMMENT_FIELD_NAME_PREFIX"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 14, 3, 14, 26, "COMMENT_FIELD_NO_TODO"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 28, 3, 28, 35, "COMMENT_RPC_MIN_LENGTH"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 28, 3, 28, 35, "COMMENT_RPC_NAME_PREFIX"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 30, 3, 30, 36, "COMMENT_RPC_NO_TODO"),
+	)
+}
+
+func TestRunCommentQualityDefault(t *testing.T) {
+	testLint(
+		t,
+		"comment_quality_default",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 7, 3, 7, 17, "COMMENT_FIELD_MIN_LENGTH"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 11, 3, 11, 20, "COMMENT_FIELD_NO_TODO"),
+	)
+}
+
+func TestRunFieldNumbers(t *testing.T) {
+	testLint(
+		t,
+		"field_numbers",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 18, 1, 23, 2, "MESSAGE_FIELD_NUMBERS_CONTIGUOUS"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 31, 1, 34, 2, "MESSAGE_FIELD_NUMBERS_CONTIGUOUS"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 33, 14, 33, 24, "EXTENSION_RANGE_EXPLICIT_BOUNDS"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 38, 14, 38, 24, "MESSAGE_NO_IMPLEMENTATION_RESERVED_NUMBERS"),
+	)
+}
+
 func TestRunDirectorySamePackage(t *testing.T) {
 	testLint(
 		t,
@@ -786,6 +821,27 @@ func TestRunIgnores4(t *testing.T) {
 	)
 }
 
+func TestRunPlugin(t *testing.T) {
+	testLint(
+		t,
+		"plugin",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 8, 3, 8, 22, "COMPANY_FIELD_NAME_BANNED"),
+	)
+}
+
+func TestRunPluginNotEnabled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	config, image := testGetConfigAndImage(ctx, t, "plugin", nil)
+	_, err := buflint.NewHandler(zap.NewNop(), command.NewRunner()).Check(
+		ctx,
+		config.Lint,
+		image,
+	)
+	assert.ErrorIs(t, err, bufcheck.ErrPluginsNotEnabled)
+}
+
 func TestCommentIgnoresOff(t *testing.T) {
 	testLint(
 		t,
@@ -912,6 +968,28 @@ func testLintConfigModifier(
 	defer cancel()
 	logger := zap.NewNop()
 
+	config, image := testGetConfigAndImage(ctx, t, relDirPath, configModifier)
+
+	handler := buflint.NewHandler(logger, command.NewRunner(), buflint.HandlerWithPlugins())
+	fileAnnotations, err := handler.Check(
+		ctx,
+		config.Lint,
+		image,
+	)
+	assert.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		expectedFileAnnotations,
+		fileAnnotations,
+	)
+}
+
+func testGetConfigAndImage(
+	ctx context.Context,
+	t *testing.T,
+	relDirPath string,
+	configModifier func(*bufconfig.Config),
+) (*bufconfig.Config, bufimage.Image) {
 	dirPath := filepath.Join("testdata", relDirPath)
 
 	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
@@ -947,19 +1025,7 @@ func testLintConfigModifier(
 	require.NoError(t, err)
 	require.Empty(t, fileAnnotations)
 	image = bufimage.ImageWithoutImports(image)
-
-	handler := buflint.NewHandler(logger)
-	fileAnnotations, err = handler.Check(
-		ctx,
-		config.Lint,
-		image,
-	)
-	assert.NoError(t, err)
-	bufanalysistesting.AssertFileAnnotationsEqual(
-		t,
-		expectedFileAnnotations,
-		fileAnnotations,
-	)
+	return config, image
 }
 
 func testGetConfig(
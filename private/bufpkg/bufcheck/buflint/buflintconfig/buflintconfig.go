@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufcheckplugin"
 	lintv1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/lint/v1"
 )
 
@@ -58,8 +59,24 @@ type Config struct {
 	// ServiceSuffix applies to the SERVICE_SUFFIX rule ID. By default, the rule verifies that all service names
 	// end with the suffix Service. This allows users to override the value with the given string.
 	ServiceSuffix string
+	// CommentMinLength applies to the COMMENT_*_MIN_LENGTH rule IDs. By default, the rules verify that comments
+	// are at least 10 characters long. This allows users to override the value with the given length.
+	//
+	// CommentMinLength is only supported for v1 and is not part of the proto representation of the Config.
+	CommentMinLength int
+	// CommentTODOMarkers applies to the COMMENT_*_NO_TODO rule IDs. By default, the rules verify that comments
+	// do not contain the markers TODO, FIXME and XXX. This allows users to override the markers with the given values.
+	//
+	// CommentTODOMarkers is only supported for v1 and is not part of the proto representation of the Config.
+	CommentTODOMarkers []string
 	// AllowCommentIgnores turns on comment-driven ignores.
 	AllowCommentIgnores bool
+	// Plugins are the external check plugins that provide additional lint rules.
+	//
+	// Rules provided by plugins can be used in Use, Except and IgnoreIDOrCategoryToRootPaths
+	// like built-in rules. Plugins are only supported for v1 and are not part of the proto
+	// representation of the Config.
+	Plugins []*bufcheckplugin.Config
 	// Version represents the version of the lint rule and category IDs that should be used with this config.
 	Version string
 }
@@ -82,7 +99,11 @@ func NewConfigV1Beta1(externalConfig ExternalConfigV1Beta1) *Config {
 }
 
 // NewConfigV1 returns a new Config.
-func NewConfigV1(externalConfig ExternalConfigV1) *Config {
+func NewConfigV1(externalConfig ExternalConfigV1) (*Config, error) {
+	plugins, err := bufcheckplugin.NewConfigsV1(externalConfig.Plugins)
+	if err != nil {
+		return nil, err
+	}
 	return &Config{
 		Use:                                  externalConfig.Use,
 		Except:                               externalConfig.Except,
@@ -93,9 +114,12 @@ func NewConfigV1(externalConfig ExternalConfigV1) *Config {
 		RPCAllowGoogleProtobufEmptyRequests:  externalConfig.RPCAllowGoogleProtobufEmptyRequests,
 		RPCAllowGoogleProtobufEmptyResponses: externalConfig.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        externalConfig.ServiceSuffix,
+		CommentMinLength:                     externalConfig.CommentMinLength,
+		CommentTODOMarkers:                   externalConfig.CommentTODOMarkers,
 		AllowCommentIgnores:                  externalConfig.AllowCommentIgnores,
+		Plugins:                              plugins,
 		Version:                              v1Version,
-	}
+	}, nil
 }
 
 // ConfigForProto returns the Config given the proto.
@@ -155,13 +179,16 @@ type ExternalConfigV1 struct {
 	// IgnoreRootPaths
 	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
 	// IgnoreIDOrCategoryToRootPaths
-	IgnoreOnly                           map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
-	EnumZeroValueSuffix                  string              `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
-	RPCAllowSameRequestResponse          bool                `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
-	RPCAllowGoogleProtobufEmptyRequests  bool                `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
-	RPCAllowGoogleProtobufEmptyResponses bool                `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
-	ServiceSuffix                        string              `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
-	AllowCommentIgnores                  bool                `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	IgnoreOnly                           map[string][]string               `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
+	EnumZeroValueSuffix                  string                            `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
+	RPCAllowSameRequestResponse          bool                              `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
+	RPCAllowGoogleProtobufEmptyRequests  bool                              `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
+	RPCAllowGoogleProtobufEmptyResponses bool                              `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
+	ServiceSuffix                        string                            `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
+	CommentMinLength                     int                               `json:"comment_min_length,omitempty" yaml:"comment_min_length,omitempty"`
+	CommentTODOMarkers                   []string                          `json:"comment_todo_markers,omitempty" yaml:"comment_todo_markers,omitempty"`
+	AllowCommentIgnores                  bool                              `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	Plugins                              []bufcheckplugin.ExternalConfigV1 `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
 // ExternalConfigV1Beta1ForConfig takes a *Config and returns the v1beta1 externalconfig representation.
@@ -192,7 +219,10 @@ func ExternalConfigV1ForConfig(config *Config) ExternalConfigV1 {
 		RPCAllowGoogleProtobufEmptyRequests:  config.RPCAllowGoogleProtobufEmptyRequests,
 		RPCAllowGoogleProtobufEmptyResponses: config.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        config.ServiceSuffix,
+		CommentMinLength:                     config.CommentMinLength,
+		CommentTODOMarkers:                   config.CommentTODOMarkers,
 		AllowCommentIgnores:                  config.AllowCommentIgnores,
+		Plugins:                              bufcheckplugin.ExternalConfigsV1ForConfigs(config.Plugins),
 	}
 }
 
@@ -207,17 +237,20 @@ func BytesForConfig(config *Config) ([]byte, error) {
 }
 
 type configJSON struct {
-	Use                                  []string      `json:"use,omitempty"`
-	Except                               []string      `json:"except,omitempty"`
-	IgnoreRootPaths                      []string      `json:"ignore_root_paths,omitempty"`
-	IgnoreIDOrCategoryToRootPaths        []idPathsJSON `json:"ignore_id_to_root_paths,omitempty"`
-	EnumZeroValueSuffix                  string        `json:"enum_zero_value_suffix,omitempty"`
-	RPCAllowSameRequestResponse          bool          `json:"rpc_allow_same_request_response,omitempty"`
-	RPCAllowGoogleProtobufEmptyRequests  bool          `json:"rpc_allow_google_protobuf_empty_requests,omitempty"`
-	RPCAllowGoogleProtobufEmptyResponses bool          `json:"rpc_allow_google_protobuf_empty_response,omitempty"`
-	ServiceSuffix                        string        `json:"service_suffix,omitempty"`
-	AllowCommentIgnores                  bool          `json:"allow_comment_ignores,omitempty"`
-	Version                              string        `json:"version,omitempty"`
+	Use                                  []string                          `json:"use,omitempty"`
+	Except                               []string                          `json:"except,omitempty"`
+	IgnoreRootPaths                      []string                          `json:"ignore_root_paths,omitempty"`
+	IgnoreIDOrCategoryToRootPaths        []idPathsJSON                     `json:"ignore_id_to_root_paths,omitempty"`
+	EnumZeroValueSuffix                  string                            `json:"enum_zero_value_suffix,omitempty"`
+	RPCAllowSameRequestResponse          bool                              `json:"rpc_allow_same_request_response,omitempty"`
+	RPCAllowGoogleProtobufEmptyRequests  bool                              `json:"rpc_allow_google_protobuf_empty_requests,omitempty"`
+	RPCAllowGoogleProtobufEmptyResponses bool                              `json:"rpc_allow_google_protobuf_empty_response,omitempty"`
+	ServiceSuffix                        string                            `json:"service_suffix,omitempty"`
+	CommentMinLength                     int                               `json:"comment_min_length,omitempty"`
+	CommentTODOMarkers                   []string                          `json:"comment_todo_markers,omitempty"`
+	AllowCommentIgnores                  bool                              `json:"allow_comment_ignores,omitempty"`
+	Plugins                              []bufcheckplugin.ExternalConfigV1 `json:"plugins,omitempty"`
+	Version                              string                            `json:"version,omitempty"`
 }
 
 type idPathsJSON struct {
@@ -248,6 +281,12 @@ func configToJSON(config *Config) *configJSON {
 	sort.Strings(use)
 	sort.Strings(except)
 	sort.Strings(ignoreRootPaths)
+	var commentTODOMarkers []string
+	if len(config.CommentTODOMarkers) > 0 {
+		commentTODOMarkers = make([]string, len(config.CommentTODOMarkers))
+		copy(commentTODOMarkers, config.CommentTODOMarkers)
+		sort.Strings(commentTODOMarkers)
+	}
 	return &configJSON{
 		Use:                                  use,
 		Except:                               except,
@@ -258,7 +297,10 @@ func configToJSON(config *Config) *configJSON {
 		RPCAllowGoogleProtobufEmptyRequests:  config.RPCAllowGoogleProtobufEmptyRequests,
 		RPCAllowGoogleProtobufEmptyResponses: config.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        config.ServiceSuffix,
+		CommentMinLength:                     config.CommentMinLength,
+		CommentTODOMarkers:                   commentTODOMarkers,
 		AllowCommentIgnores:                  config.AllowCommentIgnores,
+		Plugins:                              bufcheckplugin.ExternalConfigsV1ForConfigs(config.Plugins),
 		Version:                              config.Version,
 	}
 }
@@ -270,12 +312,13 @@ func PrintFileAnnotations(
 	writer io.Writer,
 	fileAnnotations []bufanalysis.FileAnnotation,
 	formatString string,
+	options ...bufanalysis.PrintFileAnnotationsOption,
 ) error {
 	switch s := strings.ToLower(strings.TrimSpace(formatString)); s {
 	case "config-ignore-yaml":
 		return printFileAnnotationsConfigIgnoreYAML(writer, fileAnnotations)
 	default:
-		return bufanalysis.PrintFileAnnotations(writer, fileAnnotations, s)
+		return bufanalysis.PrintFileAnnotations(writer, fileAnnotations, s, options...)
 	}
 }
 
@@ -17,25 +17,32 @@ package buflint
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufcheckplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/buflintconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/internal/buflintcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/protosource"
 	"go.uber.org/zap"
 )
 
 type handler struct {
-	logger *zap.Logger
-	runner *internal.Runner
+	logger        *zap.Logger
+	runner        *internal.Runner
+	commandRunner command.Runner
+	runPlugins    bool
 }
 
-func newHandler(logger *zap.Logger) *handler {
-	return &handler{
-		logger: logger,
+func newHandler(logger *zap.Logger, commandRunner command.Runner, options ...HandlerOption) *handler {
+	handler := &handler{
+		logger:        logger,
+		commandRunner: commandRunner,
 		// linting allows for comment ignores
 		// note that comment ignores still need to be enabled within the config
 		// for a given check, this just says that comment ignores are allowed
@@ -45,6 +52,10 @@ func newHandler(logger *zap.Logger) *handler {
 			internal.RunnerWithIgnorePrefix(buflintcheck.CommentIgnorePrefix),
 		),
 	}
+	for _, option := range options {
+		option(handler)
+	}
+	return handler
 }
 
 func (h *handler) Check(
@@ -56,9 +67,29 @@ func (h *handler) Check(
 	if err != nil {
 		return nil, err
 	}
-	internalConfig, err := internalConfigForConfig(config)
+	internalConfig, err := internalConfigForConfig(config, h.newPluginCheckFuncForImage(image))
 	if err != nil {
 		return nil, err
 	}
 	return h.runner.Check(ctx, internalConfig, nil, files)
+}
+
+func (h *handler) newPluginCheckFuncForImage(image bufimage.Image) func(*bufcheckplugin.Config) internal.PluginCheckFunc {
+	return func(pluginConfig *bufcheckplugin.Config) internal.PluginCheckFunc {
+		if !h.runPlugins {
+			return func(context.Context, []string) ([]bufanalysis.FileAnnotation, error) {
+				return nil, fmt.Errorf("lint plugin %s has rules in use: %w", pluginConfig.Name(), bufcheck.ErrPluginsNotEnabled)
+			}
+		}
+		return func(ctx context.Context, ruleIDs []string) ([]bufanalysis.FileAnnotation, error) {
+			return bufcheckplugin.Run(
+				ctx,
+				h.commandRunner,
+				pluginConfig,
+				bufimage.ImageToProtoImage(image),
+				nil,
+				ruleIDs,
+			)
+		}
+	}
 }
\ No newline at end of file
@@ -18,6 +18,8 @@ package buflintbuild
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/buflint/internal/buflintcheck"
@@ -44,6 +46,24 @@ var (
 		"fields have non-empty comments",
 		newAdapter(buflintcheck.CheckCommentField),
 	)
+	// CommentFieldMinLengthRuleBuilder is a rule builder.
+	CommentFieldMinLengthRuleBuilder = newCommentMinLengthRuleBuilder(
+		"COMMENT_FIELD_MIN_LENGTH",
+		"fields",
+		buflintcheck.CheckCommentFieldMinLength,
+	)
+	// CommentFieldNamePrefixRuleBuilder is a rule builder.
+	CommentFieldNamePrefixRuleBuilder = internal.NewNopRuleBuilder(
+		"COMMENT_FIELD_NAME_PREFIX",
+		"field comments begin with the name of the field",
+		newAdapter(buflintcheck.CheckCommentFieldNamePrefix),
+	)
+	// CommentFieldNoTODORuleBuilder is a rule builder.
+	CommentFieldNoTODORuleBuilder = newCommentNoTODORuleBuilder(
+		"COMMENT_FIELD_NO_TODO",
+		"fields",
+		buflintcheck.CheckCommentFieldNoTODO,
+	)
 	// CommentMessageRuleBuilder is a rule builder.
 	CommentMessageRuleBuilder = internal.NewNopRuleBuilder(
 		"COMMENT_MESSAGE",
@@ -62,6 +82,24 @@ var (
 		"RPCs have non-empty comments",
 		newAdapter(buflintcheck.CheckCommentRPC),
 	)
+	// CommentRPCMinLengthRuleBuilder is a rule builder.
+	CommentRPCMinLengthRuleBuilder = newCommentMinLengthRuleBuilder(
+		"COMMENT_RPC_MIN_LENGTH",
+		"RPCs",
+		buflintcheck.CheckCommentRPCMinLength,
+	)
+	// CommentRPCNamePrefixRuleBuilder is a rule builder.
+	CommentRPCNamePrefixRuleBuilder = internal.NewNopRuleBuilder(
+		"COMMENT_RPC_NAME_PREFIX",
+		"RPC comments begin with the name of the RPC",
+		newAdapter(buflintcheck.CheckCommentRPCNamePrefix),
+	)
+	// CommentRPCNoTODORuleBuilder is a rule builder.
+	CommentRPCNoTODORuleBuilder = newCommentNoTODORuleBuilder(
+		"COMMENT_RPC_NO_TODO",
+		"RPCs",
+		buflintcheck.CheckCommentRPCNoTODO,
+	)
 	// CommentServiceRuleBuilder is a rule builder.
 	CommentServiceRuleBuilder = internal.NewNopRuleBuilder(
 		"COMMENT_SERVICE",
@@ -122,6 +160,12 @@ var (
 			}), nil
 		},
 	)
+	// ExtensionRangeExplicitBoundsRuleBuilder is a rule builder.
+	ExtensionRangeExplicitBoundsRuleBuilder = internal.NewNopRuleBuilder(
+		"EXTENSION_RANGE_EXPLICIT_BOUNDS",
+		"extension ranges have an explicit upper bound instead of max",
+		newAdapter(buflintcheck.CheckExtensionRangeExplicitBounds),
+	)
 	// FieldLowerSnakeCaseRuleBuilder is a rule builder.
 	FieldLowerSnakeCaseRuleBuilder = internal.NewNopRuleBuilder(
 		"FIELD_LOWER_SNAKE_CASE",
@@ -158,6 +202,18 @@ var (
 		"imports are used",
 		newAdapter(buflintcheck.CheckImportUsed),
 	)
+	// MessageFieldNumbersContiguousRuleBuilder is a rule builder.
+	MessageFieldNumbersContiguousRuleBuilder = internal.NewNopRuleBuilder(
+		"MESSAGE_FIELD_NUMBERS_CONTIGUOUS",
+		"message field numbers are contiguous, with gaps only for reserved numbers and extension ranges",
+		newAdapter(buflintcheck.CheckMessageFieldNumbersContiguous),
+	)
+	// MessageNoImplementationReservedNumbersRuleBuilder is a rule builder.
+	MessageNoImplementationReservedNumbersRuleBuilder = internal.NewNopRuleBuilder(
+		"MESSAGE_NO_IMPLEMENTATION_RESERVED_NUMBERS",
+		"message fields and explicitly bounded extension ranges do not use the numbers 19000 to 19999 reserved for the Protobuf implementation",
+		newAdapter(buflintcheck.CheckMessageNoImplementationReservedNumbers),
+	)
 	// MessagePascalCaseRuleBuilder is a rule builder.
 	MessagePascalCaseRuleBuilder = internal.NewNopRuleBuilder(
 		"MESSAGE_PASCAL_CASE",
@@ -351,6 +407,54 @@ var (
 	)
 )
 
+func newCommentMinLengthRuleBuilder(
+	id string,
+	typeNames string,
+	f func(string, internal.IgnoreFunc, []protosource.File, int) ([]bufanalysis.FileAnnotation, error),
+) *internal.RuleBuilder {
+	return internal.NewRuleBuilder(
+		id,
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			if configBuilder.CommentMinLength <= 0 {
+				return "", fmt.Errorf("comment_min_length must be positive but was %d", configBuilder.CommentMinLength)
+			}
+			return fmt.Sprintf("%s have comments of at least %d characters (length is configurable)", typeNames, configBuilder.CommentMinLength), nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			if configBuilder.CommentMinLength <= 0 {
+				return nil, fmt.Errorf("comment_min_length must be positive but was %d", configBuilder.CommentMinLength)
+			}
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return f(id, ignoreFunc, files, configBuilder.CommentMinLength)
+			}), nil
+		},
+	)
+}
+
+func newCommentNoTODORuleBuilder(
+	id string,
+	typeNames string,
+	f func(string, internal.IgnoreFunc, []protosource.File, []string) ([]bufanalysis.FileAnnotation, error),
+) *internal.RuleBuilder {
+	return internal.NewRuleBuilder(
+		id,
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			if len(configBuilder.CommentTODOMarkers) == 0 {
+				return "", errors.New("comment_todo_markers is empty")
+			}
+			return typeNames + " do not have comments containing " + strings.Join(configBuilder.CommentTODOMarkers, ", ") + " (markers are configurable)", nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			if len(configBuilder.CommentTODOMarkers) == 0 {
+				return nil, errors.New("comment_todo_markers is empty")
+			}
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return f(id, ignoreFunc, files, configBuilder.CommentTODOMarkers)
+			}), nil
+		},
+	)
+}
+
 func newAdapter(
 	f func(string, internal.IgnoreFunc, []protosource.File) ([]bufanalysis.FileAnnotation, error),
 ) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
@@ -39,6 +40,11 @@ const (
 	// This is also used in buflint when constructing a new Runner, and is passed to the
 	// RunnerWithIgnorePrefix option.
 	CommentIgnorePrefix = "buf:lint:ignore"
+
+	// implementationReservedStart and implementationReservedEnd are the inclusive bounds of the
+	// field numbers reserved for the Protobuf implementation.
+	implementationReservedStart = 19000
+	implementationReservedEnd   = 19999
 )
 
 var (
@@ -102,6 +108,137 @@ func checkCommentNamedDescriptor(
 	return nil
 }
 
+// CheckCommentFieldMinLength is a check function.
+var CheckCommentFieldMinLength = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	minLength int,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newFieldCheckFunc(
+		func(add addFunc, field protosource.Field) error {
+			return checkCommentMinLength(add, field, "Field", minLength)
+		},
+	)(id, ignoreFunc, files)
+}
+
+// CheckCommentRPCMinLength is a check function.
+var CheckCommentRPCMinLength = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	minLength int,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newMethodCheckFunc(
+		func(add addFunc, method protosource.Method) error {
+			return checkCommentMinLength(add, method, "RPC", minLength)
+		},
+	)(id, ignoreFunc, files)
+}
+
+func checkCommentMinLength(
+	add addFunc,
+	namedDescriptor protosource.NamedDescriptor,
+	typeName string,
+	minLength int,
+) error {
+	location := namedDescriptor.Location()
+	if location == nil {
+		return nil
+	}
+	comment := leadingCommentText(location.LeadingComments())
+	// missing comments are reported by the COMMENT_* rules
+	if comment == "" {
+		return nil
+	}
+	if length := utf8.RuneCountInString(comment); length < minLength {
+		add(namedDescriptor, location, nil, "%s %q should have a comment of at least %d characters for documentation, but its comment has %d characters.", typeName, namedDescriptor.Name(), minLength, length)
+	}
+	return nil
+}
+
+// CheckCommentFieldNamePrefix is a check function.
+var CheckCommentFieldNamePrefix = newFieldCheckFunc(checkCommentFieldNamePrefix)
+
+func checkCommentFieldNamePrefix(add addFunc, field protosource.Field) error {
+	return checkCommentNamePrefix(add, field, "Field")
+}
+
+// CheckCommentRPCNamePrefix is a check function.
+var CheckCommentRPCNamePrefix = newMethodCheckFunc(checkCommentRPCNamePrefix)
+
+func checkCommentRPCNamePrefix(add addFunc, method protosource.Method) error {
+	return checkCommentNamePrefix(add, method, "RPC")
+}
+
+func checkCommentNamePrefix(
+	add addFunc,
+	namedDescriptor protosource.NamedDescriptor,
+	typeName string,
+) error {
+	location := namedDescriptor.Location()
+	if location == nil {
+		return nil
+	}
+	words := strings.Fields(leadingCommentText(location.LeadingComments()))
+	// missing comments are reported by the COMMENT_* rules
+	if len(words) == 0 {
+		return nil
+	}
+	if strings.TrimRight(words[0], ".,:;") != namedDescriptor.Name() {
+		add(namedDescriptor, location, nil, "%s %q should have a comment that begins with its name %q.", typeName, namedDescriptor.Name(), namedDescriptor.Name())
+	}
+	return nil
+}
+
+// CheckCommentFieldNoTODO is a check function.
+var CheckCommentFieldNoTODO = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	markers []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newFieldCheckFunc(
+		func(add addFunc, field protosource.Field) error {
+			return checkCommentNoTODO(add, field, "Field", markers)
+		},
+	)(id, ignoreFunc, files)
+}
+
+// CheckCommentRPCNoTODO is a check function.
+var CheckCommentRPCNoTODO = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	markers []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newMethodCheckFunc(
+		func(add addFunc, method protosource.Method) error {
+			return checkCommentNoTODO(add, method, "RPC", markers)
+		},
+	)(id, ignoreFunc, files)
+}
+
+func checkCommentNoTODO(
+	add addFunc,
+	namedDescriptor protosource.NamedDescriptor,
+	typeName string,
+	markers []string,
+) error {
+	location := namedDescriptor.Location()
+	if location == nil {
+		return nil
+	}
+	comment := leadingCommentText(location.LeadingComments())
+	for _, marker := range markers {
+		if commentContainsMarker(comment, marker) {
+			add(namedDescriptor, location, nil, "%s %q should not have a %q marker in its comment.", typeName, namedDescriptor.Name(), marker)
+			return nil
+		}
+	}
+	return nil
+}
+
 // CheckDirectorySamePackage is a check function.
 var CheckDirectorySamePackage = newDirToFilesCheckFunc(checkDirectorySamePackage)
 
@@ -263,6 +400,18 @@ func checkEnumZeroValueSuffix(add addFunc, enumValue protosource.EnumValue, suff
 	return nil
 }
 
+// CheckExtensionRangeExplicitBounds is a check function.
+var CheckExtensionRangeExplicitBounds = newMessageCheckFunc(checkExtensionRangeExplicitBounds)
+
+func checkExtensionRangeExplicitBounds(add addFunc, message protosource.Message) error {
+	for _, extensionRange := range message.ExtensionRanges() {
+		if extensionRange.Max() {
+			add(extensionRange, extensionRange.Location(), nil, "Extension range %s on message %q should have an explicit upper bound instead of max.", protosource.TagRangeString(extensionRange), message.Name())
+		}
+	}
+	return nil
+}
+
 // CheckFieldLowerSnakeCase is a check function.
 var CheckFieldLowerSnakeCase = newFieldCheckFunc(checkFieldLowerSnakeCase)
 
@@ -362,6 +511,61 @@ func checkImportUsed(add addFunc, fileImport protosource.FileImport) error {
 	return nil
 }
 
+// CheckMessageFieldNumbersContiguous is a check function.
+var CheckMessageFieldNumbersContiguous = newMessageCheckFunc(checkMessageFieldNumbersContiguous)
+
+func checkMessageFieldNumbersContiguous(add addFunc, message protosource.Message) error {
+	var gaps []string
+	for _, freeMessageRange := range protosource.FreeMessageRanges(message) {
+		// the free range after the highest used number is not a gap
+		if freeMessageRange.Max() {
+			continue
+		}
+		// the numbers reserved for the Protobuf implementation can never be used, so they are not a gap
+		start, end := freeMessageRange.Start(), freeMessageRange.End()
+		if start < implementationReservedStart {
+			gapEnd := end
+			if gapEnd >= implementationReservedStart {
+				gapEnd = implementationReservedStart - 1
+			}
+			gaps = append(gaps, numberRangeString(start, gapEnd))
+		}
+		if end > implementationReservedEnd {
+			gapStart := start
+			if gapStart <= implementationReservedEnd {
+				gapStart = implementationReservedEnd + 1
+			}
+			gaps = append(gaps, numberRangeString(gapStart, end))
+		}
+	}
+	if len(gaps) > 0 {
+		add(message, message.Location(), nil, "Message %q should have contiguous field numbers, but %s are neither used nor reserved.", message.Name(), strings.Join(gaps, ", "))
+	}
+	return nil
+}
+
+// CheckMessageNoImplementationReservedNumbers is a check function.
+var CheckMessageNoImplementationReservedNumbers = newMessageCheckFunc(checkMessageNoImplementationReservedNumbers)
+
+func checkMessageNoImplementationReservedNumbers(add addFunc, message protosource.Message) error {
+	for _, field := range message.Fields() {
+		if number := field.Number(); number >= implementationReservedStart && number <= implementationReservedEnd {
+			add(field, field.NumberLocation(), nil, "Field %q on message %q has number %d, which is in the range %d to %d reserved for the Protobuf implementation.", field.Name(), message.Name(), number, implementationReservedStart, implementationReservedEnd)
+		}
+	}
+	for _, extensionRange := range message.ExtensionRanges() {
+		// "extensions 100 to max" is the common way to declare an open extension range,
+		// so only explicitly bounded ranges are checked.
+		if extensionRange.Max() {
+			continue
+		}
+		if extensionRange.Start() <= implementationReservedEnd && extensionRange.End() >= implementationReservedStart {
+			add(extensionRange, extensionRange.Location(), nil, "Extension range %s on message %q overlaps the range %d to %d reserved for the Protobuf implementation.", protosource.TagRangeString(extensionRange), message.Name(), implementationReservedStart, implementationReservedEnd)
+		}
+	}
+	return nil
+}
+
 // CheckMessagePascalCase is a check function.
 var CheckMessagePascalCase = newMessageCheckFunc(checkMessagePascalCase)
 
@@ -15,7 +15,10 @@
 package buflintcheck
 
 import (
+	"fmt"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal"
@@ -54,6 +57,55 @@ func validLeadingComment(comment string) bool {
 	return false
 }
 
+// leadingCommentText returns the text of the leading comment without comment ignores,
+// with all whitespace collapsed to single spaces.
+func leadingCommentText(comment string) string {
+	var words []string
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, CommentIgnorePrefix) {
+			words = append(words, strings.Fields(line)...)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// commentContainsMarker returns true if the comment contains the marker as a separate word,
+// so that the marker TODO matches "TODO:" and "TODO(user)" but not "TODOS".
+func commentContainsMarker(comment string, marker string) bool {
+	if marker == "" {
+		return false
+	}
+	for offset := 0; offset < len(comment); {
+		index := strings.Index(comment[offset:], marker)
+		if index < 0 {
+			return false
+		}
+		start := offset + index
+		end := start + len(marker)
+		before, _ := utf8.DecodeLastRuneInString(comment[:start])
+		after, _ := utf8.DecodeRuneInString(comment[end:])
+		if (start == 0 || !isCommentWordRune(before)) && (end == len(comment) || !isCommentWordRune(after)) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+func isCommentWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// numberRangeString returns the string representation of the inclusive range of numbers,
+// in the same form as protosource.TagRangeString.
+func numberRangeString(start int, end int) string {
+	if start == end {
+		return fmt.Sprintf("[%d]", start)
+	}
+	return fmt.Sprintf("[%d,%d]", start, end)
+}
+
 // Returns the usedPackageList if there is an import cycle.
 //
 // Note this stops on the first import cycle detected, it doesn't attempt to get all of them - not perfect.
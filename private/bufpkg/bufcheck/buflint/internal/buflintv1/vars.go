@@ -25,9 +25,15 @@ var (
 		buflintbuild.CommentEnumRuleBuilder,
 		buflintbuild.CommentEnumValueRuleBuilder,
 		buflintbuild.CommentFieldRuleBuilder,
+		buflintbuild.CommentFieldMinLengthRuleBuilder,
+		buflintbuild.CommentFieldNamePrefixRuleBuilder,
+		buflintbuild.CommentFieldNoTODORuleBuilder,
 		buflintbuild.CommentMessageRuleBuilder,
 		buflintbuild.CommentOneofRuleBuilder,
 		buflintbuild.CommentRPCRuleBuilder,
+		buflintbuild.CommentRPCMinLengthRuleBuilder,
+		buflintbuild.CommentRPCNamePrefixRuleBuilder,
+		buflintbuild.CommentRPCNoTODORuleBuilder,
 		buflintbuild.CommentServiceRuleBuilder,
 		buflintbuild.DirectorySamePackageRuleBuilder,
 		buflintbuild.EnumFirstValueZeroRuleBuilder,
@@ -36,11 +42,14 @@ var (
 		buflintbuild.EnumValuePrefixRuleBuilder,
 		buflintbuild.EnumValueUpperSnakeCaseRuleBuilder,
 		buflintbuild.EnumZeroValueSuffixRuleBuilder,
+		buflintbuild.ExtensionRangeExplicitBoundsRuleBuilder,
 		buflintbuild.FieldLowerSnakeCaseRuleBuilder,
 		buflintbuild.FileLowerSnakeCaseRuleBuilder,
 		buflintbuild.ImportNoPublicRuleBuilder,
 		buflintbuild.ImportNoWeakRuleBuilder,
 		buflintbuild.ImportUsedRuleBuilder,
+		buflintbuild.MessageFieldNumbersContiguousRuleBuilder,
+		buflintbuild.MessageNoImplementationReservedNumbersRuleBuilder,
 		buflintbuild.MessagePascalCaseRuleBuilder,
 		buflintbuild.OneofLowerSnakeCaseRuleBuilder,
 		buflintbuild.PackageDefinedRuleBuilder,
@@ -82,6 +91,15 @@ var (
 		"COMMENT_FIELD": {
 			"COMMENTS",
 		},
+		"COMMENT_FIELD_MIN_LENGTH": {
+			"COMMENT_QUALITY",
+		},
+		"COMMENT_FIELD_NAME_PREFIX": {
+			"COMMENT_QUALITY",
+		},
+		"COMMENT_FIELD_NO_TODO": {
+			"COMMENT_QUALITY",
+		},
 		"COMMENT_MESSAGE": {
 			"COMMENTS",
 		},
@@ -91,6 +109,15 @@ var (
 		"COMMENT_RPC": {
 			"COMMENTS",
 		},
+		"COMMENT_RPC_MIN_LENGTH": {
+			"COMMENT_QUALITY",
+		},
+		"COMMENT_RPC_NAME_PREFIX": {
+			"COMMENT_QUALITY",
+		},
+		"COMMENT_RPC_NO_TODO": {
+			"COMMENT_QUALITY",
+		},
 		"COMMENT_SERVICE": {
 			"COMMENTS",
 		},
@@ -121,6 +148,9 @@ var (
 		"ENUM_ZERO_VALUE_SUFFIX": {
 			"DEFAULT",
 		},
+		"EXTENSION_RANGE_EXPLICIT_BOUNDS": {
+			"FIELD_NUMBERS",
+		},
 		"FIELD_LOWER_SNAKE_CASE": {
 			"BASIC",
 			"DEFAULT",
@@ -140,6 +170,12 @@ var (
 			"BASIC",
 			"DEFAULT",
 		},
+		"MESSAGE_FIELD_NUMBERS_CONTIGUOUS": {
+			"FIELD_NUMBERS",
+		},
+		"MESSAGE_NO_IMPLEMENTATION_RESERVED_NUMBERS": {
+			"FIELD_NUMBERS",
+		},
 		"MESSAGE_PASCAL_CASE": {
 			"BASIC",
 			"DEFAULT",
@@ -0,0 +1,12 @@
+#!/bin/sh
+
+cat > /dev/null
+cat <<'RESPONSE'
+{
+  "annotations": [
+    {"path": "a.proto", "start_line": 7, "start_column": 3, "end_line": 7, "end_column": 22, "type": "COMPANY_FIELD_NAME_BANNED", "message": "Field name \"bad_one\" is banned."},
+    {"path": "a.proto", "start_line": 8, "start_column": 3, "end_line": 8, "end_column": 22, "type": "COMPANY_FIELD_NAME_BANNED", "message": "Field name \"bad_two\" is banned."},
+    {"path": "b/b.proto", "start_line": 6, "start_column": 3, "end_line": 6, "end_column": 24, "type": "COMPANY_FIELD_NAME_BANNED", "message": "Field name \"bad_three\" is banned."}
+  ]
+}
+RESPONSE
@@ -27,8 +27,15 @@ import (
 const (
 	defaultEnumZeroValueSuffix = "_UNSPECIFIED"
 	defaultServiceSuffix       = "Service"
+	defaultCommentMinLength    = 10
 )
 
+var defaultCommentTODOMarkers = []string{
+	"TODO",
+	"FIXME",
+	"XXX",
+}
+
 // Config is the check config.
 type Config struct {
 	// Rules are the rules to run.
@@ -60,6 +67,14 @@ type ConfigBuilder struct {
 	RPCAllowGoogleProtobufEmptyRequests  bool
 	RPCAllowGoogleProtobufEmptyResponses bool
 	ServiceSuffix                        string
+	CommentMinLength                     int
+	CommentTODOMarkers                   []string
+
+	// Plugins are external check plugins that provide additional rules.
+	//
+	// The IDs of the rules provided by Plugins must not overlap with the
+	// built-in rules or with each other.
+	Plugins []*Plugin
 }
 
 // NewConfig returns a new Config.
@@ -80,13 +95,65 @@ func newConfig(configBuilder ConfigBuilder, versionSpec *VersionSpec) (*Config,
 	if configBuilder.ServiceSuffix == "" {
 		configBuilder.ServiceSuffix = defaultServiceSuffix
 	}
-	return newConfigForRuleBuilders(
-		configBuilder,
+	if configBuilder.CommentMinLength == 0 {
+		configBuilder.CommentMinLength = defaultCommentMinLength
+	}
+	configBuilder.CommentTODOMarkers = stringutil.SliceToUniqueSortedSliceFilterEmptyStrings(configBuilder.CommentTODOMarkers)
+	if len(configBuilder.CommentTODOMarkers) == 0 {
+		configBuilder.CommentTODOMarkers = defaultCommentTODOMarkers
+	}
+	ruleBuilders, idToCategories, err := ruleBuildersAndIDToCategoriesWithPlugins(
 		versionSpec.RuleBuilders,
 		versionSpec.IDToCategories,
+		configBuilder.Plugins,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return newConfigForRuleBuilders(
+		configBuilder,
+		ruleBuilders,
+		idToCategories,
 	)
 }
 
+// ruleBuildersAndIDToCategoriesWithPlugins returns copies of the RuleBuilders and idToCategories
+// with the rules provided by the plugins added.
+func ruleBuildersAndIDToCategoriesWithPlugins(
+	ruleBuilders []*RuleBuilder,
+	idToCategories map[string][]string,
+	plugins []*Plugin,
+) ([]*RuleBuilder, map[string][]string, error) {
+	if len(plugins) == 0 {
+		return ruleBuilders, idToCategories, nil
+	}
+	resultRuleBuilders := make([]*RuleBuilder, len(ruleBuilders))
+	copy(resultRuleBuilders, ruleBuilders)
+	resultIDToCategories := make(map[string][]string, len(idToCategories))
+	for id, categories := range idToCategories {
+		resultIDToCategories[id] = categories
+	}
+	categoryToIDs := getCategoryToIDs(idToCategories)
+	for _, plugin := range plugins {
+		for _, pluginRule := range plugin.rules {
+			if _, ok := resultIDToCategories[pluginRule.ID]; ok {
+				return nil, nil, fmt.Errorf("plugin %s: rule %q is already defined", plugin.name, pluginRule.ID)
+			}
+			if _, ok := categoryToIDs[pluginRule.ID]; ok {
+				return nil, nil, fmt.Errorf("plugin %s: rule %q is already defined as a category", plugin.name, pluginRule.ID)
+			}
+			for _, category := range pluginRule.Categories {
+				if _, ok := resultIDToCategories[category]; ok {
+					return nil, nil, fmt.Errorf("plugin %s: category %q is already defined as a rule", plugin.name, category)
+				}
+			}
+			resultRuleBuilders = append(resultRuleBuilders, newPluginRuleBuilder(plugin, pluginRule))
+			resultIDToCategories[pluginRule.ID] = pluginRule.Categories
+		}
+	}
+	return resultRuleBuilders, resultIDToCategories, nil
+}
+
 func newConfigForRuleBuilders(
 	configBuilder ConfigBuilder,
 	ruleBuilders []*RuleBuilder,
@@ -0,0 +1,278 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufcheckplugin"
+	"github.com/bufbuild/buf/private/pkg/protosource"
+)
+
+// PluginCheckFunc runs an external check plugin for the given rule IDs.
+//
+// Only the path of the FileInfo of each returned FileAnnotation is used, the
+// Runner resolves the path against the files being checked.
+type PluginCheckFunc func(ctx context.Context, ruleIDs []string) ([]bufanalysis.FileAnnotation, error)
+
+// PluginRule is a rule provided by an external check plugin.
+type PluginRule struct {
+	ID         string
+	Categories []string
+	// Purpose is used as-is, unlike the purposes of built-in rules.
+	Purpose string
+}
+
+// Plugin is an external check plugin.
+type Plugin struct {
+	name      string
+	rules     []*PluginRule
+	checkFunc PluginCheckFunc
+}
+
+// NewPlugin returns a new Plugin.
+//
+// checkFunc may be nil if the Plugin is only used to resolve rules, for example
+// when printing rules. Running a Plugin without a checkFunc results in an error.
+func NewPlugin(name string, rules []*PluginRule, checkFunc PluginCheckFunc) *Plugin {
+	return &Plugin{
+		name:      name,
+		rules:     rules,
+		checkFunc: checkFunc,
+	}
+}
+
+// PluginsForConfigs returns the Plugins for the plugin configs.
+//
+// newPluginCheckFunc returns the function that runs a given plugin. If
+// newPluginCheckFunc is nil, the Plugins are only used to resolve rules.
+func PluginsForConfigs(
+	pluginConfigs []*bufcheckplugin.Config,
+	newPluginCheckFunc func(*bufcheckplugin.Config) PluginCheckFunc,
+) []*Plugin {
+	if len(pluginConfigs) == 0 {
+		return nil
+	}
+	plugins := make([]*Plugin, 0, len(pluginConfigs))
+	for _, pluginConfig := range pluginConfigs {
+		pluginRules := make([]*PluginRule, 0, len(pluginConfig.Rules))
+		for _, ruleConfig := range pluginConfig.Rules {
+			pluginRules = append(
+				pluginRules,
+				&PluginRule{
+					ID:         ruleConfig.ID,
+					Categories: ruleConfig.Categories,
+					Purpose:    ruleConfig.Purpose,
+				},
+			)
+		}
+		var checkFunc PluginCheckFunc
+		if newPluginCheckFunc != nil {
+			checkFunc = newPluginCheckFunc(pluginConfig)
+		}
+		plugins = append(plugins, NewPlugin(pluginConfig.Name(), pluginRules, checkFunc))
+	}
+	return plugins
+}
+
+// Name returns the name of the Plugin.
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+func (p *Plugin) check(
+	ctx context.Context,
+	ruleIDs []string,
+	ignoreFunc IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+) ([]bufanalysis.FileAnnotation, error) {
+	if p.checkFunc == nil {
+		return nil, fmt.Errorf("plugin %s cannot be run", p.name)
+	}
+	pluginFileAnnotations, err := p.checkFunc(ctx, ruleIDs)
+	if err != nil {
+		return nil, err
+	}
+	ruleIDMap := make(map[string]struct{}, len(ruleIDs))
+	for _, ruleID := range ruleIDs {
+		ruleIDMap[ruleID] = struct{}{}
+	}
+	// files take precedence over previousFiles with the same path
+	pathToFile := make(map[string]protosource.File, len(previousFiles)+len(files))
+	for _, file := range previousFiles {
+		pathToFile[file.Path()] = file
+	}
+	for _, file := range files {
+		pathToFile[file.Path()] = file
+	}
+	var fileAnnotations []bufanalysis.FileAnnotation
+	for _, pluginFileAnnotation := range pluginFileAnnotations {
+		id := pluginFileAnnotation.Type()
+		if _, ok := ruleIDMap[id]; !ok {
+			return nil, fmt.Errorf("plugin %s returned an annotation for rule %q which was not requested", p.name, id)
+		}
+		var descriptor protosource.Descriptor
+		var location protosource.Location
+		if pluginFileInfo := pluginFileAnnotation.FileInfo(); pluginFileInfo != nil {
+			file, ok := pathToFile[pluginFileInfo.Path()]
+			if !ok {
+				return nil, fmt.Errorf("plugin %s returned an annotation for unknown file %q", p.name, pluginFileInfo.Path())
+			}
+			descriptor, location = descriptorAndLocationForPosition(
+				file,
+				pluginFileAnnotation.StartLine(),
+				pluginFileAnnotation.StartColumn(),
+			)
+		}
+		if ignoreFunc != nil && ignoreFunc(
+			id,
+			[]protosource.Descriptor{descriptor},
+			[]protosource.Location{location},
+		) {
+			continue
+		}
+		var fileInfo bufanalysis.FileInfo
+		if descriptor != nil {
+			fileInfo = descriptor.File()
+		}
+		fileAnnotations = append(
+			fileAnnotations,
+			bufanalysis.NewFileAnnotation(
+				fileInfo,
+				pluginFileAnnotation.StartLine(),
+				pluginFileAnnotation.StartColumn(),
+				pluginFileAnnotation.EndLine(),
+				pluginFileAnnotation.EndColumn(),
+				id,
+				pluginFileAnnotation.Message(),
+			),
+		)
+	}
+	return fileAnnotations, nil
+}
+
+// descriptorAndLocationForPosition returns the innermost descriptor within the
+// file that contains the given position, along with its location.
+//
+// If no descriptor contains the position, the file and a nil location are returned.
+func descriptorAndLocationForPosition(
+	file protosource.File,
+	line int,
+	column int,
+) (protosource.Descriptor, protosource.Location) {
+	if line == 0 {
+		return file, nil
+	}
+	finder := &locationDescriptorFinder{
+		line:   line,
+		column: column,
+	}
+	if !finder.visitContainer(file) {
+		for _, service := range file.Services() {
+			if finder.visit(service) {
+				for _, method := range service.Methods() {
+					if finder.visit(method) {
+						break
+					}
+				}
+				break
+			}
+		}
+		for _, extension := range file.Extensions() {
+			if finder.visit(extension) {
+				break
+			}
+		}
+	}
+	if finder.descriptor == nil {
+		return file, nil
+	}
+	return finder.descriptor, finder.descriptor.Location()
+}
+
+type locationDescriptorFinder struct {
+	line       int
+	column     int
+	descriptor protosource.LocationDescriptor
+}
+
+// visit records the descriptor if it contains the position and returns
+// whether it did.
+func (f *locationDescriptorFinder) visit(descriptor protosource.LocationDescriptor) bool {
+	if !locationContainsPosition(descriptor.Location(), f.line, f.column) {
+		return false
+	}
+	f.descriptor = descriptor
+	return true
+}
+
+// visitContainer visits the enums and messages of the container, recursing into
+// nested declarations, and returns whether any of them contained the position.
+func (f *locationDescriptorFinder) visitContainer(containerDescriptor protosource.ContainerDescriptor) bool {
+	for _, enum := range containerDescriptor.Enums() {
+		if f.visit(enum) {
+			for _, enumValue := range enum.Values() {
+				if f.visit(enumValue) {
+					break
+				}
+			}
+			return true
+		}
+	}
+	for _, message := range containerDescriptor.Messages() {
+		if f.visit(message) {
+			for _, field := range message.Fields() {
+				if f.visit(field) {
+					return true
+				}
+			}
+			for _, extension := range message.Extensions() {
+				if f.visit(extension) {
+					return true
+				}
+			}
+			for _, oneof := range message.Oneofs() {
+				if f.visit(oneof) {
+					return true
+				}
+			}
+			f.visitContainer(message)
+			return true
+		}
+	}
+	return false
+}
+
+func locationContainsPosition(location protosource.Location, line int, column int) bool {
+	if location == nil {
+		return false
+	}
+	if line < location.StartLine() || line > location.EndLine() {
+		return false
+	}
+	if column == 0 {
+		return true
+	}
+	if line == location.StartLine() && column < location.StartColumn() {
+		return false
+	}
+	if line == location.EndLine() && column > location.EndColumn() {
+		return false
+	}
+	return true
+}
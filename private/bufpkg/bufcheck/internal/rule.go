@@ -45,6 +45,9 @@ type Rule struct {
 	categories []string
 	purpose    string
 	checkFunc  CheckFunc
+	// plugin is set if the rule is provided by an external check plugin,
+	// in which case checkFunc is nil.
+	plugin *Plugin
 }
 
 // newRule returns a new Rule.
@@ -73,6 +76,21 @@ func newRule(
 	}
 }
 
+// newPluginRule returns a new Rule provided by an external check plugin.
+//
+// Categories will be sorted, purpose is used as-is.
+func newPluginRule(
+	id string,
+	categories []string,
+	purpose string,
+	plugin *Plugin,
+) *Rule {
+	rule := newRule(id, categories, "", nil)
+	rule.purpose = purpose
+	rule.plugin = plugin
+	return rule
+}
+
 // ID implements Rule.
 func (c *Rule) ID() string {
 	return c.id
@@ -25,6 +25,9 @@ type RuleBuilder struct {
 	id         string
 	newPurpose func(ConfigBuilder) (string, error)
 	newCheck   func(ConfigBuilder) (CheckFunc, error)
+	// plugin is set if the rule is provided by an external check plugin,
+	// in which case newCheck is nil.
+	plugin *Plugin
 }
 
 // NewRuleBuilder returns a new RuleBuilder.
@@ -57,7 +60,7 @@ func NewNopRuleBuilder(
 // NewRule returns a new Rule.
 //
 // Categories will be sorted and Purpose will be prepended with "Checks that "
-// and appended with ".".
+// and appended with ".", unless the rule is provided by a plugin.
 //
 // Categories is an actual copy from the ruleBuilder.
 func (c *RuleBuilder) NewRule(configBuilder ConfigBuilder, categories []string) (*Rule, error) {
@@ -65,6 +68,14 @@ func (c *RuleBuilder) NewRule(configBuilder ConfigBuilder, categories []string)
 	if err != nil {
 		return nil, err
 	}
+	if c.plugin != nil {
+		return newPluginRule(
+			c.id,
+			categories,
+			purpose,
+			c.plugin,
+		), nil
+	}
 	check, err := c.newCheck(configBuilder)
 	if err != nil {
 		return nil, err
@@ -82,6 +93,14 @@ func (c *RuleBuilder) ID() string {
 	return c.id
 }
 
+func newPluginRuleBuilder(plugin *Plugin, pluginRule *PluginRule) *RuleBuilder {
+	return &RuleBuilder{
+		id:         pluginRule.ID,
+		newPurpose: newNopPurpose(pluginRule.Purpose),
+		plugin:     plugin,
+	}
+}
+
 func newNopPurpose(purpose string) func(ConfigBuilder) (string, error) {
 	return func(ConfigBuilder) (string, error) {
 		return purpose, nil
@@ -84,16 +84,39 @@ func (r *Runner) Check(ctx context.Context, config *Config, previousFiles []prot
 
 	ignoreFunc := r.newIgnoreFunc(config)
 	var fileAnnotations []bufanalysis.FileAnnotation
-	resultC := make(chan *result, len(rules))
+	// rules provided by plugins are grouped so that each plugin is only run once
+	var builtinRules []*Rule
+	var plugins []*Plugin
+	pluginToRuleIDs := make(map[*Plugin][]string)
 	for _, rule := range rules {
+		if rule.plugin == nil {
+			builtinRules = append(builtinRules, rule)
+			continue
+		}
+		if _, ok := pluginToRuleIDs[rule.plugin]; !ok {
+			plugins = append(plugins, rule.plugin)
+		}
+		pluginToRuleIDs[rule.plugin] = append(pluginToRuleIDs[rule.plugin], rule.ID())
+	}
+	numResults := len(builtinRules) + len(plugins)
+	resultC := make(chan *result, numResults)
+	for _, rule := range builtinRules {
 		rule := rule
 		go func() {
 			iFileAnnotations, iErr := rule.check(ignoreFunc, previousFiles, files)
 			resultC <- newResult(iFileAnnotations, iErr)
 		}()
 	}
+	for _, plugin := range plugins {
+		plugin := plugin
+		ruleIDs := pluginToRuleIDs[plugin]
+		go func() {
+			iFileAnnotations, iErr := plugin.check(ctx, ruleIDs, ignoreFunc, previousFiles, files)
+			resultC <- newResult(iFileAnnotations, iErr)
+		}()
+	}
 	var err error
-	for i := 0; i < len(rules); i++ {
+	for i := 0; i < numResults; i++ {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
@@ -47,6 +47,14 @@ func newConfigV1(externalConfig ExternalConfigV1) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	breakingConfig, err := bufbreakingconfig.NewConfigV1(externalConfig.Breaking)
+	if err != nil {
+		return nil, err
+	}
+	lintConfig, err := buflintconfig.NewConfigV1(externalConfig.Lint)
+	if err != nil {
+		return nil, err
+	}
 	var moduleIdentity bufmoduleref.ModuleIdentity
 	if externalConfig.Name != "" {
 		moduleIdentity, err = bufmoduleref.ModuleIdentityForString(externalConfig.Name)
@@ -58,7 +66,7 @@ func newConfigV1(externalConfig ExternalConfigV1) (*Config, error) {
 		Version:        V1Version,
 		ModuleIdentity: moduleIdentity,
 		Build:          buildConfig,
-		Breaking:       bufbreakingconfig.NewConfigV1(externalConfig.Breaking),
-		Lint:           buflintconfig.NewConfigV1(externalConfig.Lint),
+		Breaking:       breakingConfig,
+		Lint:           lintConfig,
 	}, nil
 }
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"path/filepath"
 
 	"github.com/bufbuild/buf/private/pkg/encoding"
 	"github.com/bufbuild/buf/private/pkg/storage"
@@ -68,13 +69,18 @@ func getConfigForBucket(ctx context.Context, readBucket storage.ReadBucket) (_ *
 		if err != nil {
 			return nil, err
 		}
-		return getConfigForDataInternal(
+		config, err := getConfigForDataInternal(
 			ctx,
 			encoding.UnmarshalYAMLNonStrict,
 			encoding.UnmarshalYAMLStrict,
 			data,
 			readObjectCloser.ExternalPath(),
 		)
+		if err != nil {
+			return nil, err
+		}
+		setPluginDirPaths(config, readObjectCloser.ExternalPath())
+		return config, nil
 	default:
 		return nil, fmt.Errorf("only one configuration file can exist but found multiple configuration files: %s", stringutil.SliceToString(foundConfigFilePaths))
 	}
@@ -131,4 +137,21 @@ func getConfigForDataInternal(
 			V1Version,
 		)
 	}
+}
+
+// setPluginDirPaths sets the directory of the configuration file at the given
+// path on the check plugins of the Config, so that the plugins are run relative
+// to the configuration file.
+func setPluginDirPaths(config *Config, configFilePath string) {
+	dirPath := filepath.Dir(configFilePath)
+	if config.Lint != nil {
+		for _, pluginConfig := range config.Lint.Plugins {
+			pluginConfig.DirPath = dirPath
+		}
+	}
+	if config.Breaking != nil {
+		for _, pluginConfig := range config.Breaking.Plugins {
+			pluginConfig.DirPath = dirPath
+		}
+	}
 }
\ No newline at end of file
@@ -33,18 +33,21 @@ func readConfigOS(
 		option(readConfigOSOptions)
 	}
 	if readConfigOSOptions.override != "" {
-		var data []byte
-		var err error
 		switch filepath.Ext(readConfigOSOptions.override) {
 		case ".json", ".yaml", ".yml":
-			data, err = os.ReadFile(readConfigOSOptions.override)
+			data, err := os.ReadFile(readConfigOSOptions.override)
 			if err != nil {
 				return nil, fmt.Errorf("could not read file: %v", err)
 			}
+			config, err := GetConfigForData(ctx, data)
+			if err != nil {
+				return nil, err
+			}
+			setPluginDirPaths(config, readConfigOSOptions.override)
+			return config, nil
 		default:
-			data = []byte(readConfigOSOptions.override)
+			return GetConfigForData(ctx, []byte(readConfigOSOptions.override))
 		}
-		return GetConfigForData(ctx, data)
 	}
 	return GetConfigForBucket(ctx, readBucket)
 }
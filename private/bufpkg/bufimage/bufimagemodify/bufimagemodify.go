@@ -62,13 +62,16 @@ type Sweeper interface {
 	// can be used as a Modifier.
 	Sweep(context.Context, bufimage.Image) error
 
-	// mark is un-exported so that the Sweeper cannot be implemented
+	// mark and unmark are un-exported so that the Sweeper cannot be implemented
 	// outside of this package.
 	mark(string, []int32)
+	unmark(string, []int32)
 }
 
-// NewFileOptionSweeper constructs a new file option Sweeper that removes
+// NewFileOptionSweeper constructs a new option Sweeper that removes
 // the SourceCodeInfo_Locations associated with the marks.
+//
+// Despite its name, the Sweeper sweeps both file options and field options.
 func NewFileOptionSweeper() Sweeper {
 	return newFileOptionSweeper()
 }
@@ -207,6 +210,96 @@ func OptimizeFor(
 	return optimizeFor(logger, sweeper, defaultOptimizeFor, except, moduleOverrides, validatedOverrides), nil
 }
 
+// FileOptionRule is a rule that sets or disables a file option for the files it matches.
+//
+// A rule without any of ModuleIdentity, Path and Package matches all files. A rule
+// with more than one of them only matches the files that match all of them.
+type FileOptionRule struct {
+	// Required, the name of the FileOptions field, such as java_generic_services.
+	Option string
+	// The value of the option. Bool values are parsed with strconv.ParseBool, and
+	// enum values are the names of the enum values, such as LITE_RUNTIME.
+	//
+	// Required unless Disable is set.
+	Value string
+	// Disable says to leave the option as it is in the input, including for the
+	// options that are modified by managed mode by default, such as java_package.
+	Disable bool
+	// Optional, only matches the files in this module.
+	ModuleIdentity bufmoduleref.ModuleIdentity
+	// Optional, only matches the files in this normalized directory or with this normalized path.
+	Path string
+	// Optional, only matches the files in this package or its sub-packages.
+	Package string
+}
+
+// FileOptionRules returns a Modifier that runs the given Modifier, and then applies the
+// rules to all of the files contained in the Image.
+//
+// For every option, the last rule for the option that matches a file wins. Options of
+// files that no rule for the option matches are left as the given Modifier set them.
+// The given Modifier may be nil.
+func FileOptionRules(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	modifier Modifier,
+	rules []*FileOptionRule,
+) (Modifier, error) {
+	return fileOptionRules(logger, sweeper, modifier, rules)
+}
+
+// FieldOptionRule is a rule that sets or disables a field option for the fields it matches.
+//
+// A rule without any of ModuleIdentity, Package, Message and Field matches all fields
+// for which the option is valid. A rule with more than one of them only matches the
+// fields that match all of them.
+type FieldOptionRule struct {
+	// Required, the name of the FieldOptions field. Only jstype is supported, which is only
+	// set for 64-bit integer fields.
+	Option string
+	// The value of the option, such as JS_STRING.
+	//
+	// Required unless Disable is set.
+	Value string
+	// Disable says to leave the option as it is in the input.
+	Disable bool
+	// Optional, only matches the fields in this module.
+	ModuleIdentity bufmoduleref.ModuleIdentity
+	// Optional, only matches the fields in this package or its sub-packages.
+	Package string
+	// Optional, only matches the fields declared in the message with this fully-qualified
+	// name, such as acme.weather.v1.Forecast. Fields of nested messages are not matched.
+	Message string
+	// Optional, only matches the fields with this name.
+	Field string
+}
+
+// FieldOptionRules returns a Modifier that applies the rules to the fields and extensions
+// of all of the files contained in the Image.
+//
+// For every option, the last rule for the option that matches a field wins.
+func FieldOptionRules(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	rules []*FieldOptionRule,
+) (Modifier, error) {
+	return fieldOptionRules(logger, sweeper, rules)
+}
+
+// ValidateFieldOptionRule returns an error if the option of the rule is not a field option
+// that can be modified, or if the value is not valid for the option.
+func ValidateFieldOptionRule(rule *FieldOptionRule) error {
+	_, err := newFieldOptionRule(rule)
+	return err
+}
+
+// ValidateFileOptionRule returns an error if the option of the rule is not a file option
+// that can be modified, or if the value is not valid for the option.
+func ValidateFileOptionRule(rule *FileOptionRule) error {
+	_, err := newFileOptionRule(rule)
+	return err
+}
+
 // GoPackageImportPathForFile returns the go_package import path for the given
 // ImageFile. If the package contains a version suffix, and if there are more
 // than two components, concatenate the final two components. Otherwise, we
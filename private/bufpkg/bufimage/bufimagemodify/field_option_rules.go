@@ -0,0 +1,292 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// The field numbers used to compute the SourceCodeInfo paths of field options.
+// https://github.com/protocolbuffers/protobuf/blob/29152fbc064921ca982d64a3a9eae1daa8f979bb/src/google/protobuf/descriptor.proto
+const (
+	// message_type in FileDescriptorProto.
+	fileMessageTypeTag = 4
+	// extension in FileDescriptorProto.
+	fileExtensionTag = 7
+	// field in DescriptorProto.
+	messageFieldTag = 2
+	// nested_type in DescriptorProto.
+	messageNestedTypeTag = 3
+	// extension in DescriptorProto.
+	messageExtensionTag = 6
+	// options in FieldDescriptorProto.
+	fieldOptionsTag = 8
+)
+
+var (
+	// fieldOptionsDescriptor is the descriptor of the FieldOptions message.
+	fieldOptionsDescriptor = (&descriptorpb.FieldOptions{}).ProtoReflect().Descriptor()
+
+	// supportedFieldOptions are the names of the field options that can be modified,
+	// and the functions that return true if the option is valid for a field.
+	supportedFieldOptions = map[string]func(*descriptorpb.FieldDescriptorProto) bool{
+		// jstype is only valid for 64-bit integer fields.
+		"jstype": func(field *descriptorpb.FieldDescriptorProto) bool {
+			switch field.GetType() {
+			case descriptorpb.FieldDescriptorProto_TYPE_INT64,
+				descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+				descriptorpb.FieldDescriptorProto_TYPE_SINT64,
+				descriptorpb.FieldDescriptorProto_TYPE_FIXED64,
+				descriptorpb.FieldDescriptorProto_TYPE_SFIXED64:
+				return true
+			default:
+				return false
+			}
+		},
+	}
+)
+
+// fieldOptionRule is a validated FieldOptionRule.
+type fieldOptionRule struct {
+	*FieldOptionRule
+	fieldDescriptor protoreflect.FieldDescriptor
+	isValidForField func(*descriptorpb.FieldDescriptorProto) bool
+	// Invalid if Disable is set.
+	value protoreflect.Value
+}
+
+// fieldWithPath is a field of a file with its SourceCodeInfo path.
+type fieldWithPath struct {
+	field *descriptorpb.FieldDescriptorProto
+	// The fully-qualified name of the message the field is declared in,
+	// empty for extensions declared at the top level of the file.
+	messageName string
+	path        []int32
+}
+
+func fieldOptionRules(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	rules []*FieldOptionRule,
+) (Modifier, error) {
+	validatedRules := make([]*fieldOptionRule, 0, len(rules))
+	var optionNames []string
+	seenOptionNames := make(map[string]struct{})
+	for i, rule := range rules {
+		validatedRule, err := newFieldOptionRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %d: %w", i+1, err)
+		}
+		validatedRules = append(validatedRules, validatedRule)
+		if _, ok := seenOptionNames[rule.Option]; !ok {
+			seenOptionNames[rule.Option] = struct{}{}
+			optionNames = append(optionNames, rule.Option)
+		}
+	}
+	return ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			seenRules := make(map[*fieldOptionRule]struct{}, len(validatedRules))
+			for _, imageFile := range image.Files() {
+				if isWellKnownType(ctx, imageFile) {
+					continue
+				}
+				for _, field := range getFieldsWithPaths(imageFile.Proto()) {
+					for _, optionName := range optionNames {
+						rule := lastMatchingFieldOptionRule(validatedRules, optionName, imageFile, field)
+						if rule == nil {
+							continue
+						}
+						seenRules[rule] = struct{}{}
+						applyFieldOptionRuleForField(sweeper, imageFile, field, rule)
+					}
+				}
+			}
+			for i, rule := range validatedRules {
+				if _, ok := seenRules[rule]; !ok {
+					logger.Sugar().Warnf("managed mode field rule %d for %s did not apply to any field", i+1, rule.Option)
+				}
+			}
+			return nil
+		},
+	), nil
+}
+
+func newFieldOptionRule(rule *FieldOptionRule) (*fieldOptionRule, error) {
+	isValidForField, ok := supportedFieldOptions[rule.Option]
+	if !ok {
+		supportedFieldOptionNames := make([]string, 0, len(supportedFieldOptions))
+		for supportedFieldOptionName := range supportedFieldOptions {
+			supportedFieldOptionNames = append(supportedFieldOptionNames, supportedFieldOptionName)
+		}
+		sort.Strings(supportedFieldOptionNames)
+		return nil, fmt.Errorf("unsupported field option %q, expected one of %s", rule.Option, strings.Join(supportedFieldOptionNames, ", "))
+	}
+	validatedRule := &fieldOptionRule{
+		FieldOptionRule: rule,
+		fieldDescriptor: fieldOptionsDescriptor.Fields().ByName(protoreflect.Name(rule.Option)),
+		isValidForField: isValidForField,
+	}
+	if rule.Disable {
+		if rule.Value != "" {
+			return nil, fmt.Errorf("cannot set a value for %s if the rule disables it", rule.Option)
+		}
+		return validatedRule, nil
+	}
+	value, err := parseOptionValue(validatedRule.fieldDescriptor, rule.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", rule.Option, err)
+	}
+	validatedRule.value = value
+	return validatedRule, nil
+}
+
+// lastMatchingFieldOptionRule returns the last rule for the option that matches the field,
+// or nil if no rule for the option matches the field.
+func lastMatchingFieldOptionRule(
+	rules []*fieldOptionRule,
+	optionName string,
+	imageFile bufimage.ImageFile,
+	field *fieldWithPath,
+) *fieldOptionRule {
+	for i := len(rules) - 1; i >= 0; i-- {
+		rule := rules[i]
+		if rule.Option == optionName && rule.isValidForField(field.field) && rule.matches(imageFile, field) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// matches returns true if the field matches all of the matchers of the rule.
+func (r *FieldOptionRule) matches(imageFile bufimage.ImageFile, field *fieldWithPath) bool {
+	if r.ModuleIdentity != nil {
+		moduleIdentity := imageFile.ModuleIdentity()
+		if moduleIdentity == nil || moduleIdentity.IdentityString() != r.ModuleIdentity.IdentityString() {
+			return false
+		}
+	}
+	if r.Package != "" {
+		packageName := imageFile.FileDescriptor().GetPackage()
+		if packageName != r.Package && !strings.HasPrefix(packageName, r.Package+".") {
+			return false
+		}
+	}
+	if r.Message != "" && field.messageName != r.Message {
+		return false
+	}
+	if r.Field != "" && field.field.GetName() != r.Field {
+		return false
+	}
+	return true
+}
+
+func applyFieldOptionRuleForField(
+	sweeper Sweeper,
+	imageFile bufimage.ImageFile,
+	field *fieldWithPath,
+	rule *fieldOptionRule,
+) {
+	if rule.Disable {
+		// The field rules are the only modifiers of field options, so the option
+		// already has the value of the input.
+		return
+	}
+	if field.field.Options != nil {
+		options := field.field.Options.ProtoReflect()
+		if options.Has(rule.fieldDescriptor) && options.Get(rule.fieldDescriptor).Equal(rule.value) {
+			// The option is already set to the same value, don't do anything.
+			return
+		}
+	}
+	if field.field.Options == nil {
+		field.field.Options = &descriptorpb.FieldOptions{}
+	}
+	field.field.Options.ProtoReflect().Set(rule.fieldDescriptor, rule.value)
+	if sweeper != nil {
+		sweeper.mark(imageFile.Path(), appendPath(field.path, fieldOptionsTag, int32(rule.fieldDescriptor.Number())))
+	}
+}
+
+// getFieldsWithPaths returns all of the fields and extensions of the file, including
+// the fields of nested messages, with their SourceCodeInfo paths.
+func getFieldsWithPaths(fileDescriptor *descriptorpb.FileDescriptorProto) []*fieldWithPath {
+	var fields []*fieldWithPath
+	for i, extension := range fileDescriptor.GetExtension() {
+		fields = append(
+			fields,
+			&fieldWithPath{
+				field: extension,
+				path:  []int32{fileExtensionTag, int32(i)},
+			},
+		)
+	}
+	prefix := fileDescriptor.GetPackage()
+	for i, message := range fileDescriptor.GetMessageType() {
+		fields = appendMessageFieldsWithPaths(fields, message, prefix, []int32{fileMessageTypeTag, int32(i)})
+	}
+	return fields
+}
+
+func appendMessageFieldsWithPaths(
+	fields []*fieldWithPath,
+	message *descriptorpb.DescriptorProto,
+	prefix string,
+	messagePath []int32,
+) []*fieldWithPath {
+	messageName := message.GetName()
+	if prefix != "" {
+		messageName = prefix + "." + messageName
+	}
+	for i, field := range message.GetField() {
+		fields = append(
+			fields,
+			&fieldWithPath{
+				field:       field,
+				messageName: messageName,
+				path:        appendPath(messagePath, messageFieldTag, int32(i)),
+			},
+		)
+	}
+	for i, extension := range message.GetExtension() {
+		fields = append(
+			fields,
+			&fieldWithPath{
+				field:       extension,
+				messageName: messageName,
+				path:        appendPath(messagePath, messageExtensionTag, int32(i)),
+			},
+		)
+	}
+	for i, nestedMessage := range message.GetNestedType() {
+		fields = appendMessageFieldsWithPaths(fields, nestedMessage, messageName, appendPath(messagePath, messageNestedTypeTag, int32(i)))
+	}
+	return fields
+}
+
+// appendPath returns a new path with the elements appended to the path, without
+// modifying the path.
+func appendPath(path []int32, elements ...int32) []int32 {
+	newPath := make([]int32, 0, len(path)+len(elements))
+	newPath = append(newPath, path...)
+	return append(newPath, elements...)
+}
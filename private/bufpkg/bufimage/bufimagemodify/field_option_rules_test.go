@@ -0,0 +1,104 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestFieldOptionRules(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "fieldoptions")
+	image := testGetImage(t, dirPath, true)
+	sweeper := NewFileOptionSweeper()
+	modifier, err := FieldOptionRules(
+		zap.NewNop(),
+		sweeper,
+		[]*FieldOptionRule{
+			{
+				Option: "jstype",
+				Value:  "JS_STRING",
+			},
+			{
+				Option:  "jstype",
+				Disable: true,
+				Message: "a.v1.Foo",
+				Field:   "keep",
+			},
+			{
+				Option:  "jstype",
+				Value:   "JS_NORMAL",
+				Package: "b",
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.NoError(t, NewMultiModifier(modifier, ModifierFunc(sweeper.Sweep)).Modify(context.Background(), image))
+
+	descriptor := image.GetFile("a.proto").Proto()
+	fooFields := descriptor.GetMessageType()[0].GetField()
+	assert.Equal(t, descriptorpb.FieldOptions_JS_STRING, fooFields[0].GetOptions().GetJstype())
+	assert.Equal(t, descriptorpb.FieldOptions_JS_STRING, fooFields[1].GetOptions().GetJstype())
+	assert.True(t, fooFields[1].GetOptions().GetDeprecated())
+	assert.Equal(t, descriptorpb.FieldOptions_JS_STRING, fooFields[2].GetOptions().GetJstype())
+	// jstype is only valid for 64-bit integer fields.
+	assert.Nil(t, fooFields[3].GetOptions())
+	assert.Equal(t, descriptorpb.FieldOptions_JS_NUMBER, fooFields[4].GetOptions().GetJstype())
+	assert.Equal(t, descriptorpb.FieldOptions_JS_STRING, descriptor.GetMessageType()[0].GetNestedType()[0].GetField()[0].GetOptions().GetJstype())
+	assert.Equal(t, descriptorpb.FieldOptions_JS_STRING, descriptor.GetExtension()[0].GetOptions().GetJstype())
+
+	// The locations of the modified options are removed, and the locations of the
+	// options in brackets are only removed if no other option is left in the brackets.
+	assertLocationPresent(t, image, []int32{4, 0, 2, 1, 8}, true)
+	assertLocationPresent(t, image, []int32{4, 0, 2, 1, 8, 3}, true)
+	assertLocationPresent(t, image, []int32{4, 0, 2, 1, 8, 6}, false)
+	assertLocationPresent(t, image, []int32{4, 0, 2, 2, 8}, false)
+	assertLocationPresent(t, image, []int32{4, 0, 2, 2, 8, 6}, false)
+	assertLocationPresent(t, image, []int32{4, 0, 2, 4, 8, 6}, true)
+}
+
+func TestFieldOptionRulesInvalid(t *testing.T) {
+	t.Parallel()
+	for _, rule := range []*FieldOptionRule{
+		{Option: "packed", Value: "true"},
+		{Option: "jstype", Value: "JS_BIGINT"},
+		{Option: "jstype"},
+		{Option: "jstype", Value: "JS_STRING", Disable: true},
+	} {
+		_, err := FieldOptionRules(zap.NewNop(), NewFileOptionSweeper(), []*FieldOptionRule{rule})
+		assert.Error(t, err, "%s: %q", rule.Option, rule.Value)
+	}
+}
+
+func assertLocationPresent(t *testing.T, image bufimage.Image, path []int32, expected bool) {
+	for _, imageFile := range image.Files() {
+		var present bool
+		for _, location := range imageFile.Proto().GetSourceCodeInfo().GetLocation() {
+			if int32SliceIsEqual(location.Path, path) {
+				present = true
+				break
+			}
+		}
+		assert.Equal(t, expected, present, "%v", path)
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// fileOptionsDescriptor is the descriptor of the FileOptions message.
+var fileOptionsDescriptor = (&descriptorpb.FileOptions{}).ProtoReflect().Descriptor()
+
+// fileOptionRule is a validated FileOptionRule.
+type fileOptionRule struct {
+	*FileOptionRule
+	fieldDescriptor protoreflect.FieldDescriptor
+	// Invalid if Disable is set.
+	value protoreflect.Value
+}
+
+func fileOptionRules(
+	logger *zap.Logger,
+	sweeper Sweeper,
+	modifier Modifier,
+	rules []*FileOptionRule,
+) (Modifier, error) {
+	validatedRules := make([]*fileOptionRule, 0, len(rules))
+	// The names of the options that have rules, in the order in which they are
+	// first used, so that options are always modified in the same order.
+	var optionNames []string
+	seenOptionNames := make(map[string]struct{})
+	var hasDisable bool
+	for i, rule := range rules {
+		validatedRule, err := newFileOptionRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %d: %w", i+1, err)
+		}
+		validatedRules = append(validatedRules, validatedRule)
+		if _, ok := seenOptionNames[rule.Option]; !ok {
+			seenOptionNames[rule.Option] = struct{}{}
+			optionNames = append(optionNames, rule.Option)
+		}
+		if rule.Disable {
+			hasDisable = true
+		}
+	}
+	return ModifierFunc(
+		func(ctx context.Context, image bufimage.Image) error {
+			// Disabled options are restored to the values in the input after the
+			// modifier ran, so we have to keep the original options.
+			var originalOptions map[string]*descriptorpb.FileOptions
+			if hasDisable {
+				originalOptions = make(map[string]*descriptorpb.FileOptions, len(image.Files()))
+				for _, imageFile := range image.Files() {
+					if options := imageFile.Proto().GetOptions(); options != nil {
+						originalOptions[imageFile.Path()] = proto.Clone(options).(*descriptorpb.FileOptions)
+					}
+				}
+			}
+			if modifier != nil {
+				if err := modifier.Modify(ctx, image); err != nil {
+					return err
+				}
+			}
+			seenRules := make(map[*fileOptionRule]struct{}, len(validatedRules))
+			for _, imageFile := range image.Files() {
+				if isWellKnownType(ctx, imageFile) {
+					continue
+				}
+				for _, optionName := range optionNames {
+					rule := lastMatchingFileOptionRule(validatedRules, optionName, imageFile)
+					if rule == nil {
+						continue
+					}
+					seenRules[rule] = struct{}{}
+					applyFileOptionRuleForFile(sweeper, imageFile, rule, originalOptions[imageFile.Path()])
+				}
+			}
+			for i, rule := range validatedRules {
+				if _, ok := seenRules[rule]; !ok {
+					logger.Sugar().Warnf("managed mode rule %d for %s did not apply to any file", i+1, rule.Option)
+				}
+			}
+			return nil
+		},
+	), nil
+}
+
+func newFileOptionRule(rule *FileOptionRule) (*fileOptionRule, error) {
+	fieldDescriptor := fileOptionsDescriptor.Fields().ByName(protoreflect.Name(rule.Option))
+	if fieldDescriptor == nil || fieldDescriptor.Cardinality() == protoreflect.Repeated || fieldDescriptor.Message() != nil {
+		return nil, fmt.Errorf("unknown file option %q", rule.Option)
+	}
+	validatedRule := &fileOptionRule{
+		FileOptionRule:  rule,
+		fieldDescriptor: fieldDescriptor,
+	}
+	if rule.Disable {
+		if rule.Value != "" {
+			return nil, fmt.Errorf("cannot set a value for %s if the rule disables it", rule.Option)
+		}
+		return validatedRule, nil
+	}
+	value, err := parseOptionValue(fieldDescriptor, rule.Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", rule.Option, err)
+	}
+	validatedRule.value = value
+	return validatedRule, nil
+}
+
+// lastMatchingFileOptionRule returns the last rule for the option that matches the file,
+// or nil if no rule for the option matches the file.
+func lastMatchingFileOptionRule(
+	rules []*fileOptionRule,
+	optionName string,
+	imageFile bufimage.ImageFile,
+) *fileOptionRule {
+	for i := len(rules) - 1; i >= 0; i-- {
+		rule := rules[i]
+		if rule.Option == optionName && rule.matches(imageFile) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// matches returns true if the file matches all of the matchers of the rule.
+func (r *FileOptionRule) matches(imageFile bufimage.ImageFile) bool {
+	if r.ModuleIdentity != nil {
+		moduleIdentity := imageFile.ModuleIdentity()
+		if moduleIdentity == nil || moduleIdentity.IdentityString() != r.ModuleIdentity.IdentityString() {
+			return false
+		}
+	}
+	if r.Path != "" && !normalpath.EqualsOrContainsPath(r.Path, imageFile.Path(), normalpath.Relative) {
+		return false
+	}
+	if r.Package != "" {
+		packageName := imageFile.FileDescriptor().GetPackage()
+		if packageName != r.Package && !strings.HasPrefix(packageName, r.Package+".") {
+			return false
+		}
+	}
+	return true
+}
+
+func applyFileOptionRuleForFile(
+	sweeper Sweeper,
+	imageFile bufimage.ImageFile,
+	rule *fileOptionRule,
+	originalOptions *descriptorpb.FileOptions,
+) {
+	descriptor := imageFile.Proto()
+	optionPath := []int32{fileOptionPath[0], int32(rule.fieldDescriptor.Number())}
+	if rule.Disable {
+		if originalOptions != nil && originalOptions.ProtoReflect().Has(rule.fieldDescriptor) {
+			if descriptor.Options == nil {
+				descriptor.Options = &descriptorpb.FileOptions{}
+			}
+			descriptor.Options.ProtoReflect().Set(rule.fieldDescriptor, originalOptions.ProtoReflect().Get(rule.fieldDescriptor))
+		} else if descriptor.Options != nil {
+			descriptor.Options.ProtoReflect().Clear(rule.fieldDescriptor)
+		}
+		// The option has its original value, so we keep its SourceCodeInfo_Location
+		// even if another modifier marked it.
+		if sweeper != nil {
+			sweeper.unmark(imageFile.Path(), optionPath)
+		}
+		return
+	}
+	if descriptor.Options != nil {
+		options := descriptor.Options.ProtoReflect()
+		if options.Has(rule.fieldDescriptor) && options.Get(rule.fieldDescriptor).Equal(rule.value) {
+			// The option is already set to the same value, don't do anything.
+			return
+		}
+	}
+	if descriptor.Options == nil {
+		descriptor.Options = &descriptorpb.FileOptions{}
+	}
+	descriptor.Options.ProtoReflect().Set(rule.fieldDescriptor, rule.value)
+	if sweeper != nil {
+		sweeper.mark(imageFile.Path(), optionPath)
+	}
+}
+
+// parseOptionValue parses the value of a scalar option field.
+//
+// Bool values are parsed with strconv.ParseBool, and enum values are the names of the
+// enum values, such as LITE_RUNTIME.
+func parseOptionValue(fieldDescriptor protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch fieldDescriptor.Kind() {
+	case protoreflect.BoolKind:
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("%q is not a boolean", value)
+		}
+		return protoreflect.ValueOfBool(boolValue), nil
+	case protoreflect.StringKind:
+		if value == "" {
+			return protoreflect.Value{}, fmt.Errorf("value is required")
+		}
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.EnumKind:
+		enumValueDescriptor := fieldDescriptor.Enum().Values().ByName(protoreflect.Name(value))
+		if enumValueDescriptor == nil {
+			enumValueNames := make([]string, 0, fieldDescriptor.Enum().Values().Len())
+			for i := 0; i < fieldDescriptor.Enum().Values().Len(); i++ {
+				enumValueNames = append(enumValueNames, string(fieldDescriptor.Enum().Values().Get(i).Name()))
+			}
+			return protoreflect.Value{}, fmt.Errorf("%q is not one of %s", value, strings.Join(enumValueNames, ", "))
+		}
+		return protoreflect.ValueOfEnum(enumValueDescriptor.Number()), nil
+	default:
+		return protoreflect.Value{}, fmt.Errorf("options of type %s are not supported", fieldDescriptor.Kind().String())
+	}
+}
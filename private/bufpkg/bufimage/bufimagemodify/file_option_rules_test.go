@@ -0,0 +1,152 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimagemodify
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestFileOptionRules(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "ruleoptions")
+	image := testGetImage(t, dirPath, true)
+	sweeper := NewFileOptionSweeper()
+	modifier, err := FileOptionRules(
+		zap.NewNop(),
+		sweeper,
+		nil,
+		[]*FileOptionRule{
+			{
+				Option: "java_generic_services",
+				Value:  "true",
+			},
+			{
+				Option:  "php_class_prefix",
+				Value:   "Bar",
+				Package: "b",
+			},
+			{
+				Option: "cc_generic_services",
+				Value:  "true",
+				Path:   "a",
+			},
+			{
+				Option: "optimize_for",
+				Value:  "CODE_SIZE",
+			},
+			{
+				Option: "optimize_for",
+				Value:  "LITE_RUNTIME",
+				Path:   "b/v1/b.proto",
+			},
+			{
+				Option: "swift_prefix",
+				Value:  "Baz",
+				Path:   "c",
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.NoError(t, NewMultiModifier(modifier, ModifierFunc(sweeper.Sweep)).Modify(context.Background(), image))
+
+	aOptions := testGetFileOptions(t, image, "a/v1/a.proto")
+	assert.True(t, aOptions.GetJavaGenericServices())
+	assert.True(t, aOptions.GetCcGenericServices())
+	assert.Nil(t, aOptions.PhpClassPrefix)
+	assert.Equal(t, descriptorpb.FileOptions_CODE_SIZE, aOptions.GetOptimizeFor())
+	assert.Equal(t, "foo", aOptions.GetSwiftPrefix())
+	bOptions := testGetFileOptions(t, image, "b/v1/b.proto")
+	assert.True(t, bOptions.GetJavaGenericServices())
+	assert.False(t, bOptions.GetCcGenericServices())
+	assert.Equal(t, "Bar", bOptions.GetPhpClassPrefix())
+	assert.Equal(t, descriptorpb.FileOptions_LITE_RUNTIME, bOptions.GetOptimizeFor())
+	assert.Nil(t, bOptions.SwiftPrefix)
+	// The location of swift_prefix is kept because no rule applied to it.
+	assertFileOptionSourceCodeInfoNotEmpty(t, testGetImageForFile(t, image, "a/v1/a.proto"), []int32{8, 39})
+}
+
+func TestFileOptionRulesDisable(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "ruleoptions")
+	image := testGetImage(t, dirPath, true)
+	sweeper := NewFileOptionSweeper()
+	javaPackageModifier, err := JavaPackage(zap.NewNop(), sweeper, DefaultJavaPackagePrefix, nil, nil, nil)
+	require.NoError(t, err)
+	moduleIdentity, err := bufmoduleref.NewModuleIdentity(testRemote, testRepositoryOwner, testRepositoryName)
+	require.NoError(t, err)
+	modifier, err := FileOptionRules(
+		zap.NewNop(),
+		sweeper,
+		javaPackageModifier,
+		[]*FileOptionRule{
+			{
+				Option:         "java_package",
+				Disable:        true,
+				ModuleIdentity: moduleIdentity,
+			},
+			{
+				Option:  "java_package",
+				Value:   "com.example.b",
+				Package: "b.v1",
+			},
+		},
+	)
+	require.NoError(t, err)
+	require.NoError(t, NewMultiModifier(modifier, ModifierFunc(sweeper.Sweep)).Modify(context.Background(), image))
+
+	// The original value and its location are restored.
+	assert.Equal(t, "foo", testGetFileOptions(t, image, "a/v1/a.proto").GetJavaPackage())
+	assertFileOptionSourceCodeInfoNotEmpty(t, testGetImageForFile(t, image, "a/v1/a.proto"), javaPackagePath)
+	assert.Equal(t, "com.example.b", testGetFileOptions(t, image, "b/v1/b.proto").GetJavaPackage())
+}
+
+func TestFileOptionRulesInvalid(t *testing.T) {
+	t.Parallel()
+	for _, rule := range []*FileOptionRule{
+		{Option: "unknown", Value: "true"},
+		{Option: "uninterpreted_option", Value: "true"},
+		{Option: "java_generic_services", Value: "foo"},
+		{Option: "java_generic_services"},
+		{Option: "swift_prefix"},
+		{Option: "optimize_for", Value: "FAST"},
+		{Option: "swift_prefix", Value: "Foo", Disable: true},
+	} {
+		_, err := FileOptionRules(zap.NewNop(), NewFileOptionSweeper(), nil, []*FileOptionRule{rule})
+		assert.Error(t, err, "%s: %q", rule.Option, rule.Value)
+	}
+}
+
+func testGetFileOptions(t *testing.T, image bufimage.Image, path string) *descriptorpb.FileOptions {
+	imageFile := image.GetFile(path)
+	require.NotNil(t, imageFile)
+	return imageFile.Proto().GetOptions()
+}
+
+func testGetImageForFile(t *testing.T, image bufimage.Image, path string) bufimage.Image {
+	imageFile := image.GetFile(path)
+	require.NotNil(t, imageFile)
+	fileImage, err := bufimage.NewImage([]bufimage.ImageFile{imageFile})
+	require.NoError(t, err)
+	return fileImage
+}
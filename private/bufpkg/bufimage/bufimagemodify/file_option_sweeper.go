@@ -52,7 +52,15 @@ func (s *fileOptionSweeper) mark(imageFilePath string, path []int32) {
 	paths[getPathKey(path)] = struct{}{}
 }
 
-// Sweep applies all of the marks and sweeps the file option SourceCodeInfo_Locations.
+// unmark is used to remove the mark for the given SourceCodeInfo_Location
+// indices, for example if the option was restored to its original value.
+func (s *fileOptionSweeper) unmark(imageFilePath string, path []int32) {
+	if paths, ok := s.sourceCodeInfoPaths[imageFilePath]; ok {
+		delete(paths, getPathKey(path))
+	}
+}
+
+// Sweep applies all of the marks and sweeps the option SourceCodeInfo_Locations.
 func (s *fileOptionSweeper) Sweep(ctx context.Context, image bufimage.Image) error {
 	for _, imageFile := range image.Files() {
 		descriptor := imageFile.Proto()
@@ -64,24 +72,41 @@ func (s *fileOptionSweeper) Sweep(ctx context.Context, image bufimage.Image) err
 			continue
 		}
 		// We can't just match on an exact path match because the target
-		// file option's parent path elements would remain (i.e [8]).
+		// option's parent path elements would remain (i.e [8]).
 		// Instead, we perform an initial pass to validate that the paths
 		// are structured as expect, and collect all of the indices that
 		// we need to delete.
 		indices := make(map[int]struct{}, len(paths)*2)
+		parentIndices := make(map[int]struct{}, len(paths))
 		for i, location := range descriptor.SourceCodeInfo.Location {
 			if _, ok := paths[getPathKey(location.Path)]; !ok {
 				continue
 			}
-			if i == 0 {
-				return fmt.Errorf("path %v must have a preceding parent path", location.Path)
-			}
-			if !int32SliceIsEqual(descriptor.SourceCodeInfo.Location[i-1].Path, fileOptionPath) {
-				return fmt.Errorf("path %v must have a preceding parent path equal to %v", location.Path, fileOptionPath)
+			parentIndex, err := getOptionParentIndex(descriptor.SourceCodeInfo.Location, i)
+			if err != nil {
+				return err
 			}
-			// Add the target path and its parent.
-			indices[i-1] = struct{}{}
 			indices[i] = struct{}{}
+			parentIndices[parentIndex] = struct{}{}
+		}
+		// File options each have their own parent location, but the options of
+		// fields share a single parent location for all options in brackets, so
+		// a parent is only deleted if all of its children are deleted.
+		for parentIndex := range parentIndices {
+			parentPath := descriptor.SourceCodeInfo.Location[parentIndex].Path
+			allChildrenDeleted := true
+			for i := parentIndex + 1; i < len(descriptor.SourceCodeInfo.Location); i++ {
+				if !isDescendantPath(parentPath, descriptor.SourceCodeInfo.Location[i].Path) {
+					break
+				}
+				if _, ok := indices[i]; !ok {
+					allChildrenDeleted = false
+					break
+				}
+			}
+			if allChildrenDeleted {
+				indices[parentIndex] = struct{}{}
+			}
 		}
 		// Now that we know exactly which indices to exclude, we can
 		// filter the SourceCodeInfo_Locations as needed.
@@ -101,6 +126,33 @@ func (s *fileOptionSweeper) Sweep(ctx context.Context, image bufimage.Image) err
 	return nil
 }
 
+// getOptionParentIndex returns the index of the location of the options that the
+// option location at index belongs to.
+//
+// The parent location precedes the option location, with only the locations of
+// other options with the same parent in between.
+func getOptionParentIndex(locations []*descriptorpb.SourceCodeInfo_Location, index int) (int, error) {
+	path := locations[index].Path
+	if len(path) < 2 {
+		return 0, fmt.Errorf("path %v must have a parent path", path)
+	}
+	parentPath := path[:len(path)-1]
+	for i := index - 1; i >= 0; i-- {
+		if int32SliceIsEqual(locations[i].Path, parentPath) {
+			return i, nil
+		}
+		if !isDescendantPath(parentPath, locations[i].Path) {
+			break
+		}
+	}
+	return 0, fmt.Errorf("path %v must have a preceding parent path equal to %v", path, parentPath)
+}
+
+// isDescendantPath returns true if path is a descendant of parentPath.
+func isDescendantPath(parentPath []int32, path []int32) bool {
+	return len(path) > len(parentPath) && int32SliceIsEqual(path[:len(parentPath)], parentPath)
+}
+
 // getPathKey returns a unique key for the given path.
 func getPathKey(path []int32) string {
 	key := make([]byte, len(path)*4)
@@ -366,13 +366,15 @@ lint:
 	assert.Len(t, fileInfos, 1)
 
 	// assert: breaking and lint configuration exists
-	zeroBreaking := bufbreakingconfig.NewConfigV1(
+	zeroBreaking, err := bufbreakingconfig.NewConfigV1(
 		bufbreakingconfig.ExternalConfigV1{},
 	)
+	require.NoError(t, err)
 	assert.NotEqual(t, zeroBreaking, module.BreakingConfig(), "empty BreakingConfig")
-	zeroLint := buflintconfig.NewConfigV1(
+	zeroLint, err := buflintconfig.NewConfigV1(
 		buflintconfig.ExternalConfigV1{},
 	)
+	require.NoError(t, err)
 	assert.NotEqual(t, zeroLint, module.LintConfig(), "empty LintConfig")
 }
 
@@ -0,0 +1,74 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufpluginexec
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// addFileFuncHandler calls a function with every file that the delegate adds to
+// the response, and the CodeGeneratorRequest that the file was generated for.
+type addFileFuncHandler struct {
+	delegate    appproto.Handler
+	addFileFunc func(*pluginpb.CodeGeneratorRequest, *pluginpb.CodeGeneratorResponse_File)
+}
+
+func newAddFileFuncHandler(
+	delegate appproto.Handler,
+	addFileFunc func(*pluginpb.CodeGeneratorRequest, *pluginpb.CodeGeneratorResponse_File),
+) *addFileFuncHandler {
+	return &addFileFuncHandler{
+		delegate:    delegate,
+		addFileFunc: addFileFunc,
+	}
+}
+
+func (h *addFileFuncHandler) Handle(
+	ctx context.Context,
+	container app.EnvStderrContainer,
+	responseWriter appproto.ResponseBuilder,
+	request *pluginpb.CodeGeneratorRequest,
+) error {
+	return h.delegate.Handle(
+		ctx,
+		container,
+		&addFileFuncResponseBuilder{
+			ResponseBuilder: responseWriter,
+			request:         request,
+			addFileFunc:     h.addFileFunc,
+		},
+		request,
+	)
+}
+
+type addFileFuncResponseBuilder struct {
+	appproto.ResponseBuilder
+
+	request     *pluginpb.CodeGeneratorRequest
+	addFileFunc func(*pluginpb.CodeGeneratorRequest, *pluginpb.CodeGeneratorResponse_File)
+}
+
+func (b *addFileFuncResponseBuilder) AddFile(file *pluginpb.CodeGeneratorResponse_File) error {
+	// The delegate normalizes the name of the file, so the function is only called after.
+	if err := b.ResponseBuilder.AddFile(file); err != nil {
+		return err
+	}
+	b.addFileFunc(b.request, file)
+	return nil
+}
@@ -89,16 +89,23 @@ func (h *binaryHandler) Handle(
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
-	response := &pluginpb.CodeGeneratorResponse{}
-	if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(responseBuffer.Bytes(), response); err != nil {
+	if err := addResponseData(responseWriter, responseBuffer.Bytes()); err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
-	response, err = normalizeCodeGeneratorResponse(response)
+	return nil
+}
+
+// addResponseData adds the serialized CodeGeneratorResponse written by a plugin
+// to the responseWriter.
+func addResponseData(responseWriter appproto.ResponseBuilder, responseData []byte) error {
+	response := &pluginpb.CodeGeneratorResponse{}
+	if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(responseData, response); err != nil {
+		return err
+	}
+	response, err := normalizeCodeGeneratorResponse(response)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
 	if response.GetSupportedFeatures()&uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL) != 0 {
@@ -106,8 +113,6 @@ func (h *binaryHandler) Handle(
 	}
 	for _, file := range response.File {
 		if err := responseWriter.AddFile(file); err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, err.Error())
 			return err
 		}
 	}
@@ -22,6 +22,8 @@ package bufpluginexec
 import (
 	"context"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
@@ -91,8 +93,26 @@ func NewGenerator(
 	logger *zap.Logger,
 	storageosProvider storageos.Provider,
 	runner command.Runner,
+	options ...GeneratorOption,
 ) Generator {
-	return newGenerator(logger, storageosProvider, runner)
+	return newGenerator(logger, storageosProvider, runner, options...)
+}
+
+// GeneratorOption is an option for a new Generator.
+type GeneratorOption func(*generator)
+
+// GeneratorWithMaxParallelInvocations returns a new GeneratorOption that limits the number
+// of plugin invocations that run at once across all calls to Generate.
+//
+// Every CodeGeneratorRequest results in one invocation of the plugin. The default is to
+// only limit the number of invocations per call to Generate to thread.Parallelism().
+// A value of <1 has no meaning.
+func GeneratorWithMaxParallelInvocations(maxParallelInvocations int) GeneratorOption {
+	return func(generator *generator) {
+		if maxParallelInvocations > 0 {
+			generator.invocationSemaphoreC = make(chan struct{}, maxParallelInvocations)
+		}
+	}
 }
 
 // GenerateOption is an option for Generate.
@@ -115,11 +135,40 @@ func GenerateWithProtocPath(protocPath string) GenerateOption {
 	}
 }
 
+// GenerateWithTimeout returns a new GenerateOption that fails every invocation of the plugin
+// that does not complete within the timeout.
+//
+// Every CodeGeneratorRequest results in one invocation of the plugin, so the timeout applies
+// to each CodeGeneratorRequest separately. The error for an invocation that timed out names
+// the files of the CodeGeneratorRequest, and matches context.DeadlineExceeded with errors.Is.
+// The default is to not time out.
+func GenerateWithTimeout(timeout time.Duration) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.timeout = timeout
+	}
+}
+
+// GenerateWithAddFileFunc returns a new GenerateOption that calls the function with every
+// file that the plugin generates, and the CodeGeneratorRequest that the file was generated
+// for. This can be used to find out which files were generated for which request.
+//
+// The function is called concurrently for different requests. Files that are dropped from
+// the response as duplicates are also passed to the function.
+func GenerateWithAddFileFunc(
+	addFileFunc func(*pluginpb.CodeGeneratorRequest, *pluginpb.CodeGeneratorResponse_File),
+) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.addFileFunc = addFileFunc
+	}
+}
+
 // NewHandler returns a new Handler based on the plugin name and optional path.
 //
 // protocPath and pluginPath are optional.
 //
-//   - If the plugin path is set, this returns a new binary handler for that path.
+//   - If the plugin path is set and ends in .wasm, this returns a new handler that runs the
+//     WebAssembly plugin at that path in-process. The plugin must be compiled to WASI.
+//   - If the plugin path is set otherwise, this returns a new binary handler for that path.
 //   - If the plugin path is unset, this does exec.LookPath for a binary named protoc-gen-pluginName,
 //     and if one is found, a new binary handler is returned for this.
 //   - Else, if the name is in ProtocProxyPluginNames, this returns a new protoc proxy handler.
@@ -134,29 +183,70 @@ func NewHandler(
 	for _, option := range options {
 		option(handlerOptions)
 	}
+	pluginPath, protocProxy, err := lookPath(pluginName, handlerOptions)
+	if err != nil {
+		return nil, err
+	}
+	if protocProxy {
+		return newProtocProxyHandler(storageosProvider, runner, pluginPath, pluginName), nil
+	}
+	var pluginArgs []string
+	if len(handlerOptions.pluginPath) > 0 {
+		pluginArgs = handlerOptions.pluginPath[1:]
+	}
+	if isWasmPluginPath(pluginPath) {
+		return newWasmHandler(pluginPath, pluginArgs), nil
+	}
+	return newBinaryHandler(runner, pluginPath, pluginArgs), nil
+}
+
+// LookPath returns the path to the binary that a Handler returned by NewHandler would
+// invoke for the same plugin name and options.
+//
+// For plugins that are proxied through protoc, this is the path to protoc.
+func LookPath(pluginName string, options ...HandlerOption) (string, error) {
+	handlerOptions := newHandlerOptions()
+	for _, option := range options {
+		option(handlerOptions)
+	}
+	pluginPath, _, err := lookPath(pluginName, handlerOptions)
+	return pluginPath, err
+}
+
+// lookPath returns the path to the binary to invoke for the plugin, and whether the
+// plugin is proxied through protoc, in which case the path is the path to protoc.
+func lookPath(pluginName string, handlerOptions *handlerOptions) (string, bool, error) {
 	if len(handlerOptions.pluginPath) > 0 {
+		if isWasmPluginPath(handlerOptions.pluginPath[0]) {
+			// WebAssembly plugins are not looked up on the $PATH, and do not need to be executable.
+			if _, err := os.Stat(handlerOptions.pluginPath[0]); err != nil {
+				return "", false, err
+			}
+			return handlerOptions.pluginPath[0], false, nil
+		}
 		pluginPath, err := unsafeLookPath(handlerOptions.pluginPath[0])
 		if err != nil {
-			return nil, err
+			return "", false, err
 		}
-		return newBinaryHandler(runner, pluginPath, handlerOptions.pluginPath[1:]), nil
+		return pluginPath, false, nil
 	}
 	pluginPath, err := unsafeLookPath("protoc-gen-" + pluginName)
 	if err == nil {
-		return newBinaryHandler(runner, pluginPath, nil), nil
+		return pluginPath, false, nil
 	}
 	// we always look for protoc-gen-X first, but if not, check the builtins
 	if _, ok := ProtocProxyPluginNames[pluginName]; ok {
-		if handlerOptions.protocPath == "" {
-			handlerOptions.protocPath = "protoc"
+		protocPath := handlerOptions.protocPath
+		if protocPath == "" {
+			protocPath = "protoc"
 		}
-		protocPath, err := unsafeLookPath(handlerOptions.protocPath)
+		protocPath, err := unsafeLookPath(protocPath)
 		if err != nil {
-			return nil, err
+			return "", false, err
 		}
-		return newProtocProxyHandler(storageosProvider, runner, protocPath, pluginName), nil
+		return protocPath, true, nil
 	}
-	return nil, fmt.Errorf(
+	return "", false, fmt.Errorf(
 		"could not find protoc plugin for name %s - please make sure protoc-gen-%s is installed and present on your $PATH",
 		pluginName,
 		pluginName,
@@ -16,6 +16,7 @@ package bufpluginexec
 
 import (
 	"context"
+	"time"
 
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
@@ -29,18 +30,25 @@ type generator struct {
 	logger            *zap.Logger
 	storageosProvider storageos.Provider
 	runner            command.Runner
+	// Nil if the number of invocations is not limited.
+	invocationSemaphoreC chan struct{}
 }
 
 func newGenerator(
 	logger *zap.Logger,
 	storageosProvider storageos.Provider,
 	runner command.Runner,
+	options ...GeneratorOption,
 ) *generator {
-	return &generator{
+	generator := &generator{
 		logger:            logger,
 		storageosProvider: storageosProvider,
 		runner:            runner,
 	}
+	for _, option := range options {
+		option(generator)
+	}
+	return generator
 }
 
 func (g *generator) Generate(
@@ -64,6 +72,12 @@ func (g *generator) Generate(
 	if err != nil {
 		return nil, err
 	}
+	if g.invocationSemaphoreC != nil || generateOptions.timeout > 0 {
+		handler = newLimitHandler(handler, g.invocationSemaphoreC, generateOptions.timeout)
+	}
+	if generateOptions.addFileFunc != nil {
+		handler = newAddFileFuncHandler(handler, generateOptions.addFileFunc)
+	}
 	return appproto.NewGenerator(
 		g.logger,
 		handler,
@@ -77,6 +91,9 @@ func (g *generator) Generate(
 type generateOptions struct {
 	pluginPath []string
 	protocPath string
+	timeout    time.Duration
+	// Nil if not set.
+	addFileFunc func(*pluginpb.CodeGeneratorRequest, *pluginpb.CodeGeneratorResponse_File)
 }
 
 func newGenerateOptions() *generateOptions {
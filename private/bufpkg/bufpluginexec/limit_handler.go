@@ -0,0 +1,104 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufpluginexec
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// limitHandler limits the number of invocations of the delegate that run at once,
+// and how long each invocation may run.
+type limitHandler struct {
+	delegate appproto.Handler
+	// Nil if the number of invocations is not limited.
+	semaphoreC chan struct{}
+	// Zero if invocations do not time out.
+	timeout time.Duration
+}
+
+func newLimitHandler(
+	delegate appproto.Handler,
+	semaphoreC chan struct{},
+	timeout time.Duration,
+) *limitHandler {
+	return &limitHandler{
+		delegate:   delegate,
+		semaphoreC: semaphoreC,
+		timeout:    timeout,
+	}
+}
+
+func (h *limitHandler) Handle(
+	ctx context.Context,
+	container app.EnvStderrContainer,
+	responseWriter appproto.ResponseBuilder,
+	request *pluginpb.CodeGeneratorRequest,
+) error {
+	if h.semaphoreC != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case h.semaphoreC <- struct{}{}:
+		}
+		defer func() {
+			<-h.semaphoreC
+		}()
+	}
+	if h.timeout <= 0 {
+		return h.delegate.Handle(ctx, container, responseWriter, request)
+	}
+	// The timeout starts once the invocation acquired the semaphore, so that waiting
+	// for other invocations does not count against it.
+	timeoutCtx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	err := h.delegate.Handle(timeoutCtx, container, responseWriter, request)
+	// The delegate usually fails with an error about the killed process instead
+	// of with context.DeadlineExceeded, so we check the context instead of the error.
+	if err != nil && ctx.Err() == nil && timeoutCtx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf(
+			"timed out after %v generating %s: %w",
+			h.timeout,
+			describeFilesToGenerate(request.FileToGenerate),
+			context.DeadlineExceeded,
+		)
+	}
+	return err
+}
+
+// describeFilesToGenerate describes the files to generate of a CodeGeneratorRequest
+// for error messages.
+func describeFilesToGenerate(filesToGenerate []string) string {
+	dirPaths := make([]string, len(filesToGenerate))
+	for i, fileToGenerate := range filesToGenerate {
+		dirPaths[i] = normalpath.Dir(fileToGenerate)
+	}
+	dirPaths = stringutil.SliceToUniqueSortedSlice(dirPaths)
+	switch len(dirPaths) {
+	case 0:
+		return "no files"
+	case 1:
+		return fmt.Sprintf("the files in directory %q", dirPaths[0])
+	default:
+		return fmt.Sprintf("%d files in %d directories", len(filesToGenerate), len(dirPaths))
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufpluginexec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/thread"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestLimitHandlerTimeout(t *testing.T) {
+	t.Parallel()
+	handler := newLimitHandler(
+		appproto.HandlerFunc(
+			func(
+				ctx context.Context,
+				container app.EnvStderrContainer,
+				responseWriter appproto.ResponseBuilder,
+				request *pluginpb.CodeGeneratorRequest,
+			) error {
+				<-ctx.Done()
+				return errors.New("signal: killed")
+			},
+		),
+		nil,
+		10*time.Millisecond,
+	)
+	err := handler.Handle(
+		context.Background(),
+		app.NewContainer(nil, nil, nil, bytes.NewBuffer(nil)),
+		nil,
+		&pluginpb.CodeGeneratorRequest{
+			FileToGenerate: []string{"a/b/a.proto", "a/b/b.proto"},
+		},
+	)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, `timed out after 10ms generating the files in directory "a/b": context deadline exceeded`, err.Error())
+}
+
+func TestLimitHandlerMaxParallelInvocations(t *testing.T) {
+	t.Parallel()
+	var lock sync.Mutex
+	var running int
+	var maxRunning int
+	handler := newLimitHandler(
+		appproto.HandlerFunc(
+			func(
+				ctx context.Context,
+				container app.EnvStderrContainer,
+				responseWriter appproto.ResponseBuilder,
+				request *pluginpb.CodeGeneratorRequest,
+			) error {
+				lock.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				lock.Unlock()
+				time.Sleep(time.Millisecond)
+				lock.Lock()
+				running--
+				lock.Unlock()
+				return nil
+			},
+		),
+		make(chan struct{}, 2),
+		0,
+	)
+	container := app.NewContainer(nil, nil, nil, bytes.NewBuffer(nil))
+	jobs := make([]func(context.Context) error, 16)
+	for i := range jobs {
+		jobs[i] = func(ctx context.Context) error {
+			return handler.Handle(ctx, container, nil, &pluginpb.CodeGeneratorRequest{})
+		}
+	}
+	require.NoError(t, thread.Parallelize(context.Background(), jobs, thread.ParallelizeWithMultiplier(4)))
+	assert.LessOrEqual(t, maxRunning, 2)
+}
+
+func TestDescribeFilesToGenerate(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, `the files in directory "."`, describeFilesToGenerate([]string{"a.proto"}))
+	assert.Equal(t, `the files in directory "a"`, describeFilesToGenerate([]string{"a/a.proto", "a/b.proto"}))
+	assert.Equal(t, "3 files in 2 directories", describeFilesToGenerate([]string{"a/a.proto", "a/b.proto", "b/a.proto"}))
+}
@@ -0,0 +1,65 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main implements a plugin that is compiled to WebAssembly for tests.
+//
+// The plugin writes a file NAME.txt for every file to generate, containing the
+// parameter, and fails if the parameter is "error".
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func main() {
+	if err := run(); err != nil {
+		_, _ = os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+	request := &pluginpb.CodeGeneratorRequest{}
+	if err := proto.Unmarshal(data, request); err != nil {
+		return err
+	}
+	response := &pluginpb.CodeGeneratorResponse{}
+	if request.GetParameter() == "error" {
+		response.Error = proto.String("parameter was error")
+	}
+	for _, fileToGenerate := range request.FileToGenerate {
+		response.File = append(
+			response.File,
+			&pluginpb.CodeGeneratorResponse_File{
+				Name:    proto.String(strings.TrimSuffix(fileToGenerate, ".proto") + ".txt"),
+				Content: proto.String(request.GetParameter()),
+			},
+		)
+	}
+	data, err = proto.Marshal(response)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
@@ -0,0 +1,131 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufpluginexec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// wasmPluginExt is the file extension that identifies a plugin as a WebAssembly plugin.
+const wasmPluginExt = ".wasm"
+
+// wasmHandler runs a WebAssembly plugin compiled to WASI in-process.
+//
+// The plugin has no access to the filesystem, the network or the environment
+// variables of buf, and only communicates through stdin, stdout and stderr
+// like a binary plugin.
+type wasmHandler struct {
+	pluginPath string
+	pluginArgs []string
+	tracer     trace.Tracer
+	// Shared between all invocations of the plugin by this handler, so that
+	// the plugin is only compiled once per handler.
+	compilationCache wazero.CompilationCache
+}
+
+func newWasmHandler(
+	pluginPath string,
+	pluginArgs []string,
+) *wasmHandler {
+	return &wasmHandler{
+		pluginPath:       pluginPath,
+		pluginArgs:       pluginArgs,
+		tracer:           otel.GetTracerProvider().Tracer("bufbuild/buf"),
+		compilationCache: wazero.NewCompilationCache(),
+	}
+}
+
+func (h *wasmHandler) Handle(
+	ctx context.Context,
+	container app.EnvStderrContainer,
+	responseWriter appproto.ResponseBuilder,
+	request *pluginpb.CodeGeneratorRequest,
+) (retErr error) {
+	ctx, span := h.tracer.Start(ctx, "plugin_proxy", trace.WithAttributes(
+		attribute.Key("plugin").String(filepath.Base(h.pluginPath)),
+	))
+	defer span.End()
+	defer func() {
+		if retErr != nil {
+			span.RecordError(retErr)
+			span.SetStatus(codes.Error, retErr.Error())
+		}
+	}()
+	requestData, err := protoencoding.NewWireMarshaler().Marshal(request)
+	if err != nil {
+		return err
+	}
+	wasmData, err := os.ReadFile(h.pluginPath)
+	if err != nil {
+		return err
+	}
+	runtime := wazero.NewRuntimeWithConfig(
+		ctx,
+		wazero.NewRuntimeConfig().
+			WithCompilationCache(h.compilationCache).
+			WithCloseOnContextDone(true),
+	)
+	defer func() {
+		retErr = multierr.Append(retErr, runtime.Close(ctx))
+	}()
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		return err
+	}
+	compiledModule, err := runtime.CompileModule(ctx, wasmData)
+	if err != nil {
+		return fmt.Errorf("could not compile WebAssembly plugin %s: %w", h.pluginPath, err)
+	}
+	responseBuffer := bytes.NewBuffer(nil)
+	stderrWriteCloser := newStderrWriteCloser(container.Stderr(), h.pluginPath)
+	moduleConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(append([]string{filepath.Base(h.pluginPath)}, h.pluginArgs...)...).
+		WithStdin(bytes.NewReader(requestData)).
+		WithStdout(responseBuffer).
+		WithStderr(stderrWriteCloser).
+		WithSysWalltime().
+		WithSysNanotime()
+	// This runs the _start function of the plugin, which returns once the plugin exits.
+	// A non-zero exit code results in a *sys.ExitError.
+	module, err := runtime.InstantiateModule(ctx, compiledModule, moduleConfig)
+	if err != nil {
+		return fmt.Errorf("WebAssembly plugin %s failed: %w", h.pluginPath, err)
+	}
+	if err := module.Close(ctx); err != nil {
+		return err
+	}
+	return addResponseData(responseWriter, responseBuffer.Bytes())
+}
+
+// isWasmPluginPath returns true if the plugin at the path is a WebAssembly plugin.
+func isWasmPluginPath(pluginPath string) bool {
+	return filepath.Ext(pluginPath) == wasmPluginExt
+}
@@ -0,0 +1,98 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufpluginexec
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestWasmHandler(t *testing.T) {
+	t.Parallel()
+	wasmPluginPath := buildWasmPlugin(t)
+	generator := NewGenerator(zap.NewNop(), storageos.NewProvider(), command.NewRunner())
+	container := app.NewContainer(nil, nil, nil, bytes.NewBuffer(nil))
+	response, err := generator.Generate(
+		context.Background(),
+		container,
+		"test",
+		[]*pluginpb.CodeGeneratorRequest{
+			{
+				FileToGenerate: []string{"a/a.proto"},
+				Parameter:      proto.String("foo"),
+				ProtoFile:      []*descriptorpb.FileDescriptorProto{{Name: proto.String("a/a.proto")}},
+			},
+			{
+				FileToGenerate: []string{"b/b.proto"},
+				Parameter:      proto.String("foo"),
+				ProtoFile:      []*descriptorpb.FileDescriptorProto{{Name: proto.String("b/b.proto")}},
+			},
+		},
+		GenerateWithPluginPath(wasmPluginPath),
+	)
+	require.NoError(t, err)
+	require.Len(t, response.File, 2)
+	assert.Equal(t, "a/a.txt", response.File[0].GetName())
+	assert.Equal(t, "foo", response.File[0].GetContent())
+	assert.Equal(t, "b/b.txt", response.File[1].GetName())
+
+	_, err = generator.Generate(
+		context.Background(),
+		container,
+		"test",
+		[]*pluginpb.CodeGeneratorRequest{
+			{
+				FileToGenerate: []string{"a/a.proto"},
+				Parameter:      proto.String("error"),
+				ProtoFile:      []*descriptorpb.FileDescriptorProto{{Name: proto.String("a/a.proto")}},
+			},
+		},
+		GenerateWithPluginPath(wasmPluginPath),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parameter was error")
+
+	lookedUpPath, err := LookPath("test", HandlerWithPluginPath(wasmPluginPath))
+	require.NoError(t, err)
+	assert.Equal(t, wasmPluginPath, lookedUpPath)
+}
+
+// buildWasmPlugin builds the plugin in testdata/wasm for WASI, and returns the path
+// to the built plugin.
+//
+// Skips the test if the Go toolchain cannot build for WASI.
+func buildWasmPlugin(t *testing.T) string {
+	wasmPluginPath := filepath.Join(t.TempDir(), "protoc-gen-test.wasm")
+	cmd := exec.Command("go", "build", "-o", wasmPluginPath, "./testdata/wasm")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("could not build WebAssembly plugin: %v: %s", err, output)
+	}
+	return wasmPluginPath
+}
@@ -19,11 +19,18 @@ import (
 	"context"
 	"io"
 
+	"github.com/bufbuild/buf/private/pkg/command"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
+// CleanManifestFilePath is the path of the manifest within an output directory that
+// records the files that were generated into the directory when cleaning is enabled.
+//
+// See AddResponseWithClean.
+const CleanManifestFilePath = ".buf.gen.manifest"
+
 // ResponseWriter writes CodeGeneratorResponses to the OS filesystem.
 type ResponseWriter interface {
 	// Close writes all of the responses to disk. No further calls can be
@@ -40,7 +47,19 @@ type ResponseWriter interface {
 		ctx context.Context,
 		response *pluginpb.CodeGeneratorResponse,
 		pluginOut string,
+		options ...AddResponseOption,
 	) error
+	// Diff writes a unified diff between the files on disk and the responses to the
+	// writer, instead of writing the responses to disk. No further calls can be made
+	// to the ResponseWriter after this call.
+	//
+	// Only the files produced by the responses are compared, so that files in the
+	// output directories that were not generated are ignored. For output directories
+	// that are cleaned, the previously generated files that would be deleted are
+	// also included.
+	//
+	// Returns true if the files on disk differ from the responses.
+	Diff(ctx context.Context, runner command.Runner, writer io.Writer) (bool, error)
 }
 
 // NewResponseWriter returns a new ResponseWriter.
@@ -66,3 +85,21 @@ func ResponseWriterWithCreateOutDirIfNotExists() ResponseWriterOption {
 		responseWriterOptions.createOutDirIfNotExists = true
 	}
 }
+
+// AddResponseOption is an option for AddResponse.
+type AddResponseOption func(*addResponseOptions)
+
+// AddResponseWithClean returns a new AddResponseOption that deletes the files in the
+// output directory that were generated by a previous invocation, but are no longer
+// generated.
+//
+// The generated files are recorded in a manifest at CleanManifestFilePath within the
+// output directory, and only the files listed in the manifest are ever deleted. If any
+// response written to an output directory has this option set, the entire output
+// directory is cleaned. This has no effect for .jar and .zip outputs, which are always
+// rewritten in full.
+func AddResponseWithClean() AddResponseOption {
+	return func(addResponseOptions *addResponseOptions) {
+		addResponseOptions.clean = true
+	}
+}
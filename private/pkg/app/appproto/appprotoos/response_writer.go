@@ -16,13 +16,19 @@
 package appprotoos
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 
 	"github.com/bufbuild/buf/private/pkg/app/appproto"
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/diff"
+	"github.com/bufbuild/buf/private/pkg/manifest"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storagearchive"
@@ -64,10 +70,19 @@ type responseWriter struct {
 	// $ protoc example.proto --insertion-point-receiver_out=. --insertion-point-writer_out=$(pwd)
 	//
 	readWriteBuckets map[string]storage.ReadWriteBucket
+	// The output paths that should be cleaned of previously generated
+	// files when the responseWriter is flushed.
+	cleanOutDirPaths map[string]struct{}
+	// The output paths as they were given to AddResponse, keyed by
+	// their absolute paths. These are used to print diffs.
+	displayOutPaths map[string]string
 	// Cache the functions used to flush all of the responses to disk.
 	// This holds all of the buckets in-memory so that we only write
 	// the results to disk if all of the responses are successful.
 	closers []func() error
+	// Cache the functions used to diff all of the responses against
+	// the files on disk. There is one for each closer.
+	differs []func(context.Context, command.Runner, io.Writer) (bool, error)
 	lock    sync.RWMutex
 }
 
@@ -86,6 +101,8 @@ func newResponseWriter(
 		responseWriter:          appproto.NewResponseWriter(logger),
 		createOutDirIfNotExists: responseWriterOptions.createOutDirIfNotExists,
 		readWriteBuckets:        make(map[string]storage.ReadWriteBucket),
+		cleanOutDirPaths:        make(map[string]struct{}),
+		displayOutPaths:         make(map[string]string),
 	}
 }
 
@@ -93,7 +110,12 @@ func (w *responseWriter) AddResponse(
 	ctx context.Context,
 	response *pluginpb.CodeGeneratorResponse,
 	pluginOut string,
+	options ...AddResponseOption,
 ) error {
+	addResponseOptions := newAddResponseOptions()
+	for _, option := range options {
+		option(addResponseOptions)
+	}
 	// It's important that we get a consistent output path
 	// so that we use the same in-memory bucket for paths
 	// set to the same directory.
@@ -109,6 +131,12 @@ func (w *responseWriter) AddResponse(
 	}
 	w.lock.Lock()
 	defer w.lock.Unlock()
+	if addResponseOptions.clean {
+		w.cleanOutDirPaths[absPluginOut] = struct{}{}
+	}
+	if _, ok := w.displayOutPaths[absPluginOut]; !ok {
+		w.displayOutPaths[absPluginOut] = normalpath.Normalize(pluginOut)
+	}
 	return w.addResponse(
 		ctx,
 		response,
@@ -131,10 +159,36 @@ func (w *responseWriter) Close() error {
 			return err
 		}
 	}
-	// Re-initialize the cached values to be safe.
+	w.reset()
+	return nil
+}
+
+func (w *responseWriter) Diff(
+	ctx context.Context,
+	runner command.Runner,
+	writer io.Writer,
+) (bool, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	var diffPresent bool
+	for _, diffFunc := range w.differs {
+		outDiffPresent, err := diffFunc(ctx, runner, writer)
+		if err != nil {
+			return false, err
+		}
+		diffPresent = diffPresent || outDiffPresent
+	}
+	w.reset()
+	return diffPresent, nil
+}
+
+// reset re-initializes the cached values to be safe.
+func (w *responseWriter) reset() {
 	w.readWriteBuckets = make(map[string]storage.ReadWriteBucket)
+	w.cleanOutDirPaths = make(map[string]struct{})
+	w.displayOutPaths = make(map[string]string)
 	w.closers = nil
-	return nil
+	w.differs = nil
 }
 
 func (w *responseWriter) addResponse(
@@ -237,6 +291,21 @@ func (w *responseWriter) writeZip(
 		// protoc does not compress.
 		return storagearchive.Zip(ctx, readWriteBucket, file, false)
 	})
+	w.differs = append(w.differs, func(ctx context.Context, runner command.Runner, writer io.Writer) (bool, error) {
+		existingReadBucket, err := readZip(ctx, outFilePath)
+		if err != nil {
+			return false, err
+		}
+		return diffGenerated(
+			ctx,
+			runner,
+			writer,
+			existingReadBucket,
+			readWriteBucket,
+			nil,
+			w.displayOutPaths[outFilePath],
+		)
+	})
 	return nil
 }
 
@@ -285,18 +354,232 @@ func (w *responseWriter) writeDirectory(
 		if err != nil {
 			return err
 		}
+		// Other responses written to the same outDirPath may have enabled
+		// cleaning, so we only check this once all responses are added.
+		if _, ok := w.cleanOutDirPaths[outDirPath]; ok {
+			return cleanAndCopy(ctx, readWriteBucket, osReadWriteBucket)
+		}
 		if _, err := storage.Copy(ctx, readWriteBucket, osReadWriteBucket); err != nil {
 			return err
 		}
 		return nil
 	})
+	w.differs = append(w.differs, func(ctx context.Context, runner command.Runner, writer io.Writer) (bool, error) {
+		// The directory may not exist yet, in which case all files are new.
+		var existingReadBucket storage.ReadBucket = storagemem.NewReadWriteBucket()
+		// OK to use os.Stat instead of os.Lstat here.
+		if _, err := os.Stat(outDirPath); err == nil {
+			existingReadBucket, err = w.storageosProvider.NewReadWriteBucket(
+				outDirPath,
+				storageos.ReadWriteBucketWithSymlinksIfSupported(),
+			)
+			if err != nil {
+				return false, err
+			}
+		} else if !os.IsNotExist(err) {
+			return false, err
+		}
+		var deletedPaths []string
+		if _, ok := w.cleanOutDirPaths[outDirPath]; ok {
+			var err error
+			deletedPaths, err = orphanedPaths(ctx, readWriteBucket, existingReadBucket)
+			if err != nil {
+				return false, err
+			}
+		}
+		return diffGenerated(
+			ctx,
+			runner,
+			writer,
+			existingReadBucket,
+			readWriteBucket,
+			deletedPaths,
+			w.displayOutPaths[outDirPath],
+		)
+	})
 	return nil
 }
 
+// cleanAndCopy copies the generated files in the readBucket to the readWriteBucket, and
+// deletes the files in the readWriteBucket that were listed in the previous manifest at
+// CleanManifestFilePath, but are not generated anymore. The manifest is then replaced
+// with a manifest of the generated files.
+func cleanAndCopy(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	readWriteBucket storage.ReadWriteBucket,
+) error {
+	deletedPaths, err := orphanedPaths(ctx, readBucket, readWriteBucket)
+	if err != nil {
+		return err
+	}
+	for _, path := range deletedPaths {
+		if err := readWriteBucket.Delete(ctx, path); err != nil && !storage.IsNotExist(err) {
+			return err
+		}
+	}
+	if _, err := storage.Copy(ctx, readBucket, readWriteBucket); err != nil {
+		return err
+	}
+	generatedManifest, _, err := manifest.NewFromBucket(ctx, readBucket)
+	if err != nil {
+		return err
+	}
+	data, err := generatedManifest.MarshalText()
+	if err != nil {
+		return err
+	}
+	return storage.PutPath(ctx, readWriteBucket, CleanManifestFilePath, data)
+}
+
+// orphanedPaths returns the sorted paths listed in the manifest at CleanManifestFilePath
+// in the existingReadBucket that are not in the generatedReadBucket.
+func orphanedPaths(
+	ctx context.Context,
+	generatedReadBucket storage.ReadBucket,
+	existingReadBucket storage.ReadBucket,
+) ([]string, error) {
+	previousManifest, err := readCleanManifest(ctx, existingReadBucket)
+	if err != nil {
+		return nil, err
+	}
+	var orphanedPaths []string
+	for _, path := range previousManifest.Paths() {
+		exists, err := storage.Exists(ctx, generatedReadBucket, path)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			orphanedPaths = append(orphanedPaths, path)
+		}
+	}
+	sort.Strings(orphanedPaths)
+	return orphanedPaths, nil
+}
+
+// readCleanManifest reads the manifest at CleanManifestFilePath in the readBucket.
+//
+// Returns an empty manifest if the manifest does not exist.
+func readCleanManifest(ctx context.Context, readBucket storage.ReadBucket) (_ *manifest.Manifest, retErr error) {
+	readObjectCloser, err := readBucket.Get(ctx, CleanManifestFilePath)
+	if err != nil {
+		if storage.IsNotExist(err) {
+			return &manifest.Manifest{}, nil
+		}
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, readObjectCloser.Close())
+	}()
+	cleanManifest, err := manifest.NewFromReader(readObjectCloser)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", readObjectCloser.ExternalPath(), err)
+	}
+	return cleanManifest, nil
+}
+
+// diffGenerated writes a unified diff between the files in the existingReadBucket and
+// the generatedReadBucket to the writer, for all of the paths in the generatedReadBucket
+// and the deletedPaths. The paths are prefixed with displayOutPath.
+//
+// Returns true if there was a diff.
+func diffGenerated(
+	ctx context.Context,
+	runner command.Runner,
+	writer io.Writer,
+	existingReadBucket storage.ReadBucket,
+	generatedReadBucket storage.ReadBucket,
+	deletedPaths []string,
+	displayOutPath string,
+) (bool, error) {
+	paths, err := storage.AllPaths(ctx, generatedReadBucket, "")
+	if err != nil {
+		return false, err
+	}
+	sort.Strings(paths)
+	var diffPresent bool
+	for _, path := range append(paths, deletedPaths...) {
+		existingData, err := readPathIfExists(ctx, existingReadBucket, path)
+		if err != nil {
+			return false, err
+		}
+		generatedData, err := readPathIfExists(ctx, generatedReadBucket, path)
+		if err != nil {
+			return false, err
+		}
+		displayPath := normalpath.Join(displayOutPath, path)
+		diffData, err := diff.Diff(
+			ctx,
+			runner,
+			existingData,
+			generatedData,
+			displayPath,
+			displayPath,
+			diff.DiffWithSuppressTimestamps(),
+		)
+		if err != nil {
+			return false, err
+		}
+		if len(diffData) == 0 {
+			continue
+		}
+		diffPresent = true
+		if _, err := writer.Write(diffData); err != nil {
+			return false, err
+		}
+	}
+	return diffPresent, nil
+}
+
+// readPathIfExists reads the file at the path, returning nil if it does not exist.
+func readPathIfExists(ctx context.Context, readBucket storage.ReadBucket, path string) ([]byte, error) {
+	data, err := storage.ReadPath(ctx, readBucket, path)
+	if err != nil {
+		if storage.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// readZip reads the entries of the existing archive at outFilePath.
+//
+// Returns an empty bucket if the archive does not exist.
+func readZip(ctx context.Context, outFilePath string) (storage.ReadBucket, error) {
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	data, err := os.ReadFile(outFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return readWriteBucket, nil
+		}
+		return nil, err
+	}
+	if err := storagearchive.Unzip(
+		ctx,
+		bytes.NewReader(data),
+		int64(len(data)),
+		readWriteBucket,
+		nil,
+		0,
+	); err != nil {
+		return nil, err
+	}
+	return readWriteBucket, nil
+}
+
 type responseWriterOptions struct {
 	createOutDirIfNotExists bool
 }
 
 func newResponseWriterOptions() *responseWriterOptions {
 	return &responseWriterOptions{}
+}
+
+type addResponseOptions struct {
+	clean bool
+}
+
+func newAddResponseOptions() *addResponseOptions {
+	return &addResponseOptions{}
 }
\ No newline at end of file
@@ -0,0 +1,131 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package appprotoos
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/command"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestCleanDeletesOrphanedFiles(t *testing.T) {
+	t.Parallel()
+	outDirPath := t.TempDir()
+	// A hand-written file that must never be deleted.
+	require.NoError(t, os.WriteFile(filepath.Join(outDirPath, "handwritten.txt"), []byte("handwritten"), 0600))
+	testWriteResponse(t, outDirPath, true, "a.txt", "foo/b.txt")
+	assert.FileExists(t, filepath.Join(outDirPath, "a.txt"))
+	assert.FileExists(t, filepath.Join(outDirPath, "foo", "b.txt"))
+	assert.FileExists(t, filepath.Join(outDirPath, CleanManifestFilePath))
+
+	testWriteResponse(t, outDirPath, true, "a.txt")
+	assert.FileExists(t, filepath.Join(outDirPath, "a.txt"))
+	assert.NoFileExists(t, filepath.Join(outDirPath, "foo", "b.txt"))
+	assert.FileExists(t, filepath.Join(outDirPath, "handwritten.txt"))
+	data, err := os.ReadFile(filepath.Join(outDirPath, CleanManifestFilePath))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "foo/b.txt")
+	assert.Contains(t, string(data), "a.txt")
+}
+
+func TestNoCleanKeepsOrphanedFiles(t *testing.T) {
+	t.Parallel()
+	outDirPath := t.TempDir()
+	testWriteResponse(t, outDirPath, false, "a.txt", "b.txt")
+	assert.NoFileExists(t, filepath.Join(outDirPath, CleanManifestFilePath))
+	testWriteResponse(t, outDirPath, false, "a.txt")
+	assert.FileExists(t, filepath.Join(outDirPath, "b.txt"))
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	outDirPath := t.TempDir()
+	diffPresent, diffData := testDiffResponse(t, outDirPath, false, "a.txt")
+	assert.True(t, diffPresent)
+	assert.Contains(t, diffData, "+a.txt")
+	// Diff must not write anything.
+	assert.NoFileExists(t, filepath.Join(outDirPath, "a.txt"))
+
+	testWriteResponse(t, outDirPath, false, "a.txt")
+	require.NoError(t, os.WriteFile(filepath.Join(outDirPath, "handwritten.txt"), []byte("handwritten"), 0600))
+	diffPresent, diffData = testDiffResponse(t, outDirPath, false, "a.txt")
+	assert.False(t, diffPresent)
+	assert.Empty(t, diffData)
+
+	require.NoError(t, os.WriteFile(filepath.Join(outDirPath, "a.txt"), []byte("modified"), 0600))
+	diffPresent, diffData = testDiffResponse(t, outDirPath, false, "a.txt")
+	assert.True(t, diffPresent)
+	assert.Contains(t, diffData, "-modified")
+	assert.Contains(t, diffData, "+a.txt")
+	data, err := os.ReadFile(filepath.Join(outDirPath, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "modified", string(data))
+}
+
+func TestDiffClean(t *testing.T) {
+	t.Parallel()
+	outDirPath := t.TempDir()
+	testWriteResponse(t, outDirPath, true, "a.txt", "b.txt")
+	diffPresent, diffData := testDiffResponse(t, outDirPath, false, "a.txt")
+	assert.False(t, diffPresent)
+	assert.Empty(t, diffData)
+	diffPresent, diffData = testDiffResponse(t, outDirPath, true, "a.txt")
+	assert.True(t, diffPresent)
+	assert.Contains(t, diffData, "-b.txt")
+	assert.FileExists(t, filepath.Join(outDirPath, "b.txt"))
+}
+
+func testDiffResponse(t *testing.T, outDirPath string, clean bool, fileNames ...string) (bool, string) {
+	responseWriter := NewResponseWriter(zap.NewNop(), storageos.NewProvider())
+	testAddResponse(t, responseWriter, outDirPath, clean, fileNames...)
+	buffer := bytes.NewBuffer(nil)
+	diffPresent, err := responseWriter.Diff(context.Background(), command.NewRunner(), buffer)
+	require.NoError(t, err)
+	return diffPresent, buffer.String()
+}
+
+func testWriteResponse(t *testing.T, outDirPath string, clean bool, fileNames ...string) {
+	responseWriter := NewResponseWriter(zap.NewNop(), storageos.NewProvider())
+	testAddResponse(t, responseWriter, outDirPath, clean, fileNames...)
+	require.NoError(t, responseWriter.Close())
+}
+
+func testAddResponse(t *testing.T, responseWriter ResponseWriter, outDirPath string, clean bool, fileNames ...string) {
+	response := &pluginpb.CodeGeneratorResponse{}
+	for _, fileName := range fileNames {
+		response.File = append(
+			response.File,
+			&pluginpb.CodeGeneratorResponse_File{
+				Name:    proto.String(fileName),
+				Content: proto.String(fileName),
+			},
+		)
+	}
+	var options []AddResponseOption
+	if clean {
+		options = append(options, AddResponseWithClean())
+	}
+	require.NoError(t, responseWriter.AddResponse(context.Background(), response, outDirPath, options...))
+}
@@ -86,6 +86,14 @@ func NewJSONMarshalerUseProtoNames(resolver Resolver) Marshaler {
 	return newJSONMarshaler(resolver, "", true)
 }
 
+// NewTxtpbMarshaler returns a new Marshaler for txtpb, the Protobuf text format.
+//
+// This has the potential to be unstable over time.
+// resolver can be nil if unknown and are only needed for extensions.
+func NewTxtpbMarshaler(resolver Resolver) Marshaler {
+	return newTxtpbMarshaler(resolver)
+}
+
 // Unmarshaler unmarshals Messages.
 type Unmarshaler interface {
 	Unmarshal(data []byte, message proto.Message) error
@@ -103,4 +111,11 @@ func NewWireUnmarshaler(resolver Resolver) Unmarshaler {
 // resolver can be nil if unknown and are only needed for extensions.
 func NewJSONUnmarshaler(resolver Resolver) Unmarshaler {
 	return newJSONUnmarshaler(resolver)
+}
+
+// NewTxtpbUnmarshaler returns a new Unmarshaler for txtpb, the Protobuf text format.
+//
+// resolver can be nil if unknown and are only needed for extensions.
+func NewTxtpbUnmarshaler(resolver Resolver) Unmarshaler {
+	return newTxtpbUnmarshaler(resolver)
 }
\ No newline at end of file
@@ -0,0 +1,46 @@
+// Copyright 2020-2023 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protoencoding
+
+import (
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+type txtpbMarshaler struct {
+	resolver Resolver
+}
+
+func newTxtpbMarshaler(resolver Resolver) Marshaler {
+	return &txtpbMarshaler{
+		resolver: resolver,
+	}
+}
+
+func (m *txtpbMarshaler) Marshal(message proto.Message) ([]byte, error) {
+	if err := ReparseUnrecognized(m.resolver, message.ProtoReflect()); err != nil {
+		return nil, err
+	}
+	options := prototext.MarshalOptions{
+		Resolver:  m.resolver,
+		Multiline: true,
+		Indent:    "  ",
+	}
+	// Note that prototext output is deliberately unstable, and may differ
+	// in whitespace between invocations.
+	//
+	// https://developers.google.com/protocol-buffers/docs/reference/go/faq#unstable-text
+	return options.Marshal(message)
+}
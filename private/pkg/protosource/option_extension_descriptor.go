@@ -72,3 +72,24 @@ func (o *optionExtensionDescriptor) PresentExtensionNumbers() []int32 {
 
 	return fieldNumbers
 }
+
+func (o *optionExtensionDescriptor) OptionExtensionRawFields(fieldNumber int32) (protoreflect.RawFields, error) {
+	// Extensions may either be unknown fields or known extension fields, depending
+	// on how the options were produced, so we marshal the message to cover both.
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(o.message)
+	if err != nil {
+		return nil, err
+	}
+	var rawFields protoreflect.RawFields
+	for len(data) > 0 {
+		fieldNo, _, n := protowire.ConsumeField(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		if int32(fieldNo) == fieldNumber {
+			rawFields = append(rawFields, data[:n]...)
+		}
+		data = data[n:]
+	}
+	return rawFields, nil
+}
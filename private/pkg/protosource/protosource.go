@@ -135,6 +135,14 @@ type OptionExtensionDescriptor interface {
 	// PresentExtensionNumbers returns field numbers for all options that
 	// have a set value on this descriptor.
 	PresentExtensionNumbers() []int32
+
+	// OptionExtensionRawFields returns the wire-format fields for the options
+	// extension field with the given number.
+	//
+	// This is used to inspect custom options such as validation constraints
+	// without depending on their generated types. If the field occurs multiple
+	// times, all occurrences are returned in order.
+	OptionExtensionRawFields(fieldNumber int32) (protoreflect.RawFields, error)
 }
 
 // Location defines source code info location information.